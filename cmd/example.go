@@ -22,9 +22,9 @@ package cmd
 
 import (
 	"crypto/md5"
-	"encoding/csv"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"time"
 
@@ -50,6 +50,8 @@ var useMa *bool
 var useCp *bool
 var useDate *string
 var useNo *string
+var useFormat *string
+var useOut *string
 
 //global var
 var (
@@ -345,21 +347,32 @@ func checkFirstDayOfMonth(stock *twse.Data) error {
 	//fmt.Println("checkFirstDayOfMonth:", stock.Date, d, stock.Date==d)
 }
 
+// pickedStock getTWSE 篩選出的股票，匯出為當日的 CSV
+type pickedStock struct {
+	No             string  `json:"no"`
+	Name           string  `json:"name"`
+	Range          float64 `json:"range"`
+	Price          float64 `json:"price"`
+	Gain           float64 `json:"gain"`
+	NDayAvg        float64 `json:"n_day_avg"`
+	OverMA         bool    `json:"over_ma"`
+	T38OverBought  bool    `json:"t38_over_bought"`
+	T44OverBought  bool    `json:"t44_over_bought"`
+	MTSSOverBought bool    `json:"mtss_over_bought"`
+}
+
+// round2 四捨五入至小數第二位
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
 func getTWSE(date time.Time, category string, minDataNum int, t38 *twse.TWT38U, t44 *twse.TWT44U, mtss *twse.TWMTSS) error {
 	utils.Dbgln(date.Format(shortForm))
 
 	t := twse.NewLists(date)
 	tList := t.GetCategoryList(category)
 
-	year, month, day := date.Date()
-	csvFile, err := os.OpenFile(fmt.Sprintf("%d%02d%02d.csv", year, month, day), os.O_CREATE|os.O_RDWR, 0666)
-	defer csvFile.Close()
-	if err != nil {
-		utils.Dbg("error: %s\n", err)
-		return err
-	}
-	csvWriter := csv.NewWriter(csvFile)
-
+	var picked []pickedStock
 	mtssMapData, err := mtss.GetData()
 	if err != nil {
 		return errors.Wrap(err, "MTSS GetData Fail.")
@@ -413,24 +426,18 @@ func getTWSE(date time.Time, category string, minDataNum int, t38 *twse.TWT38U,
 					}
 				}
 				if output {
-					err = csvWriter.Write([]string{v.No,
-						v.Name,
-						fmt.Sprintf("%.2f", res.todayRange),
-						fmt.Sprintf("%.2f", res.todayPrice),
-						fmt.Sprintf("%.2f", res.todayGain),
-						fmt.Sprintf("%.2f", res.NDayAvg),
-						fmt.Sprintf("%t", res.overMA),
-						fmt.Sprintf("%t", isT38OverBought),
-						fmt.Sprintf("%t", isT44OverBought),
-						fmt.Sprintf("%t", isMTSSOverBought)})
-					if err != nil {
-						return err
-					}
-					csvWriter.Flush()
-					err = csvWriter.Error()
-					if err != nil {
-						return err
-					}
+					picked = append(picked, pickedStock{
+						No:             v.No,
+						Name:           v.Name,
+						Range:          round2(res.todayRange),
+						Price:          round2(res.todayPrice),
+						Gain:           round2(res.todayGain),
+						NDayAvg:        round2(res.NDayAvg),
+						OverMA:         res.overMA,
+						T38OverBought:  isT38OverBought,
+						T44OverBought:  isT44OverBought,
+						MTSSOverBought: isMTSSOverBought,
+					})
 					fmt.Printf("No:%6s Range: %6.2f Price: %6.2f Gain: %6.2f%% NDayAvg:%6.2f overMA:%t T38OverBought:%t(%v) T44OverBought:%t(%v) MTSSOverBought:%t\n",
 						v.No,
 						res.todayRange,
@@ -450,8 +457,17 @@ func getTWSE(date time.Time, category string, minDataNum int, t38 *twse.TWT38U,
 			fmt.Fprintln(os.Stderr, err)
 		}
 	}
-	return nil
 
+	format, err := exportFormatOf(*useFormat, *useOut)
+	if err != nil {
+		return err
+	}
+	out := *useOut
+	if out == "" {
+		year, month, day := date.Date()
+		out = fmt.Sprintf("%d%02d%02d.%s", year, month, day, format)
+	}
+	return writeExport(string(format), out, picked)
 }
 
 func getOTC(category string, minDataNum int) error {
@@ -460,6 +476,15 @@ func getOTC(category string, minDataNum int) error {
 
 	oList := otc.GetCategoryList(category)
 
+	var (
+		t38 = twse.NewOTCTWT38U(RecentlyOpendtoday)
+		t44 = twse.NewOTCTWT44U(RecentlyOpendtoday)
+	)
+	mtssMapData, err := twse.NewOTCTWMTSS(RecentlyOpendtoday).GetData()
+	if err != nil {
+		return errors.Wrap(err, "OTC MTSS GetData Fail.")
+	}
+
 	/*year, month, day := RecentlyOpendtoday.Date()
 
 	csvFile, err := os.OpenFile(fmt.Sprintf("%d%02d%02d.csv", year, month, day), os.O_APPEND|os.O_CREATE|os.O_RDWR, 0666)
@@ -473,7 +498,14 @@ func getOTC(category string, minDataNum int) error {
 	for _, v := range oList {
 		stock := twse.NewOTC(v.No, RecentlyOpendtoday)
 		if err := prepareStock(stock, minDataNum); err == nil {
+			isT38OverBought, t38Increase, t38ValList := t38.IsOverBoughtDates(v.No, fiNetBuyDay)
+			isT44OverBought, t44Increase, t44ValList := t44.IsOverBoughtDates(v.No, itNetBuyDay)
+			isMTSSOverBought := mtssMapData[v.No].MT.Total > 0 && mtssMapData[v.No].SS.Total > 0
 			if res, err := showStock(stock, minDataNum); err == nil {
+				if (*useT38 && !isT38OverBought) || (*useT44 && !isT44OverBought) || (*useMtss && !isMTSSOverBought) ||
+					(fiIncrementalBuy && !t38Increase) || (itIncrementalBuy && !t44Increase) {
+					continue
+				}
 				/*err = csvWriter.Write([]string{v.No,
 					fmt.Sprintf("%.2f", res.todayRange),
 					fmt.Sprintf("%.2f", res.todayPrice),
@@ -488,13 +520,18 @@ func getOTC(category string, minDataNum int) error {
 				if err != nil {
 					return err
 				}*/
-				fmt.Printf("No: %6s Range: %.2f Price: %.2f Gain: %.2f%% NDayAvg:%.2f overMA:%t\n",
+				fmt.Printf("No: %6s Range: %.2f Price: %.2f Gain: %.2f%% NDayAvg:%.2f overMA:%t T38OverBought:%t(%v) T44OverBought:%t(%v) MTSSOverBought:%t\n",
 					v.No,
 					res.todayRange,
 					res.todayPrice,
 					res.todayGain,
 					res.NDayAvg,
 					res.overMA,
+					isT38OverBought,
+					t38ValList,
+					isT44OverBought,
+					t44ValList,
+					isMTSSOverBought,
 				)
 			}
 		} else {
@@ -519,26 +556,23 @@ var testCmd = &cobra.Command{
 }
 
 func prepareStock(stock *twse.Data, mindata int) error {
-
-	if _, err := stock.Get(); err != nil {
-		return err
+	var (
+		days = mindata
+		err  = stock.LoadDays(days)
+	)
+	// 停牌的日子沒有資料，每次往前多載入約一個月，直到資料足夠或沒有新的資料
+	for start := -1; stock.Len() < mindata && stock.Len() != start; {
+		start = stock.Len()
+		days += 20
+		err = stock.LoadDays(days)
 	}
 	utils.Dbgln("stock len:", stock.Len())
+	// 只有沒有資料的月份（如停牌）失敗時不視為錯誤
+	if loadErr, ok := err.(*twse.LoadError); err != nil && (!ok || !loadErr.NoData()) {
+		return err
+	}
 	if stock.Len() < mindata {
-		start := stock.Len()
-		for {
-			stock.PlusData()
-			if stock.Len() > mindata {
-				break
-			}
-			if stock.Len() == start {
-				break
-			}
-			start = stock.Len()
-		}
-		if stock.Len() < mindata {
-			return errors.New("Can't prepare enough data please check file has data or remove cache file")
-		}
+		return errors.New("Can't prepare enough data please check file has data or remove cache file")
 	}
 	return nil
 }
@@ -656,6 +690,8 @@ func init() {
 	useCp = getAllStockCmd.PersistentFlags().BoolP("cp" /*closing price*/, "c", false, "使用收盤價篩選")
 	date := tradingdays.FindRecentlyOpenedTaipeiZone(time.Now()).Format(shortForm)
 	useDate = getAllStockCmd.PersistentFlags().StringP("date", "d", date, "使用自訂日期")
+	useFormat = getAllStockCmd.PersistentFlags().String("format", "", "篩選結果的匯出格式：csv、jsonl、parquet（default: 依 --out 副檔名判斷，無法判斷時為 csv）")
+	useOut = getAllStockCmd.PersistentFlags().StringP("out", "o", "", "篩選結果的輸出檔案，\"-\" 為 stdout（default: 資料日期，例：20170707.csv）")
 	useNo = getStockCmd.Flags().StringP("No", "t", "2330", "stock No")
 
 	getAllStockCmd.AddCommand(getTPEXCmd)
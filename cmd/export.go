@@ -0,0 +1,351 @@
+// Copyright © 2017 Toomore Chiang
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/export"
+	"github.com/DoubleChuang/gogrs/tradingdays"
+	"github.com/DoubleChuang/gogrs/twse"
+	"github.com/DoubleChuang/gogrs/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat   *string
+	exportOut      *string
+	exportDate     *string
+	exportMonths   *int
+	exportTWSENo   *string
+	exportOTCNo    *string
+	exportCategory *string
+	exportDir      *string
+	exportAdjusted *bool
+	exportStrict   *bool
+)
+
+// dailyRow 匯出的每日資料，加上股票代碼與名稱
+type dailyRow struct {
+	No   string `json:"no"`
+	Name string `json:"name"`
+	twse.FmtData
+}
+
+// t86Row 匯出的三大法人買賣超，加上日期
+type t86Row struct {
+	Date time.Time `json:"date"`
+	twse.T86Data
+}
+
+// mtssRow 匯出的融資融券，加上日期
+type mtssRow struct {
+	Date time.Time `json:"date"`
+	twse.BaseMTSS
+}
+
+// listRow 匯出的上市股票列表，加上日期
+type listRow struct {
+	Date time.Time `json:"date"`
+	twse.FmtListData
+}
+
+// exportFormatOf 依 --format 或輸出檔案的副檔名決定匯出格式，無法判斷時為 CSV
+func exportFormatOf(format, out string) (export.Format, error) {
+	if format != "" {
+		return export.ParseFormat(format)
+	}
+	return export.FormatFromPath(out), nil
+}
+
+// writeExport 依 --format、--out 匯出，out 為空或 "-" 時輸出至 stdout
+func writeExport(format, out string, rows interface{}) error {
+	f, err := exportFormatOf(format, out)
+	if err != nil {
+		return err
+	}
+	if out == "" || out == "-" {
+		return export.Write(os.Stdout, f, rows)
+	}
+	return export.WriteFile(out, f, rows)
+}
+
+// exportWarn 寬鬆模式下有欄位解析失敗時輸出驗證報告
+func exportWarn(report *twse.Report) {
+	if !report.OK() {
+		fmt.Fprintln(os.Stderr, report)
+	}
+}
+
+func exportDaily(client *twse.Client, date time.Time) (interface{}, error) {
+	var (
+		from   = time.Date(date.Year(), date.Month()-time.Month(*exportMonths-1), 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		stocks []*twse.Data
+		rows   []dailyRow
+	)
+	if *exportTWSENo != "" {
+		for _, no := range strings.Split(*exportTWSENo, ",") {
+			stocks = append(stocks, client.NewTWSE(no, date))
+		}
+	}
+	if *exportOTCNo != "" {
+		for _, no := range strings.Split(*exportOTCNo, ",") {
+			stocks = append(stocks, client.NewOTC(no, date))
+		}
+	}
+	if len(stocks) == 0 {
+		return nil, fmt.Errorf("daily: need --twse or --otc")
+	}
+	for _, stock := range stocks {
+		if err := stock.LoadRange(from, date); err != nil {
+			return nil, err
+		}
+		exportWarn(stock.Report())
+		data := stock.FormatData()
+		if *exportAdjusted {
+			data = stock.FormatAdjustedData()
+			if err := stock.ExRightsErr(); err != nil {
+				return nil, err
+			}
+		}
+		for _, v := range data {
+			rows = append(rows, dailyRow{No: stock.No, Name: stock.Name, FmtData: v})
+		}
+	}
+	return rows, nil
+}
+
+func exportList(client *twse.Client, date time.Time) (interface{}, error) {
+	var (
+		l    = client.NewLists(date)
+		rows []listRow
+	)
+	if _, err := l.Get(*exportCategory); err != nil {
+		return nil, err
+	}
+	exportWarn(l.Report())
+	for _, s := range l.GetCategoryList(*exportCategory) {
+		// 無成交的股票不在 FmtData
+		if data, ok := l.FmtData[s.No]; ok {
+			rows = append(rows, listRow{Date: date, FmtListData: data})
+		}
+	}
+	return rows, nil
+}
+
+func exportT86(client *twse.Client, date time.Time) (interface{}, error) {
+	t86 := client.NewT86(date)
+	data, err := t86.Get(*exportCategory)
+	if err != nil {
+		return nil, err
+	}
+	exportWarn(t86.Report())
+	var rows = make([]t86Row, len(data))
+	for i, v := range data {
+		rows[i] = t86Row{Date: date, T86Data: v}
+	}
+	return rows, nil
+}
+
+func exportMTSS(client *twse.Client, date time.Time) (interface{}, error) {
+	mtss := client.NewTWMTSS(date, *exportCategory)
+	data, err := mtss.Get()
+	if err != nil {
+		return nil, err
+	}
+	exportWarn(mtss.Report())
+	var rows = make(map[string]mtssRow, len(data))
+	for no, v := range data {
+		rows[no] = mtssRow{Date: date, BaseMTSS: v}
+	}
+	return rows, nil
+}
+
+func exportWeight(client *twse.Client, date time.Time) (interface{}, error) {
+	data, report, err := client.GetWeight(date)
+	if err != nil {
+		return nil, err
+	}
+	exportWarn(report)
+	return data, nil
+}
+
+// exportValuation 指定 --twse 或 --otc 時匯出個股最近幾個月的資料，否則匯出當日上市與上櫃全部個股
+func exportValuation(client *twse.Client, date time.Time) (interface{}, error) {
+	var rows []twse.ValuationData
+	if *exportTWSENo == "" && *exportOTCNo == "" {
+		for _, v := range []*twse.Valuation{client.NewValuation(date), client.NewOTCValuation(date)} {
+			data, err := v.Get()
+			if err != nil {
+				return nil, err
+			}
+			exportWarn(v.Report())
+			rows = append(rows, data...)
+		}
+		return rows, nil
+	}
+
+	var (
+		from    = time.Date(date.Year(), date.Month()-time.Month(*exportMonths-1), 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		history []*twse.ValuationHistory
+	)
+	if *exportTWSENo != "" {
+		for _, no := range strings.Split(*exportTWSENo, ",") {
+			history = append(history, client.NewValuationHistory(no))
+		}
+	}
+	if *exportOTCNo != "" {
+		for _, no := range strings.Split(*exportOTCNo, ",") {
+			history = append(history, client.NewOTCValuationHistory(no))
+		}
+	}
+	for _, h := range history {
+		if err := h.LoadRange(from, date); err != nil {
+			return nil, err
+		}
+		exportWarn(h.Report())
+		rows = append(rows, h.Data...)
+	}
+	return rows, nil
+}
+
+func exportSBL(client *twse.Client, date time.Time) (interface{}, error) {
+	t := client.NewTWT93U(date)
+	data, err := t.Get()
+	if err != nil {
+		return nil, err
+	}
+	exportWarn(t.Report())
+	return data, nil
+}
+
+func exportQFIIS(client *twse.Client, date time.Time) (interface{}, error) {
+	q := client.NewQFIIS(date)
+	data, err := q.Get()
+	if err != nil {
+		return nil, err
+	}
+	exportWarn(q.Report())
+	return data, nil
+}
+
+// exportRevenue 匯出資料日期上個月的上市與上櫃公司營收
+func exportRevenue(client *twse.Client, date time.Time) (interface{}, error) {
+	var (
+		month = time.Date(date.Year(), date.Month()-1, 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		rows  []twse.MonthlyRevenue
+	)
+	for _, r := range []*twse.Revenue{client.NewRevenue(month), client.NewOTCRevenue(month)} {
+		data, err := r.Get()
+		if err != nil {
+			return nil, err
+		}
+		exportWarn(r.Report())
+		rows = append(rows, data...)
+	}
+	return rows, nil
+}
+
+var exportDatasets = map[string]func(*twse.Client, time.Time) (interface{}, error){
+	"daily":     exportDaily,
+	"list":      exportList,
+	"t86":       exportT86,
+	"mtss":      exportMTSS,
+	"weight":    exportWeight,
+	"valuation": exportValuation,
+	"revenue":   exportRevenue,
+	"sbl":       exportSBL,
+	"qfiis":     exportQFIIS,
+}
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export [daily|list|t86|mtss|weight|valuation|revenue|sbl|qfiis]",
+	Short: "export data",
+	Long:  `匯出每日資料、上市股票列表、三大法人買賣超、融資融券、大盤成交資訊、本益比、殖利率及股價淨值比、月營收、融券借券賣出餘額、外資及陸資持股為 CSV、JSON Lines 或 Parquet`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		tradingdays.DownloadCSV(true)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 || exportDatasets[args[0]] == nil {
+			cmd.Help()
+			return
+		}
+
+		if _, err := exportFormatOf(*exportFormat, *exportOut); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		var date time.Time
+		if *exportDate != "" {
+			var err error
+			if date, err = time.ParseInLocation("20060102", *exportDate, utils.TaipeiTimeZone); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		} else {
+			recent := tradingdays.FindRecentlyOpened(time.Now())
+			date = time.Date(recent.Year(), recent.Month(), recent.Day(), 0, 0, 0, 0, utils.TaipeiTimeZone)
+		}
+		if *exportMonths < 1 {
+			*exportMonths = 1
+		}
+
+		var opts = []twse.ClientOption{twse.WithStrict(*exportStrict)}
+		if *exportDir != "" {
+			store, err := twse.NewFileStore(*exportDir)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			opts = append(opts, twse.WithStore(store))
+		}
+
+		rows, err := exportDatasets[args[0]](twse.NewClient(opts...), date)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if err := writeExport(*exportFormat, *exportOut, rows); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	exportFormat = exportCmd.Flags().StringP("format", "f", "", "匯出格式：csv、jsonl、parquet（default: 依 --out 副檔名判斷，無法判斷時為 csv）")
+	exportOut = exportCmd.Flags().StringP("out", "o", "", "輸出檔案（default: stdout）")
+	exportDate = exportCmd.Flags().StringP("date", "d", "", "資料日期，例：20170707，revenue 匯出上個月的營收（default: 最近一個開市日）")
+	exportMonths = exportCmd.Flags().IntP("months", "m", 1, "daily、valuation：匯出最近幾個月")
+	exportTWSENo = exportCmd.Flags().StringP("twse", "t", "", "daily、valuation：上市股票代碼，可使用 ',' 分隔多組代碼，例：2618,2329")
+	exportOTCNo = exportCmd.Flags().StringP("otc", "c", "", "daily、valuation：上櫃股票代碼，可使用 ',' 分隔多組代碼，例：4406,8446")
+	exportAdjusted = exportCmd.Flags().BoolP("adjusted", "a", false, "daily：匯出還原權值後的價格")
+	exportDir = exportCmd.Flags().StringP("store", "s", "", "daily：本地歷史資料位置（gogrs sync），指定時先讀取本地資料")
+	exportCategory = exportCmd.Flags().StringP("category", "g", "ALLBUT0999", "list、t86、mtss：類別")
+	exportStrict = exportCmd.Flags().Bool("strict", false, "嚴格模式，欄位解析失敗時停止匯出")
+
+	RootCmd.AddCommand(exportCmd)
+}
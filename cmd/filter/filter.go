@@ -2,6 +2,7 @@
 package filter
 
 import (
+	"github.com/DoubleChuang/gogrs/indicator"
 	"github.com/DoubleChuang/gogrs/twse"
 	"github.com/DoubleChuang/gogrs/utils"
 )
@@ -276,6 +277,44 @@ func (Check07) CheckFunc(b ...*twse.Data) bool {
 	return false
 }
 
+// Check08 收盤突破 45 日內波段壓力
+type Check08 struct{}
+
+// No for check no.
+func (Check08) No() uint64 {
+	return 8
+}
+
+// String to string.
+func (Check08) String() string {
+	return "收盤突破 45 日內波段壓力"
+}
+
+// Mindata is filter required a minimum of data.
+func (Check08) Mindata() int {
+	return 45
+}
+
+// CheckFunc func to check.
+func (Check08) CheckFunc(b ...*twse.Data) bool {
+	if !prepareData(b...)[0] {
+		return false
+	}
+
+	var (
+		data   = b[0].FormatData()
+		recent = data[len(data)-45:]
+		levels = indicator.Levels(indicator.SwingPoints(recent[:len(recent)-1], 0.05), 0.02)
+		last   = recent[len(recent)-1].Date
+	)
+	for _, v := range indicator.Breakouts(recent, levels) {
+		if v.Up && v.Date.Equal(last) {
+			return true
+		}
+	}
+	return false
+}
+
 func prepareData(b ...*twse.Data) []bool {
 	var (
 		result  []bool
@@ -325,4 +364,5 @@ func init() {
 	AllList.Add(CheckGroup(Check05{}))
 	AllList.Add(CheckGroup(Check06{}))
 	AllList.Add(CheckGroup(Check07{}))
+	AllList.Add(CheckGroup(Check08{}))
 }
@@ -35,6 +35,7 @@ var (
 	cyan      = color.New(color.FgCyan).SprintfFunc()
 	index     *bool
 	nonstop   *int64
+	oddLot    *bool
 	pt        *bool
 )
 
@@ -103,6 +104,30 @@ func rtprettyprint(data realtime.Data) string {
 	)
 }
 
+// rtoddprint 盤中零股的成交價與最佳一檔買賣價、量（股）
+func rtoddprint(data realtime.Data) string {
+	var bid, ask string
+	if len(data.BestBidPrice) > 0 && len(data.BestBidVolume) > 0 {
+		bid = fmt.Sprintf("%.2f(%d)", data.BestBidPrice[0], data.BestBidVolume[0])
+	}
+	if len(data.BestAskPrice) > 0 && len(data.BestAskVolume) > 0 {
+		ask = fmt.Sprintf("%.2f(%d)", data.BestAskPrice[0], data.BestAskVolume[0])
+	}
+	return cyan("零股 $%.2f 買 %s 賣 %s %.0f股", data.Price, bid, ask, data.VolumeAcc)
+}
+
+// oddLotOf 回傳同一檔股票的盤中零股，指數沒有零股時回傳 nil
+func oddLotOf(r *realtime.StockRealTime) *realtime.StockRealTime {
+	switch {
+	case r.OddLot || r.No == "t00" || r.No == "o00" || r.No == "FRMSA":
+		return nil
+	case r.Exchange == "otc":
+		return realtime.NewOTCOddLot(r.No, r.Date)
+	default:
+		return realtime.NewTWSEOddLot(r.No, r.Date)
+	}
+}
+
 func fetch(r *realtime.StockRealTime) {
 	limit <- struct{}{}
 	runtime.Gosched()
@@ -111,7 +136,13 @@ func fetch(r *realtime.StockRealTime) {
 	if data.TradeTime.IsZero() {
 		log.Println("No data")
 	} else {
-		log.Println(rtprettyprint(data))
+		var line = rtprettyprint(data)
+		if odd := oddLotOf(r); *oddLot && odd != nil {
+			if oddData, err := odd.Get(); err == nil {
+				line = fmt.Sprintf("%s %s", line, rtoddprint(oddData))
+			}
+		}
+		log.Println(line)
 	}
 	if *count {
 		counter++
@@ -250,6 +281,7 @@ func init() {
 	index = realtimeCmd.Flags().BoolP("index", "i", false, "顯示大盤、上櫃、寶島指數（default: false）")
 	ncpu = realtimeCmd.Flags().IntP("ncpu", "n", runtime.NumCPU(), "指定 CPU 數量，預設為實際 CPU 數量")
 	nonstop = realtimeCmd.Flags().Int64P("nonstop", "", 0, "自動重複，單位秒數")
+	oddLot = realtimeCmd.Flags().BoolP("odd", "", false, "同時顯示盤中零股的成交價與最佳一檔買賣價")
 	otcCate = realtimeCmd.Flags().StringP("otccate", "e", "", "上櫃股票類別，可使用 ',' 分隔多組代碼，例：02,14")
 	otcNo = realtimeCmd.Flags().StringP("otc", "o", "", "上櫃股票代碼，可使用 ',' 分隔多組代碼，例：4406,8446")
 	pt = realtimeCmd.Flags().BoolP("pt", "", false, "計算花費時間")
@@ -15,6 +15,7 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"runtime"
 	"strings"
 	"sync"
@@ -23,12 +24,14 @@ import (
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/DoubleChuang/gogrs/cmd/filter"
+	"github.com/DoubleChuang/gogrs/indicator"
 	"github.com/DoubleChuang/gogrs/tradingdays"
 	"github.com/DoubleChuang/gogrs/twse"
 )
 
 var (
 	wg           sync.WaitGroup
+	adjusted     *bool
 	twseNo       *string
 	twseCate     *string
 	otcNo        *string
@@ -36,6 +39,11 @@ var (
 	showcatelist *bool
 	showcolor    *bool
 	ncpu         *int
+	showpattern  *bool
+	reportFormat *string
+	reportOut    *string
+	reportRows   []reportRow
+	reportMu     sync.Mutex
 	white        = color.New(color.FgWhite, color.Bold).SprintfFunc()
 	red          = color.New(color.FgRed, color.Bold).SprintfFunc()
 	green        = color.New(color.FgGreen, color.Bold).SprintfFunc()
@@ -45,6 +53,39 @@ var (
 	limit        = make(chan struct{}, 1)
 )
 
+// reportRow 匯出的篩選結果，Check 為符合的篩選條件或 K 線型態
+type reportRow struct {
+	Check  string    `json:"check"`
+	No     string    `json:"no"`
+	Name   string    `json:"name"`
+	Date   time.Time `json:"date"`
+	Price  float64   `json:"price"`
+	Range  float64   `json:"range"`
+	Volume uint64    `json:"volume"`
+}
+
+// exporting 指定 --format 或 --out 時匯出篩選結果而不顯示
+func exporting() bool {
+	return *reportFormat != "" || *reportOut != ""
+}
+
+// newReportRow 最後一日的資料
+func newReportRow(check string, stock *twse.Data) reportRow {
+	var (
+		dates = stock.GetDateList()
+		i     = len(dates) - 1
+	)
+	return reportRow{
+		Check:  check,
+		No:     stock.No,
+		Name:   stock.Name,
+		Date:   dates[i],
+		Price:  stock.GetPriceList()[i],
+		Range:  stock.GetDailyRangeList()[i],
+		Volume: stock.GetVolumeList()[i],
+	}
+}
+
 func prettyprint(stock *twse.Data, check filter.CheckGroup) string {
 	var (
 		Open        = stock.GetOpenList()[len(stock.GetOpenList())-1]
@@ -74,10 +115,35 @@ func prettyprint(stock *twse.Data, check filter.CheckGroup) string {
 	)
 }
 
+func prettypattern(stock *twse.Data, patterns []indicator.Pattern) string {
+	var result = make([]string, len(patterns))
+	for i, p := range patterns {
+		var outputcolor = white
+		switch p.Trend {
+		case 1:
+			outputcolor = red
+		case -1:
+			outputcolor = green
+		}
+		result[i] = outputcolor("%s(%.2f)", p.Name, p.Strength)
+	}
+	return fmt.Sprintf("%s %s %s",
+		blue("%s", stock.RawData[stock.Len()-1][0]),
+		white("%s %s", stock.No, stock.Name),
+		strings.Join(result, " "),
+	)
+}
+
 func gocheck(check filter.CheckGroup, stock *twse.Data) {
 	defer wg.Done()
 	if check.CheckFunc(stock) {
-		fmt.Println(prettyprint(stock, check))
+		if exporting() {
+			reportMu.Lock()
+			reportRows = append(reportRows, newReportRow(check.String(), stock))
+			reportMu.Unlock()
+		} else {
+			fmt.Println(prettyprint(stock, check))
+		}
 	}
 	<-limit
 }
@@ -146,16 +212,18 @@ func run() int {
 	datalist = make([]*twse.Data, len(twselist)+len(twsecatelist)+len(otclist)+len(otccatelist))
 
 	for i, no := range append(twselist, twsecatelist...) {
-		datalist[i] = twse.NewTWSE(no, tradingdays.FindRecentlyOpened(time.Now()))
+		datalist[i] = twse.NewTWSE(no, tradingdays.FindRecentlyOpened(time.Now())).SetAdjusted(*adjusted)
 	}
 	otcdelta = len(twselist) + len(twsecatelist)
 	for i, no := range append(otclist, otccatelist...) {
-		datalist[i+otcdelta] = twse.NewOTC(no, tradingdays.FindRecentlyOpened(time.Now()))
+		datalist[i+otcdelta] = twse.NewOTC(no, tradingdays.FindRecentlyOpened(time.Now())).SetAdjusted(*adjusted)
 	}
 
 	if len(datalist) > 0 {
 		for _, check := range filter.AllList {
-			fmt.Println(yellowBold("----- %v -----", check))
+			if !exporting() {
+				fmt.Println(yellowBold("----- %v -----", check))
+			}
 			wg.Add(len(datalist))
 			for _, stock := range datalist {
 				limit <- struct{}{}
@@ -164,6 +232,28 @@ func run() int {
 			wg.Wait()
 		}
 	}
+
+	if len(datalist) > 0 && *showpattern {
+		if !exporting() {
+			fmt.Println(yellowBold("----- K 線型態 -----"))
+		}
+		for _, stock := range datalist {
+			if stock.Len() == 0 {
+				stock.Get()
+			}
+			if stock.Len() == 0 {
+				continue
+			}
+			patterns := indicator.LastPatterns(stock.FormatData())
+			if exporting() {
+				for _, p := range patterns {
+					reportRows = append(reportRows, newReportRow(string(p.Name), stock))
+				}
+			} else if len(patterns) > 0 {
+				fmt.Println(prettypattern(stock, patterns))
+			}
+		}
+	}
 	return len(datalist)
 }
 
@@ -176,18 +266,33 @@ var reportCmd = &cobra.Command{
 		tradingdays.DownloadCSV(true)
 	},
 	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := exportFormatOf(*reportFormat, *reportOut); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 		if run() == 0 {
 			cmd.Help()
+			return
+		}
+		if exporting() {
+			if err := writeExport(*reportFormat, *reportOut, reportRows); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
 		}
 	},
 }
 
 func init() {
+	adjusted = reportCmd.Flags().BoolP("adjusted", "a", false, "移動平均使用還原權值的收盤價")
+	reportFormat = reportCmd.Flags().StringP("format", "f", "", "匯出篩選結果的格式：csv、jsonl、parquet（default: 依 --out 副檔名判斷，無法判斷時為 csv）")
+	reportOut = reportCmd.Flags().String("out", "", "匯出篩選結果至檔案，\"-\" 為 stdout；指定 --format 或 --out 時不顯示報表")
 	ncpu = reportCmd.Flags().IntP("ncpu", "n", runtime.NumCPU(), "指定 CPU 數量，預設為實際 CPU 數量")
 	otcCate = reportCmd.Flags().StringP("otccate", "e", "", "上櫃股票類別，可使用 ',' 分隔多組代碼，例：02,14")
 	otcNo = reportCmd.Flags().StringP("otc", "o", "", "上櫃股票代碼，可使用 ',' 分隔多組代碼，例：4406,8446")
 	showcatelist = reportCmd.Flags().BoolP("catelist", "l", false, "顯示上市/上櫃分類表")
 	showcolor = reportCmd.Flags().BoolP("color", "", true, "色彩化")
+	showpattern = reportCmd.Flags().BoolP("pattern", "p", false, "顯示最後一日的 K 線型態")
 	twseCate = reportCmd.Flags().StringP("twsecate", "c", "", "上市股票類別，可使用 ',' 分隔多組代碼，例：11,15")
 	twseNo = reportCmd.Flags().StringP("twse", "t", "", "上市股票代碼，可使用 ',' 分隔多組代碼，例：2618,2329")
 
@@ -0,0 +1,172 @@
+// Copyright © 2017 Toomore Chiang
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/tradingdays"
+	"github.com/DoubleChuang/gogrs/twse"
+	"github.com/DoubleChuang/gogrs/utils"
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncDir      *string
+	syncMonths   *int
+	syncNcpu     *int
+	syncTWSENo   *string
+	syncTWSECate *string
+	syncOTCNo    *string
+	syncOTCCate  *string
+)
+
+// syncStoreDir 回傳本地歷史資料位置，預設為 $HOME/.gogrs/store
+func syncStoreDir() (string, error) {
+	if *syncDir != "" {
+		return *syncDir, nil
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".gogrs", "store"), nil
+}
+
+func syncStocks(client *twse.Client, date time.Time) []*twse.Data {
+	var (
+		twselist []string
+		otclist  []string
+		result   []*twse.Data
+	)
+	if *syncTWSENo != "" {
+		twselist = strings.Split(*syncTWSENo, ",")
+	}
+	if *syncTWSECate != "" {
+		l := client.NewLists(date)
+		for _, cate := range strings.Split(*syncTWSECate, ",") {
+			for _, s := range l.GetCategoryList(cate) {
+				twselist = append(twselist, s.No)
+			}
+		}
+	}
+	if *syncOTCNo != "" {
+		otclist = strings.Split(*syncOTCNo, ",")
+	}
+	if *syncOTCCate != "" {
+		o := client.NewOTCLists(date)
+		for _, cate := range strings.Split(*syncOTCCate, ",") {
+			for _, s := range o.GetCategoryList(cate) {
+				otclist = append(otclist, s.No)
+			}
+		}
+	}
+	for _, no := range twselist {
+		result = append(result, client.NewTWSE(no, date))
+	}
+	for _, no := range otclist {
+		result = append(result, client.NewOTC(no, date))
+	}
+	return result
+}
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "sync local store",
+	Long:  `同步本地歷史資料至最近一個開市日，只擷取尚未同步的月份`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		tradingdays.DownloadCSV(true)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, err := syncStoreDir()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		store, err := twse.NewFileStore(dir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		if *syncMonths < 1 {
+			*syncMonths = 1
+		}
+		var (
+			client = twse.NewClient(twse.WithStore(store))
+			recent = tradingdays.FindRecentlyOpened(time.Now())
+			date   = time.Date(recent.Year(), recent.Month(), recent.Day(), 0, 0, 0, 0, utils.TaipeiTimeZone)
+			from   = time.Date(date.Year(), date.Month()-time.Month(*syncMonths-1), 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+			stocks = syncStocks(client, date)
+			limit  = make(chan struct{}, *syncNcpu)
+			wg     sync.WaitGroup
+			fail   int
+			mu     sync.Mutex
+		)
+		if len(stocks) == 0 {
+			cmd.Help()
+			return
+		}
+
+		outputNote("Sync:", dir, from.Format("2006/01/02"), "~", date.Format("2006/01/02"), len(stocks), "stocks")
+		for _, stock := range stocks {
+			wg.Add(1)
+			limit <- struct{}{}
+			go func(stock *twse.Data) {
+				defer wg.Done()
+				defer func() { <-limit }()
+				// 沒有交易的月份已記錄在 Store，不視為失敗
+				if err := stock.LoadRange(from, date); err != nil {
+					if loadErr, ok := err.(*twse.LoadError); !ok || !loadErr.NoData() {
+						mu.Lock()
+						fail++
+						mu.Unlock()
+						fmt.Fprintln(os.Stderr, err)
+					}
+				}
+				if report := stock.Report(); !report.OK() {
+					fmt.Fprintln(os.Stderr, report)
+				}
+			}(stock)
+		}
+		wg.Wait()
+		outputDone("Done!", len(stocks)-fail, "ok,", fail, "fail")
+	},
+}
+
+func init() {
+	syncDir = syncCmd.Flags().StringP("dir", "d", "", "本地歷史資料位置（default: $HOME/.gogrs/store）")
+	syncMonths = syncCmd.Flags().IntP("months", "m", 3, "同步最近幾個月")
+	syncNcpu = syncCmd.Flags().IntP("ncpu", "n", 4, "同時同步的股票數量")
+	syncOTCCate = syncCmd.Flags().StringP("otccate", "e", "", "上櫃股票類別，可使用 ',' 分隔多組代碼，例：02,14")
+	syncOTCNo = syncCmd.Flags().StringP("otc", "o", "", "上櫃股票代碼，可使用 ',' 分隔多組代碼，例：4406,8446")
+	syncTWSECate = syncCmd.Flags().StringP("twsecate", "c", "", "上市股票類別，可使用 ',' 分隔多組代碼，例：11,15")
+	syncTWSENo = syncCmd.Flags().StringP("twse", "t", "", "上市股票代碼，可使用 ',' 分隔多組代碼，例：2618,2329")
+
+	RootCmd.AddCommand(syncCmd)
+}
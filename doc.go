@@ -6,11 +6,11 @@ gogrs 是擷取台灣上市股票股價資訊工具，目前還在大量的開
 
 Package realtime
 
-擷取盤中個股、指數即時股價資訊
+擷取盤中個股、盤中零股、指數即時股價資訊
 
 Package twse
 
-擷取台灣股市上市、上櫃股票資訊、外資及陸資持股比率前二十名彙總表、 三大法人買賣金額統計表、三大法人買賣超日報、自營商、投信、外資及陸資買賣超彙總表
+擷取台灣股市上市、上櫃股票資訊、零股行情、個股本益比、殖利率及股價淨值比、每月營業收入、集保戶股權分散表、融券借券賣出餘額、當日沖銷交易、外資及陸資投資持股統計、外資及陸資持股比率前二十名彙總表、 三大法人買賣金額統計表、上市/上櫃三大法人買賣超日報、自營商、投信、外資及陸資買賣超彙總表、融資融券
 
 Package tradingdays
 
@@ -20,5 +20,17 @@ Package utils
 
 套件所需的公用工具
 
+Package indicator
+
+技術指標（KD、RSI、MACD、布林通道、ATR、肯特納通道、OBV、MFI、量比、K 線型態、波段高低點與支撐壓力）
+
+Package export
+
+匯出每日資料、上市股票列表、三大法人買賣超、融資融券、大盤成交資訊為 CSV、JSON Lines、Parquet
+
+Package fundamental
+
+公開資訊觀測站季度綜合損益表與資產負債表彙總（EPS TTM、ROE、毛利率、負債比率）
+
 */
 package main
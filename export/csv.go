@@ -0,0 +1,50 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// writeCSV 第一列為欄位名稱，日期為 2006-01-02
+func (t *table) writeCSV(w io.Writer) error {
+	var (
+		cw     = csv.NewWriter(w)
+		header = make([]string, len(t.columns))
+	)
+	for i, c := range t.columns {
+		header[i] = c.name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, row := range t.rows {
+		record := make([]string, len(t.columns))
+		for i, c := range t.columns {
+			record[i] = formatValue(c.kind, c.value(row))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatValue 將值轉為字串
+func formatValue(k kind, v reflect.Value) string {
+	switch k {
+	case kindBool:
+		return strconv.FormatBool(v.Bool())
+	case kindInt:
+		return strconv.FormatInt(v.Int(), 10)
+	case kindUint:
+		return strconv.FormatUint(v.Uint(), 10)
+	case kindFloat:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case kindDate:
+		return date(v).Format("2006-01-02")
+	}
+	return v.String()
+}
@@ -0,0 +1,8 @@
+// Package export - 匯出資料
+// 將 twse 的每日資料（FmtData）、上市列表（FmtListData）、三大法人買賣超（T86Data）、
+// 融資融券（BaseMTSS）、大盤成交資訊（WeightData）等序列化為 CSV、JSON Lines 與 Parquet。
+//
+// Parquet 只使用最基本的格式（見 parquet.go），目前僅以本套件的測試讀回驗證，
+// 尚未以 pyarrow、DuckDB 等其他實作驗證相容性。
+//
+package export
@@ -0,0 +1,233 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Format 匯出格式
+type Format string
+
+// 支援的匯出格式
+const (
+	CSV     Format = "csv"
+	JSONL   Format = "jsonl"
+	Parquet Format = "parquet"
+)
+
+// ParseFormat 解析格式名稱，不分大小寫，json、ndjson 視為 JSON Lines
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "csv":
+		return CSV, nil
+	case "jsonl", "json", "ndjson":
+		return JSONL, nil
+	case "parquet", "pq":
+		return Parquet, nil
+	}
+	return "", fmt.Errorf("export: unknown format %q", name)
+}
+
+// FormatFromPath 由副檔名判斷格式，無法判斷時回傳 CSV
+func FormatFromPath(path string) Format {
+	if f, err := ParseFormat(filepath.Ext(path)); err == nil {
+		return f
+	}
+	return CSV
+}
+
+// Write 將 rows 依 format 寫入 w
+//
+// rows 為 struct（或 struct 指標）的 slice，或值為 struct 的 map（依 key 排序）。
+// 欄位名稱取自 json tag，無 tag 時為欄位名稱的 snake_case；
+// 巢狀 struct 以底線連接上層名稱（如 fii_buy），嵌入的 struct 直接展開；
+// time.Time 以日期（2006-01-02）輸出。
+func Write(w io.Writer, format Format, rows interface{}) error {
+	t, err := newTable(rows)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case CSV:
+		return t.writeCSV(w)
+	case JSONL:
+		return t.writeJSONL(w)
+	case Parquet:
+		return t.writeParquet(w)
+	}
+	return fmt.Errorf("export: unknown format %q", format)
+}
+
+// WriteFile 將 rows 寫入檔案，format 為空值時由副檔名判斷
+func WriteFile(path string, format Format, rows interface{}) error {
+	if format == "" {
+		format = FormatFromPath(path)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Write(f, format, rows); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// kind 欄位型態
+type kind int
+
+const (
+	kindString kind = iota
+	kindBool
+	kindInt
+	kindUint
+	kindFloat
+	kindDate
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// column 欄位名稱與在 struct 中的位置
+type column struct {
+	name  string
+	index []int
+	kind  kind
+}
+
+// table 欄位與每一列的 struct 值
+type table struct {
+	columns []column
+	rows    []reflect.Value
+}
+
+func newTable(rows interface{}) (*table, error) {
+	var (
+		v      = reflect.ValueOf(rows)
+		t      = &table{}
+		values []reflect.Value
+	)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, v.Index(i))
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, k := range keys {
+			values = append(values, v.MapIndex(k))
+		}
+	default:
+		return nil, fmt.Errorf("export: rows must be a slice or map, got %T", rows)
+	}
+
+	elem := v.Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("export: rows must contain structs, got %s", v.Type().Elem())
+	}
+	t.columns = columns(elem, "", nil)
+
+	for _, row := range values {
+		if row.Kind() == reflect.Ptr {
+			if row.IsNil() {
+				continue
+			}
+			row = row.Elem()
+		}
+		t.rows = append(t.rows, row)
+	}
+	return t, nil
+}
+
+// columns 展開 struct 的欄位
+func columns(t reflect.Type, prefix string, index []int) []column {
+	var result []column
+	for i := 0; i < t.NumField(); i++ {
+		var (
+			f   = t.Field(i)
+			idx = append(append([]int(nil), index...), i)
+		)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := fieldName(f)
+		if name == "-" {
+			continue
+		}
+		switch {
+		case f.Type == timeType:
+			result = append(result, column{name: prefix + name, index: idx, kind: kindDate})
+		case f.Type.Kind() == reflect.Struct && f.Anonymous && f.Tag.Get("json") == "":
+			result = append(result, columns(f.Type, prefix, idx)...)
+		case f.Type.Kind() == reflect.Struct:
+			result = append(result, columns(f.Type, prefix+name+"_", idx)...)
+		default:
+			if k, ok := kindOf(f.Type); ok {
+				result = append(result, column{name: prefix + name, index: idx, kind: k})
+			}
+		}
+	}
+	return result
+}
+
+func kindOf(t reflect.Type) (kind, bool) {
+	switch t.Kind() {
+	case reflect.String:
+		return kindString, true
+	case reflect.Bool:
+		return kindBool, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return kindInt, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return kindUint, true
+	case reflect.Float32, reflect.Float64:
+		return kindFloat, true
+	}
+	return 0, false
+}
+
+// fieldName 取得 json tag 名稱，無 tag 時為 snake_case
+func fieldName(f reflect.StructField) string {
+	if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag != "" {
+		return tag
+	}
+	return snakeCase(f.Name)
+}
+
+// snakeCase 轉換欄位名稱，如 TotalPrice 為 total_price、PERatio 為 pe_ratio
+func snakeCase(name string) string {
+	var (
+		r      = []rune(name)
+		result []rune
+	)
+	for i, c := range r {
+		if i > 0 && unicode.IsUpper(c) &&
+			(!unicode.IsUpper(r[i-1]) || i+1 < len(r) && unicode.IsLower(r[i+1])) {
+			result = append(result, '_')
+		}
+		result = append(result, unicode.ToLower(c))
+	}
+	return string(result)
+}
+
+// value 取得某一列某一欄的值
+func (c column) value(row reflect.Value) reflect.Value {
+	return row.FieldByIndex(c.index)
+}
+
+// date 日期所在日的 00:00 UTC，保留原時區的年月日
+func date(v reflect.Value) time.Time {
+	y, m, d := v.Interface().(time.Time).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
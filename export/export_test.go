@@ -0,0 +1,221 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/twse"
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+var sampleData = []twse.FmtData{
+	{Date: time.Date(2015, 7, 1, 0, 0, 0, 0, utils.TaipeiTimeZone), Volume: 1000, TotalPrice: 12345, Open: 12.3, High: 12.5, Low: 12.1, Price: 12.45, Range: 0.15, Totalsale: 10},
+	{Date: time.Date(2015, 7, 2, 0, 0, 0, 0, utils.TaipeiTimeZone), Volume: 2000, TotalPrice: 25000, Open: 12.45, High: 12.6, Low: 12.4, Price: 12.5, Range: 0.05, Totalsale: 20},
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"CSV": CSV, "json": JSONL, ".jsonl": JSONL, "parquet": Parquet} {
+		if f, err := ParseFormat(name); err != nil || f != want {
+			t.Error("ParseFormat", name, f, err)
+		}
+	}
+	if _, err := ParseFormat("xlsx"); err == nil {
+		t.Error("Should be error")
+	}
+	if f := FormatFromPath("/tmp/2618.parquet"); f != Parquet {
+		t.Error("FormatFromPath", f)
+	}
+	if f := FormatFromPath("-"); f != CSV {
+		t.Error("FormatFromPath", f)
+	}
+}
+
+func TestColumns(t *testing.T) {
+	var cases = []struct {
+		rows interface{}
+		want string
+	}{
+		{sampleData, "date,volume,total_price,open,high,low,price,range,totalsale"},
+		{[]twse.T86Data{}, "no,name,fii_no,fii_name,fii_buy,fii_sell,fii_total,sit_no,sit_name,sit_buy,sit_sell,sit_total,d_prop_no,d_prop_name,d_prop_buy,d_prop_sell,d_prop_total,d_hedge_no,d_hedge_name,d_hedge_buy,d_hedge_sell,d_hedge_total,diff"},
+		{map[string]twse.BaseMTSS{}, "no,name,mt_buy,mt_sell,mt_total,ss_buy,ss_sell,ss_total"},
+		{[]*twse.WeightData{}, "date,volume,total_price,totalsale,point,range"},
+		{[]struct {
+			No string `json:"no"`
+			twse.FmtData
+			PERatio float64
+			skip    int
+		}{}, "no,date,volume,total_price,open,high,low,price,range,totalsale,pe_ratio"},
+	}
+	for _, c := range cases {
+		tb, err := newTable(c.rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, col := range tb.columns {
+			names = append(names, col.name)
+		}
+		if strings.Join(names, ",") != c.want {
+			t.Errorf("%T: %s", c.rows, strings.Join(names, ","))
+		}
+	}
+	if _, err := newTable([]int{1}); err == nil {
+		t.Error("Should be error")
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, CSV, sampleData); err != nil {
+		t.Fatal(err)
+	}
+	want := "date,volume,total_price,open,high,low,price,range,totalsale\n" +
+		"2015-07-01,1000,12345,12.3,12.5,12.1,12.45,0.15,10\n" +
+		"2015-07-02,2000,25000,12.45,12.6,12.4,12.5,0.05,20\n"
+	if buf.String() != want {
+		t.Error(buf.String())
+	}
+}
+
+func TestWriteJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	rows := map[string]twse.BaseMTSS{
+		"2618": {No: "2618", Name: "長榮航", MT: twse.TradingVolume{Buy: 1, Sell: 2, Total: 3}},
+		"1101": {No: "1101", Name: "台泥"},
+	}
+	if err := Write(&buf, JSONL, rows); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"no":"1101","name":"台泥","mt_buy":0,"mt_sell":0,"mt_total":0,"ss_buy":0,"ss_sell":0,"ss_total":0}` + "\n" +
+		`{"no":"2618","name":"長榮航","mt_buy":1,"mt_sell":2,"mt_total":3,"ss_buy":0,"ss_sell":0,"ss_total":0}` + "\n"
+	if buf.String() != want {
+		t.Error(buf.String())
+	}
+
+	buf.Reset()
+	Write(&buf, JSONL, []struct{ Value float64 }{{math.NaN()}})
+	if buf.String() != "{\"value\":null}\n" {
+		t.Error(buf.String())
+	}
+}
+
+// thriftReader 讀取 Thrift compact protocol，struct 為 map[欄位編號]值
+type thriftReader struct {
+	*bytes.Reader
+}
+
+func (r thriftReader) zigzag() int64 {
+	v, _ := binary.ReadUvarint(r)
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case 1:
+		return true
+	case 2:
+		return false
+	case 5, 6:
+		return r.zigzag()
+	case 8:
+		n, _ := binary.ReadUvarint(r)
+		b := make([]byte, n)
+		r.Read(b)
+		return string(b)
+	case 9:
+		h, _ := r.ReadByte()
+		size := int(h >> 4)
+		if size == 15 {
+			n, _ := binary.ReadUvarint(r)
+			size = int(n)
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(h & 0x0f)
+		}
+		return list
+	case 12:
+		return r.structure()
+	}
+	panic(typ)
+}
+
+func (r thriftReader) structure() map[int64]interface{} {
+	var (
+		result = make(map[int64]interface{})
+		last   int64
+	)
+	for {
+		h, _ := r.ReadByte()
+		if h == 0 {
+			return result
+		}
+		if delta := int64(h >> 4); delta > 0 {
+			last += delta
+		} else {
+			last = r.zigzag()
+		}
+		result[last] = r.value(h & 0x0f)
+	}
+}
+
+func TestWriteParquet(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Parquet, sampleData); err != nil {
+		t.Fatal(err)
+	}
+	file := buf.Bytes()
+	if string(file[:4]) != "PAR1" || string(file[len(file)-4:]) != "PAR1" {
+		t.Fatal("Wrong magic")
+	}
+	size := binary.LittleEndian.Uint32(file[len(file)-8:])
+	meta := thriftReader{bytes.NewReader(file[len(file)-8-int(size) : len(file)-8])}.structure()
+
+	if meta[3].(int64) != 2 {
+		t.Error("num_rows", meta[3])
+	}
+	schema := meta[2].([]interface{})
+	if len(schema) != 10 || schema[0].(map[int64]interface{})[5].(int64) != 9 {
+		t.Fatal("schema", schema)
+	}
+	date := schema[1].(map[int64]interface{})
+	if date[4] != "date" || date[1].(int64) != 1 || date[6].(int64) != 6 {
+		t.Error("date schema", date)
+	}
+
+	chunks := meta[4].([]interface{})[0].(map[int64]interface{})[1].([]interface{})
+	for i, want := range []interface{}{int32(16617), uint64(1000), uint64(12345), 12.3} {
+		var (
+			chunk  = chunks[i].(map[int64]interface{})[3].(map[int64]interface{})
+			r      = bytes.NewReader(file[chunk[9].(int64):])
+			header = thriftReader{r}.structure()
+		)
+		if header[5].(map[int64]interface{})[1].(int64) != 2 {
+			t.Error("num_values", header)
+		}
+		switch want := want.(type) {
+		case int32:
+			var v [2]int32
+			binary.Read(r, binary.LittleEndian, &v)
+			if v[0] != want || v[1] != want+1 {
+				t.Error(i, v)
+			}
+		case uint64:
+			var v uint64
+			binary.Read(r, binary.LittleEndian, &v)
+			if v != want {
+				t.Error(i, v)
+			}
+		case float64:
+			var v float64
+			binary.Read(r, binary.LittleEndian, &v)
+			if v != want {
+				t.Error(i, v)
+			}
+		}
+	}
+}
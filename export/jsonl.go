@@ -0,0 +1,44 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"math"
+)
+
+// writeJSONL 每一列一個 JSON 物件，欄位依 struct 順序，NaN 與 Inf 為 null
+func (t *table) writeJSONL(w io.Writer) error {
+	var bw = bufio.NewWriter(w)
+	for _, row := range t.rows {
+		bw.WriteByte('{')
+		for i, c := range t.columns {
+			if i > 0 {
+				bw.WriteByte(',')
+			}
+			name, _ := json.Marshal(c.name)
+			bw.Write(name)
+			bw.WriteByte(':')
+
+			v := c.value(row)
+			switch c.kind {
+			case kindString, kindDate:
+				s, err := json.Marshal(formatValue(c.kind, v))
+				if err != nil {
+					return err
+				}
+				bw.Write(s)
+			case kindFloat:
+				if math.IsNaN(v.Float()) || math.IsInf(v.Float(), 0) {
+					bw.WriteString("null")
+					continue
+				}
+				fallthrough
+			default:
+				bw.WriteString(formatValue(c.kind, v))
+			}
+		}
+		bw.WriteString("}\n")
+	}
+	return bw.Flush()
+}
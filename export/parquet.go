@@ -0,0 +1,269 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// Parquet 檔案僅使用最基本的格式：單一 row group、每欄一個 PLAIN 編碼未壓縮的 data page、
+// 欄位皆為 REQUIRED，日期為 DATE（1970-01-01 起的天數）、字串為 UTF8。
+//
+// 格式說明：https://github.com/apache/parquet-format
+
+// parquet.thrift 使用的列舉值
+const (
+	parquetBoolean   = 0
+	parquetInt32     = 1
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetRequired = 0
+
+	convertedUTF8   = 0
+	convertedDate   = 6
+	convertedUint64 = 14
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecUncompressed = 0
+	pageData          = 0
+)
+
+var parquetMagic = []byte("PAR1")
+
+// physical 欄位的 parquet 型態與 converted type（-1 為無）
+func (k kind) physical() (int32, int32) {
+	switch k {
+	case kindBool:
+		return parquetBoolean, -1
+	case kindInt:
+		return parquetInt64, -1
+	case kindUint:
+		return parquetInt64, convertedUint64
+	case kindFloat:
+		return parquetDouble, -1
+	case kindDate:
+		return parquetInt32, convertedDate
+	}
+	return parquetByteArray, convertedUTF8
+}
+
+// writeParquet 依欄位寫入 data page，最後寫入 FileMetaData
+func (t *table) writeParquet(w io.Writer) error {
+	var (
+		buf    = bytes.NewBuffer(append([]byte(nil), parquetMagic...))
+		chunks = make([]columnChunk, len(t.columns))
+		total  int64
+	)
+	for i, c := range t.columns {
+		var (
+			values = t.plain(c)
+			header thriftWriter
+		)
+		header.pageHeader(len(t.rows), len(values))
+		chunks[i] = columnChunk{
+			offset: int64(buf.Len()),
+			size:   int64(header.Len() + len(values)),
+		}
+		total += chunks[i].size
+		buf.Write(header.Bytes())
+		buf.Write(values)
+	}
+
+	var meta thriftWriter
+	meta.fileMetaData(t, chunks, total)
+	buf.Write(meta.Bytes())
+	binary.Write(buf, binary.LittleEndian, uint32(meta.Len()))
+	buf.Write(parquetMagic)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// plain 以 PLAIN 編碼某一欄所有的值
+func (t *table) plain(c column) []byte {
+	var buf bytes.Buffer
+	switch c.kind {
+	case kindBool:
+		packed := make([]byte, (len(t.rows)+7)/8)
+		for i, row := range t.rows {
+			if c.value(row).Bool() {
+				packed[i/8] |= 1 << uint(i%8)
+			}
+		}
+		buf.Write(packed)
+	default:
+		for _, row := range t.rows {
+			v := c.value(row)
+			switch c.kind {
+			case kindInt:
+				binary.Write(&buf, binary.LittleEndian, v.Int())
+			case kindUint:
+				binary.Write(&buf, binary.LittleEndian, v.Uint())
+			case kindFloat:
+				binary.Write(&buf, binary.LittleEndian, math.Float64bits(v.Float()))
+			case kindDate:
+				binary.Write(&buf, binary.LittleEndian, int32(date(v).Unix()/86400))
+			default:
+				binary.Write(&buf, binary.LittleEndian, uint32(len(v.String())))
+				buf.WriteString(v.String())
+			}
+		}
+	}
+	return buf.Bytes()
+}
+
+// columnChunk 欄位在檔案中的位置與大小（含 page header）
+type columnChunk struct {
+	offset int64
+	size   int64
+}
+
+// thriftWriter Thrift compact protocol
+type thriftWriter struct {
+	bytes.Buffer
+	last  int16
+	stack []int16
+}
+
+// compact protocol 型態
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+func (w *thriftWriter) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	w.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func (w *thriftWriter) zigzag(v int64) {
+	w.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (w *thriftWriter) field(id int16, typ byte) {
+	if delta := id - w.last; delta > 0 && delta <= 15 {
+		w.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.WriteByte(typ)
+		w.zigzag(int64(id))
+	}
+	w.last = id
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(id, thriftI32)
+	w.zigzag(int64(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.zigzag(v)
+}
+
+func (w *thriftWriter) str(id int16, v string) {
+	w.field(id, thriftBinary)
+	w.varint(uint64(len(v)))
+	w.WriteString(v)
+}
+
+func (w *thriftWriter) list(id int16, typ byte, size int) {
+	w.field(id, thriftList)
+	if size < 15 {
+		w.WriteByte(byte(size)<<4 | typ)
+	} else {
+		w.WriteByte(0xf0 | typ)
+		w.varint(uint64(size))
+	}
+}
+
+// begin 開始巢狀 struct，id 為 0 時為 list 中的元素
+func (w *thriftWriter) begin(id int16) {
+	if id > 0 {
+		w.field(id, thriftStruct)
+	}
+	w.stack = append(w.stack, w.last)
+	w.last = 0
+}
+
+func (w *thriftWriter) end() {
+	w.WriteByte(0)
+	w.last = w.stack[len(w.stack)-1]
+	w.stack = w.stack[:len(w.stack)-1]
+}
+
+// pageHeader PageHeader 與 DataPageHeader
+func (w *thriftWriter) pageHeader(rows, size int) {
+	w.begin(0)
+	w.i32(1, pageData)
+	w.i32(2, int32(size))
+	w.i32(3, int32(size))
+	w.begin(5)
+	w.i32(1, int32(rows))
+	w.i32(2, encodingPlain)
+	w.i32(3, encodingRLE)
+	w.i32(4, encodingRLE)
+	w.end()
+	w.end()
+}
+
+// fileMetaData FileMetaData，包含 schema 與單一 row group
+func (w *thriftWriter) fileMetaData(t *table, chunks []columnChunk, total int64) {
+	w.begin(0)
+	w.i32(1, 1)
+
+	w.list(2, thriftStruct, len(t.columns)+1)
+	w.begin(0)
+	w.str(4, "schema")
+	w.i32(5, int32(len(t.columns)))
+	w.end()
+	for _, c := range t.columns {
+		typ, converted := c.kind.physical()
+		w.begin(0)
+		w.i32(1, typ)
+		w.i32(3, parquetRequired)
+		w.str(4, c.name)
+		if converted >= 0 {
+			w.i32(6, converted)
+		}
+		w.end()
+	}
+
+	w.i64(3, int64(len(t.rows)))
+
+	w.list(4, thriftStruct, 1)
+	w.begin(0)
+	w.list(1, thriftStruct, len(t.columns))
+	for i, c := range t.columns {
+		typ, _ := c.kind.physical()
+		w.begin(0)
+		w.i64(2, chunks[i].offset)
+		w.begin(3)
+		w.i32(1, typ)
+		w.list(2, thriftI32, 1)
+		w.zigzag(encodingPlain)
+		w.list(3, thriftBinary, 1)
+		w.varint(uint64(len(c.name)))
+		w.WriteString(c.name)
+		w.i32(4, codecUncompressed)
+		w.i64(5, int64(len(t.rows)))
+		w.i64(6, chunks[i].size)
+		w.i64(7, chunks[i].size)
+		w.i64(9, chunks[i].offset)
+		w.end()
+		w.end()
+	}
+	w.i64(2, total)
+	w.i64(3, int64(len(t.rows)))
+	w.end()
+
+	w.str(6, "gogrs")
+	w.end()
+}
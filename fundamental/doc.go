@@ -0,0 +1,5 @@
+// Package fundamental - 財務報表
+// 擷取公開資訊觀測站上市/上櫃公司的季度綜合損益表與資產負債表彙總，
+// 計算單季與近四季（TTM）每股盈餘、ROE、毛利率與負債比率，股票代碼與 twse.Lists 相同。
+//
+package fundamental
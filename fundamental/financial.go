@@ -0,0 +1,203 @@
+package fundamental
+
+import (
+	"math"
+	"sort"
+
+	"github.com/DoubleChuang/gogrs/twse"
+	"github.com/pkg/errors"
+)
+
+// Financial 公司單季財務指標，金額單位為千元，比率為百分比
+//
+// 綜合損益表為當年度累計的數值，單季數值為與上一季累計數的差；
+// 近四季（TTM）的數值需要連續四季的資料，不足時為 0。
+type Financial struct {
+	Quarter
+	No          string  `json:"no"`
+	Name        string  `json:"name"`
+	Revenue     int64   `json:"revenue"`      // 單季營業收入
+	GrossProfit int64   `json:"gross_profit"` // 單季營業毛利
+	NetIncome   int64   `json:"net_income"`   // 單季淨利歸屬於母公司業主，無此欄位時為本期淨利
+	EPS         float64 `json:"eps"`          // 單季每股盈餘
+	EPSTTM      float64 `json:"eps_ttm"`      // 近四季每股盈餘
+	ROE         float64 `json:"roe"`          // 近四季淨利除以期末母公司業主權益
+	GrossMargin float64 `json:"gross_margin"` // 單季毛利率
+	DebtRatio   float64 `json:"debt_ratio"`   // 負債比率
+	BookValue   float64 `json:"book_value"`   // 每股參考淨值
+}
+
+// StockInfo 回傳股票代碼與名稱
+func (f Financial) StockInfo() twse.StockInfo {
+	return twse.StockInfo{No: f.No, Name: f.Name}
+}
+
+// History 多季的財務報表彙總，以股票代碼取得依季別排序的財務指標
+type History struct {
+	Income   map[Quarter]map[string]IncomeStatement
+	Balance  map[Quarter]map[string]BalanceSheet
+	exchange string
+	client   *twse.Client
+	fetcher  twse.Fetcher
+}
+
+// NewHistory 建立上市公司的財務報表歷史資料
+func NewHistory() *History {
+	return newHistory("tse")
+}
+
+// NewOTCHistory 建立上櫃公司的財務報表歷史資料
+func NewOTCHistory() *History {
+	return newHistory("otc")
+}
+
+func newHistory(exchange string) *History {
+	return &History{
+		Income:   make(map[Quarter]map[string]IncomeStatement),
+		Balance:  make(map[Quarter]map[string]BalanceSheet),
+		exchange: exchange,
+	}
+}
+
+// SetClient 使用 twse.Client 的公開資訊觀測站主機與 Fetcher
+func (h *History) SetClient(c *twse.Client) *History {
+	h.client = c
+	return h
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (h *History) SetFetcher(f twse.Fetcher) *History {
+	h.fetcher = f
+	return h
+}
+
+// LoadRange 擷取 from 到 to（含）每一季的綜合損益表與資產負債表彙總，
+// 已擷取的季別不會重複擷取，尚未公布的季別略過
+//
+// 計算 from 當季的 TTM 需要前三季的資料，可將 from 提前三季。
+func (h *History) LoadRange(from, to Quarter) error {
+	for q := from; !to.Before(q); q = q.Next() {
+		if _, ok := h.Income[q]; ok {
+			continue
+		}
+		s := newSummary(h.exchange, q)
+		if h.client != nil {
+			s.SetClient(h.client)
+		}
+		if h.fetcher != nil {
+			s.SetFetcher(h.fetcher)
+		}
+		income, err := s.GetIncome()
+		if errors.Cause(err) == ErrNoData {
+			continue
+		} else if err != nil {
+			return err
+		}
+		balance, err := s.GetBalance()
+		if err != nil && errors.Cause(err) != ErrNoData {
+			return err
+		}
+		h.Income[q] = income
+		h.Balance[q] = balance
+	}
+	return nil
+}
+
+// Quarters 已擷取的季別，依季別排序
+func (h History) Quarters() []Quarter {
+	var list = make([]Quarter, 0, len(h.Income))
+	for q := range h.Income {
+		list = append(list, q)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Before(list[j]) })
+	return list
+}
+
+// single 單季數值，第一季為累計數，其他季為與上一季累計數的差；缺少上一季時回傳 false
+func (h History) single(no string, q Quarter) (IncomeStatement, bool) {
+	v, ok := h.Income[q][no]
+	if !ok || q.Season == 1 {
+		return v, ok
+	}
+	prev, ok := h.Income[q.Prev()][no]
+	if !ok {
+		return v, false
+	}
+	v.Revenue -= prev.Revenue
+	v.GrossProfit -= prev.GrossProfit
+	v.OperatingIncome -= prev.OperatingIncome
+	v.NetIncome -= prev.NetIncome
+	v.NetIncomeParent -= prev.NetIncomeParent
+	v.EPS -= prev.EPS
+	return v, true
+}
+
+// netIncome 淨利歸屬於母公司業主，無此欄位時為本期淨利
+func netIncome(v IncomeStatement) int64 {
+	if v.NetIncomeParent != 0 {
+		return v.NetIncomeParent
+	}
+	return v.NetIncome
+}
+
+// ratio 計算百分比，取到小數第二位；分母為 0 時為 0
+func ratio(value, base float64) float64 {
+	if base == 0 {
+		return 0
+	}
+	return math.Round(value/base*10000) / 100
+}
+
+// Stock 回傳個股依季別排序的財務指標，缺少上一季累計數的季別不會回傳
+func (h History) Stock(no string) []Financial {
+	var result []Financial
+	for _, q := range h.Quarters() {
+		v, ok := h.single(no, q)
+		if !ok {
+			continue
+		}
+		var f = Financial{
+			Quarter:     q,
+			No:          no,
+			Name:        v.Name,
+			Revenue:     v.Revenue,
+			GrossProfit: v.GrossProfit,
+			NetIncome:   netIncome(v),
+			EPS:         math.Round(v.EPS*100) / 100,
+		}
+		if v.GrossProfit != 0 {
+			f.GrossMargin = ratio(float64(v.GrossProfit), float64(v.Revenue))
+		}
+
+		var (
+			eps, income float64
+			count       int
+		)
+		for i, p := 0, q; i < 4; i, p = i+1, p.Prev() {
+			s, ok := h.single(no, p)
+			if !ok {
+				break
+			}
+			eps += s.EPS
+			income += float64(netIncome(s))
+			count++
+		}
+
+		if b, ok := h.Balance[q][no]; ok {
+			f.DebtRatio = ratio(float64(b.TotalLiabilities), float64(b.TotalAssets))
+			f.BookValue = b.BookValue
+			if count == 4 {
+				var equity = b.EquityParent
+				if equity == 0 {
+					equity = b.Equity
+				}
+				f.ROE = ratio(income, float64(equity))
+			}
+		}
+		if count == 4 {
+			f.EPSTTM = math.Round(eps*100) / 100
+		}
+		result = append(result, f)
+	}
+	return result
+}
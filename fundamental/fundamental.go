@@ -0,0 +1,262 @@
+package fundamental
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/DoubleChuang/gogrs/twse"
+	"github.com/DoubleChuang/gogrs/utils"
+	"github.com/pkg/errors"
+)
+
+// ErrNoData 查詢無資料，如該季財報尚未公布
+var ErrNoData = errors.New("fundamental: no data")
+
+// Quarter 年度（西元年）與季別
+type Quarter struct {
+	Year   int `json:"year"`
+	Season int `json:"season"` // 1~4
+}
+
+// Prev 上一季
+func (q Quarter) Prev() Quarter {
+	if q.Season <= 1 {
+		return Quarter{Year: q.Year - 1, Season: 4}
+	}
+	return Quarter{Year: q.Year, Season: q.Season - 1}
+}
+
+// Next 下一季
+func (q Quarter) Next() Quarter {
+	if q.Season >= 4 {
+		return Quarter{Year: q.Year + 1, Season: 1}
+	}
+	return Quarter{Year: q.Year, Season: q.Season + 1}
+}
+
+// Before 是否早於 other
+func (q Quarter) Before(other Quarter) bool {
+	return q.Year < other.Year || (q.Year == other.Year && q.Season < other.Season)
+}
+
+func (q Quarter) String() string {
+	return fmt.Sprintf("%dQ%d", q.Year, q.Season)
+}
+
+// IncomeStatement 綜合損益表彙總，金額單位為千元，為當年度累計至該季的數值
+type IncomeStatement struct {
+	Quarter
+	No              string  `json:"no"`
+	Name            string  `json:"name"`
+	Revenue         int64   `json:"revenue"`           // 營業收入，金融業為淨收益
+	GrossProfit     int64   `json:"gross_profit"`      // 營業毛利（毛損），金融業無此欄位
+	OperatingIncome int64   `json:"operating_income"`  // 營業利益（損失）
+	NetIncome       int64   `json:"net_income"`        // 本期淨利（淨損）
+	NetIncomeParent int64   `json:"net_income_parent"` // 淨利（淨損）歸屬於母公司業主
+	EPS             float64 `json:"eps"`               // 基本每股盈餘（元）
+}
+
+// BalanceSheet 資產負債表彙總，金額單位為千元
+type BalanceSheet struct {
+	Quarter
+	No               string  `json:"no"`
+	Name             string  `json:"name"`
+	TotalAssets      int64   `json:"total_assets"`      // 資產總計
+	TotalLiabilities int64   `json:"total_liabilities"` // 負債總計
+	Equity           int64   `json:"equity"`            // 權益總計
+	EquityParent     int64   `json:"equity_parent"`     // 歸屬於母公司業主之權益合計
+	BookValue        float64 `json:"book_value"`        // 每股參考淨值
+}
+
+// incomeColumns 綜合損益表彙總（t163sb04）各產業表格的欄位
+var incomeColumns = []twse.Column{
+	twse.Col("No", "公司代號"),
+	twse.Col("Name", "公司名稱"),
+	twse.Col("EPS", "基本每股盈餘（元）", "基本每股盈餘(元)"),
+	twse.OptCol("Revenue", "營業收入", "淨收益", "收入", "收益"),
+	twse.OptCol("GrossProfit", "營業毛利（毛損）", "營業毛利（毛損）淨額", "營業毛利(毛損)"),
+	twse.OptCol("OperatingIncome", "營業利益（損失）", "營業利益(損失)"),
+	twse.OptCol("NetIncome", "本期淨利（淨損）", "本期稅後淨利（淨損）", "本期淨利(淨損)"),
+	twse.OptCol("NetIncomeParent", "淨利（淨損）歸屬於母公司業主", "淨利(淨損)歸屬於母公司業主"),
+}
+
+// balanceColumns 資產負債表彙總（t163sb05）各產業表格的欄位
+var balanceColumns = []twse.Column{
+	twse.Col("No", "公司代號"),
+	twse.Col("Name", "公司名稱"),
+	twse.Col("TotalAssets", "資產總計", "資產總額"),
+	twse.Col("TotalLiabilities", "負債總計", "負債總額"),
+	twse.OptCol("Equity", "權益總計", "權益總額"),
+	twse.OptCol("EquityParent", "歸屬於母公司業主之權益合計", "歸屬於母公司業主之權益", "歸屬於母公司業主權益合計"),
+	twse.OptCol("BookValue", "每股參考淨值"),
+}
+
+// Summary 上市或上櫃公司某一季的綜合損益表與資產負債表彙總
+type Summary struct {
+	Quarter
+	Income   map[string]IncomeStatement
+	Balance  map[string]BalanceSheet
+	exchange string
+	host     string
+	fetcher  twse.Fetcher
+}
+
+// NewSummary 建立上市公司某一季的財務報表彙總，year 為西元年
+func NewSummary(year, season int) *Summary {
+	return newSummary("tse", Quarter{Year: year, Season: season})
+}
+
+// NewOTCSummary 建立上櫃公司某一季的財務報表彙總，year 為西元年
+func NewOTCSummary(year, season int) *Summary {
+	return newSummary("otc", Quarter{Year: year, Season: season})
+}
+
+func newSummary(exchange string, q Quarter) *Summary {
+	return &Summary{
+		Quarter:  q,
+		Income:   make(map[string]IncomeStatement),
+		Balance:  make(map[string]BalanceSheet),
+		exchange: exchange,
+		host:     utils.MOPSHOST,
+	}
+}
+
+// SetClient 使用 twse.Client 的公開資訊觀測站主機與 Fetcher
+func (s *Summary) SetClient(c *twse.Client) *Summary {
+	s.host = c.MOPSHost()
+	s.fetcher = c.UTF8Fetcher()
+	return s
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (s *Summary) SetFetcher(f twse.Fetcher) *Summary {
+	s.fetcher = f
+	return s
+}
+
+// form 查詢的表單
+func (s Summary) form() url.Values {
+	var typek = map[string]string{"tse": "sii", "otc": "otc"}[s.exchange]
+	return url.Values{
+		"encodeURIComponent": {"1"},
+		"step":               {"1"},
+		"firstin":            {"1"},
+		"off":                {"1"},
+		"isQuery":            {"Y"},
+		"TYPEK":              {typek},
+		"year":               {strconv.Itoa(s.Year - 1911)},
+		"season":             {fmt.Sprintf("%02d", s.Season)},
+	}
+}
+
+// fetch 擷取 path 的報表並找出符合 columns 的資料列
+func (s *Summary) fetch(path string, columns []twse.Column) ([]twse.Record, error) {
+	if !utils.ExchangeMap[s.exchange] {
+		return nil, errors.Errorf("fundamental: not support %s", s.exchange)
+	}
+	var f = s.fetcher
+	if f == nil {
+		f = twse.DefaultClient().UTF8Fetcher()
+	}
+	data, err := f.PostForm(s.host+path, s.form())
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetch %s %s", s.exchange, path)
+	}
+	records, err := twse.ParseRows(fmt.Sprintf("%s %s %s", s.exchange, path, s.Quarter), columns, readRows(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.WithMessage(ErrNoData, fmt.Sprintf("%s %s %s", s.exchange, path, s.Quarter))
+	}
+	return records, nil
+}
+
+// GetIncome 擷取綜合損益表彙總
+func (s *Summary) GetIncome() (map[string]IncomeStatement, error) {
+	records, err := s.fetch(utils.MOPSINCOME, incomeColumns)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		var (
+			v = IncomeStatement{Quarter: s.Quarter, No: r.Get("No"), Name: r.Get("Name")}
+			p = numberParser{dataset: fmt.Sprintf("%s income %s %s", s.exchange, s.Quarter, v.No)}
+		)
+		v.Revenue = p.int("Revenue", r.Get("Revenue"))
+		v.GrossProfit = p.int("GrossProfit", r.Get("GrossProfit"))
+		v.OperatingIncome = p.int("OperatingIncome", r.Get("OperatingIncome"))
+		v.NetIncome = p.int("NetIncome", r.Get("NetIncome"))
+		v.NetIncomeParent = p.int("NetIncomeParent", r.Get("NetIncomeParent"))
+		v.EPS = p.float("EPS", r.Get("EPS"))
+		if p.err != nil {
+			return nil, p.err
+		}
+		s.Income[v.No] = v
+	}
+	return s.Income, nil
+}
+
+// GetBalance 擷取資產負債表彙總
+func (s *Summary) GetBalance() (map[string]BalanceSheet, error) {
+	records, err := s.fetch(utils.MOPSBALANCE, balanceColumns)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		var (
+			v = BalanceSheet{Quarter: s.Quarter, No: r.Get("No"), Name: r.Get("Name")}
+			p = numberParser{dataset: fmt.Sprintf("%s balance %s %s", s.exchange, s.Quarter, v.No)}
+		)
+		v.TotalAssets = p.int("TotalAssets", r.Get("TotalAssets"))
+		v.TotalLiabilities = p.int("TotalLiabilities", r.Get("TotalLiabilities"))
+		v.Equity = p.int("Equity", r.Get("Equity"))
+		v.EquityParent = p.int("EquityParent", r.Get("EquityParent"))
+		v.BookValue = p.float("BookValue", r.Get("BookValue"))
+		if p.err != nil {
+			return nil, p.err
+		}
+		s.Balance[v.No] = v
+	}
+	return s.Balance, nil
+}
+
+// numberParser 解析數值欄位，無數值（空白、--）時為 0，記錄第一個解析錯誤
+type numberParser struct {
+	dataset string
+	err     error
+}
+
+func (p *numberParser) clean(raw string) (string, bool) {
+	s := strings.Replace(strings.TrimSpace(raw), ",", "", -1)
+	if s == "" || strings.Trim(s, "-") == "" {
+		return "", false
+	}
+	return s, true
+}
+
+func (p *numberParser) int(column, raw string) int64 {
+	s, ok := p.clean(raw)
+	if !ok {
+		return 0
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil && p.err == nil {
+		p.err = errors.Wrapf(err, "%s: %s", p.dataset, column)
+	}
+	return v
+}
+
+func (p *numberParser) float(column, raw string) float64 {
+	s, ok := p.clean(raw)
+	if !ok {
+		return 0
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil && p.err == nil {
+		p.err = errors.Wrapf(err, "%s: %s", p.dataset, column)
+	}
+	return v
+}
@@ -0,0 +1,119 @@
+package fundamental
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/DoubleChuang/gogrs/twse"
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+// formFetcher 依網址與查詢的年度、季別回傳固定內容
+type formFetcher map[string]string
+
+func (f formFetcher) key(url string, year, season string) string {
+	return fmt.Sprintf("%s %s/%s", url, year, season)
+}
+
+func (f formFetcher) Get(url string, rand bool) ([]byte, error) {
+	return nil, fmt.Errorf("unexpected get %s", url)
+}
+
+func (f formFetcher) PostForm(url string, data url.Values) ([]byte, error) {
+	if v, ok := f[f.key(url, data.Get("year"), data.Get("season"))]; ok {
+		return []byte(v), nil
+	}
+	return []byte(`<html><body><center><h3>查詢無資料!</h3></center></body></html>`), nil
+}
+
+// table 建立 MOPS 彙總報表的表格
+func table(header string, rows ...string) string {
+	var s = "<table class='hasBorder'><tr class='tblHead'>"
+	for _, v := range strings.Split(header, ",") {
+		s += "<th>" + v + "</th>"
+	}
+	s += "</tr>"
+	for _, row := range rows {
+		s += "<tr class='even'>"
+		for _, v := range strings.Split(row, "|") {
+			s += "<td style='text-align:right !important;'>" + v + "</td>"
+		}
+		s += "</tr>"
+	}
+	return s + "</table>"
+}
+
+const incomeHeader = "公司<br>代號,公司名稱,營業收入,營業成本,營業毛利（毛損）,營業利益（損失）,本期淨利（淨損）,淨利（淨損）歸屬於母公司業主,基本每股盈餘（元）"
+
+func TestSummary_GetIncome(t *testing.T) {
+	var (
+		income = utils.MOPSHOST + utils.MOPSINCOME
+		f      = formFetcher{}
+	)
+	f[f.key(income, "106", "01")] = "<html><body><table><tr><td>" +
+		table(incomeHeader, "1101|台泥|23,386,574|19,000,000|4,386,574|3,000,000|2,500,000|2,450,000|0.66") +
+		"<br>" + table("公司<br>代號,公司名稱,利息淨收益,淨收益,本期稅後淨利（淨損）,淨利（淨損）歸屬於母公司業主,基本每股盈餘（元）",
+		"2801|彰銀|5,000,000|6,800,000|3,100,000|3,100,000|&nbsp;0.35") +
+		"</td></tr></table></body></html>"
+
+	s := NewSummary(2017, 1).SetFetcher(f)
+	data, err := s.GetIncome()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := data["1101"]; len(data) != 2 || v.Name != "台泥" || v.Revenue != 23386574 || v.GrossProfit != 4386574 || v.EPS != 0.66 {
+		t.Fatalf("Wrong data: %+v", data)
+	}
+	if v := data["2801"]; v.Revenue != 6800000 || v.NetIncome != 3100000 || v.GrossProfit != 0 || v.EPS != 0.35 || v.Quarter != (Quarter{2017, 1}) {
+		t.Errorf("Wrong data: %+v", v)
+	}
+
+	if _, err := NewSummary(2017, 2).SetFetcher(f).GetIncome(); err == nil || !strings.Contains(err.Error(), ErrNoData.Error()) {
+		t.Errorf("Should be ErrNoData but %v", err)
+	}
+
+	// 表頭改變時回傳 *twse.SchemaError 而非 ErrNoData
+	f[f.key(income, "106", "03")] = table("公司<br>代號,公司名稱,營業收入,每股盈餘", "1101|台泥|23,386,574|0.66")
+	if _, err := NewSummary(2017, 3).SetFetcher(f).GetIncome(); err == nil {
+		t.Error("Should be error")
+	} else if _, ok := err.(*twse.SchemaError); !ok {
+		t.Errorf("Should be *twse.SchemaError but %v", err)
+	}
+}
+
+func TestHistory_Stock(t *testing.T) {
+	var (
+		income  = utils.MOPSHOST + utils.MOPSINCOME
+		balance = utils.MOPSHOST + utils.MOPSBALANCE
+		f       = formFetcher{}
+	)
+	for _, v := range []struct {
+		year, season string
+		row          string
+	}{
+		{"105", "01", "2618|長榮航|800|600|200|150|100|100|1.00"},
+		{"105", "02", "2618|長榮航|1,700|1,300|400|300|260|250|2.50"},
+		{"105", "03", "2618|長榮航|2,500|1,950|550|350|310|300|3.00"},
+		{"105", "04", "2618|長榮航|3,400|2,650|750|500|430|420|4.20"},
+		{"106", "01", "2618|長榮航|1,000|750|250|150|115|110|1.10"},
+	} {
+		f[f.key(income, v.year, v.season)] = table(incomeHeader, v.row)
+	}
+	f[f.key(balance, "106", "01")] = table("公司<br>代號,公司名稱,流動資產,資產總計,負債總計,歸屬於母公司業主之權益合計,權益總計,每股參考淨值",
+		"2618|長榮航|1,000|6,300|2,000|4,300|4,300|10.50")
+
+	h := NewHistory().SetFetcher(f)
+	if err := h.LoadRange(Quarter{2016, 1}, Quarter{2017, 2}); err != nil {
+		t.Fatal(err)
+	}
+	data := h.Stock("2618")
+	if len(data) != 5 || data[1].Revenue != 900 || data[1].EPS != 1.5 || data[0].EPSTTM != 0 {
+		t.Fatalf("Wrong data: %+v", data)
+	}
+	if v := data[4]; v.Quarter != (Quarter{2017, 1}) || v.EPSTTM != 4.3 || v.ROE != 10 || v.GrossMargin != 25 ||
+		v.DebtRatio != 31.75 || v.BookValue != 10.5 || v.StockInfo().Name != "長榮航" {
+		t.Errorf("Wrong data: %+v", v)
+	}
+}
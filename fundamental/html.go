@@ -0,0 +1,41 @@
+package fundamental
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	rowReg  = regexp.MustCompile(`(?is)<tr[^>]*>((?:[^<]|<[^t]|<t[^r])*?)</tr>`) // 不含其他 <tr 的最內層列
+	cellReg = regexp.MustCompile(`(?is)<t([hd])[^>]*>(.*?)</t[hd]>`)
+	tagReg  = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// readRows 依序讀取 HTML 中所有表格的列，欄位去除標籤並轉換字元參照
+//
+// 各產業的表格欄位不同，表頭（<th>）之前加入空白列結束前一個表格，
+// 再交由 twse.ParseRows 比對每個表頭的欄位位置。
+func readRows(data []byte) [][]string {
+	var rows [][]string
+	for _, tr := range rowReg.FindAllStringSubmatch(string(data), -1) {
+		var (
+			cells  []string
+			header bool
+		)
+		for _, cell := range cellReg.FindAllStringSubmatch(tr[1], -1) {
+			if strings.EqualFold(cell[1], "h") {
+				header = true
+			}
+			cells = append(cells, strings.TrimSpace(html.UnescapeString(tagReg.ReplaceAllString(cell[2], ""))))
+		}
+		if len(cells) == 0 {
+			continue
+		}
+		if header {
+			rows = append(rows, nil)
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
@@ -0,0 +1,187 @@
+package indicator
+
+import (
+	"math"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/twse"
+)
+
+// PatternName K 線型態名稱
+type PatternName string
+
+// K 線型態
+const (
+	Doji               PatternName = "十字線"
+	Hammer             PatternName = "錘子線"
+	ShootingStar       PatternName = "流星線"
+	BullishEngulfing   PatternName = "多頭吞噬"
+	BearishEngulfing   PatternName = "空頭吞噬"
+	BullishHarami      PatternName = "多頭母子"
+	BearishHarami      PatternName = "空頭母子"
+	MorningStar        PatternName = "晨星"
+	EveningStar        PatternName = "夜星"
+	ThreeWhiteSoldiers PatternName = "紅三兵"
+	GapUp              PatternName = "跳空上漲"
+	GapDown            PatternName = "跳空下跌"
+)
+
+// Pattern 符合的 K 線型態
+type Pattern struct {
+	Date     time.Time // 型態最後一根 K 線的日期
+	Name     PatternName
+	Bars     int     // 組成型態的 K 線數
+	Trend    int     // 1 偏多、-1 偏空、0 中性
+	Strength float64 // 0 ~ 1，越大越明顯
+}
+
+// candle K 線的實體與影線
+type candle struct {
+	twse.FmtData
+}
+
+func (c candle) body() float64 {
+	return math.Abs(c.Price - c.Open)
+}
+
+func (c candle) span() float64 {
+	return c.High - c.Low
+}
+
+func (c candle) upper() float64 {
+	return c.High - math.Max(c.Open, c.Price)
+}
+
+func (c candle) lower() float64 {
+	return math.Min(c.Open, c.Price) - c.Low
+}
+
+func (c candle) isRed() bool {
+	return c.Price > c.Open
+}
+
+func (c candle) isBlack() bool {
+	return c.Price < c.Open
+}
+
+// isLong 實體佔全日振幅一半以上
+func (c candle) isLong() bool {
+	return c.span() > 0 && c.body() >= c.span()/2
+}
+
+// clamp 將數值限制在 0 ~ 1
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// Patterns 找出所有符合的單根、兩根與三根 K 線型態，依日期排序
+//
+// 錘子線須在下跌後出現、流星線須在上漲後出現（與三日前收盤價比較）。
+func Patterns(data []twse.FmtData) []Pattern {
+	data = clean(data)
+	var result []Pattern
+	for i := range data {
+		result = append(result, patternsAt(data, i)...)
+	}
+	return result
+}
+
+// LastPatterns 找出最後一根 K 線符合的型態
+func LastPatterns(data []twse.FmtData) []Pattern {
+	data = clean(data)
+	if len(data) == 0 {
+		return nil
+	}
+	return patternsAt(data, len(data)-1)
+}
+
+// HasPattern 判斷最後一根 K 線是否符合任一指定型態
+func HasPattern(data []twse.FmtData, names ...PatternName) bool {
+	for _, p := range LastPatterns(data) {
+		for _, name := range names {
+			if p.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func patternsAt(data []twse.FmtData, i int) []Pattern {
+	var (
+		result []Pattern
+		c      = candle{data[i]}
+		add    = func(name PatternName, bars, trend int, strength float64) {
+			result = append(result, Pattern{Date: c.Date, Name: name, Bars: bars, Trend: trend, Strength: clamp(strength)})
+		}
+	)
+
+	// 單根 K 線
+	if span := c.span(); span > 0 {
+		if c.body() <= span*0.1 {
+			add(Doji, 1, 0, 1-c.body()/(span*0.1))
+		}
+		if c.body() <= span/3 && c.body() > 0 {
+			if c.lower() >= 2*c.body() && c.upper() <= c.body() && i >= 3 && data[i-1].Price < data[i-3].Price {
+				add(Hammer, 1, 1, c.lower()/span)
+			}
+			if c.upper() >= 2*c.body() && c.lower() <= c.body() && i >= 3 && data[i-1].Price > data[i-3].Price {
+				add(ShootingStar, 1, -1, c.upper()/span)
+			}
+		}
+	}
+	if i < 1 {
+		return result
+	}
+
+	// 兩根 K 線，跳空幅度 10% 時強度為 1
+	var p = candle{data[i-1]}
+	if c.Low > p.High {
+		add(GapUp, 2, 1, (c.Low-p.High)/p.High*10)
+	}
+	if c.High < p.Low {
+		add(GapDown, 2, -1, (p.Low-c.High)/p.Low*10)
+	}
+	if p.body() > 0 && c.body() > p.body() {
+		if p.isBlack() && c.isRed() && c.Open <= p.Price && c.Price >= p.Open {
+			add(BullishEngulfing, 2, 1, 1-p.body()/c.body())
+		}
+		if p.isRed() && c.isBlack() && c.Open >= p.Price && c.Price <= p.Open {
+			add(BearishEngulfing, 2, -1, 1-p.body()/c.body())
+		}
+	}
+	if p.isLong() && c.body() < p.body() &&
+		math.Max(c.Open, c.Price) <= math.Max(p.Open, p.Price) &&
+		math.Min(c.Open, c.Price) >= math.Min(p.Open, p.Price) {
+		if p.isBlack() && !c.isBlack() {
+			add(BullishHarami, 2, 1, 1-c.body()/p.body())
+		}
+		if p.isRed() && !c.isRed() {
+			add(BearishHarami, 2, -1, 1-c.body()/p.body())
+		}
+	}
+	if i < 2 {
+		return result
+	}
+
+	// 三根 K 線
+	var (
+		f   = candle{data[i-2]}
+		mid = (f.Open + f.Price) / 2
+	)
+	if f.isLong() && p.body() <= f.body()*0.3 {
+		if f.isBlack() && c.isRed() && math.Max(p.Open, p.Price) < f.Price && c.Price > mid {
+			add(MorningStar, 3, 1, (c.Price-mid)/(f.Open-mid))
+		}
+		if f.isRed() && c.isBlack() && math.Min(p.Open, p.Price) > f.Price && c.Price < mid {
+			add(EveningStar, 3, -1, (mid-c.Price)/(mid-f.Open))
+		}
+	}
+	if f.isRed() && p.isRed() && c.isRed() &&
+		p.Price > f.Price && c.Price > p.Price &&
+		p.Open >= f.Open && p.Open <= f.Price && c.Open >= p.Open && c.Open <= p.Price &&
+		f.upper() <= f.span()*0.3 && p.upper() <= p.span()*0.3 && c.upper() <= c.span()*0.3 {
+		add(ThreeWhiteSoldiers, 3, 1, (f.body()/f.span()+p.body()/p.span()+c.body()/c.span())/3)
+	}
+	return result
+}
@@ -0,0 +1,79 @@
+package indicator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/twse"
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+// ohlc 依序建立 (開盤, 最高, 最低, 收盤) 的日資料
+func ohlc(bars ...[4]float64) []twse.FmtData {
+	var result = make([]twse.FmtData, len(bars))
+	for i, v := range bars {
+		result[i] = twse.FmtData{
+			Date:  time.Date(2015, 3, 2+i, 0, 0, 0, 0, utils.TaipeiTimeZone),
+			Open:  v[0],
+			High:  v[1],
+			Low:   v[2],
+			Price: v[3],
+		}
+	}
+	return result
+}
+
+func hasName(patterns []Pattern, name PatternName) bool {
+	for _, p := range patterns {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPatterns(t *testing.T) {
+	var cases = []struct {
+		name PatternName
+		data []twse.FmtData
+	}{
+		{Doji, ohlc([4]float64{10, 11, 9, 10.05})},
+		{Hammer, ohlc([4]float64{12, 12, 11, 11}, [4]float64{11, 11, 10, 10}, [4]float64{10, 10, 9, 9}, [4]float64{8.8, 9, 7, 9})},
+		{ShootingStar, ohlc([4]float64{8, 9, 8, 9}, [4]float64{9, 10, 9, 10}, [4]float64{10, 11, 10, 11}, [4]float64{11.2, 13, 11, 11})},
+		{BullishEngulfing, ohlc([4]float64{10, 10.2, 9.4, 9.5}, [4]float64{9.4, 10.5, 9.3, 10.3})},
+		{BearishEngulfing, ohlc([4]float64{9.5, 10.2, 9.4, 10}, [4]float64{10.1, 10.2, 9.2, 9.3})},
+		{BullishHarami, ohlc([4]float64{11, 11.1, 9.9, 10}, [4]float64{10.2, 10.6, 10.1, 10.5})},
+		{BearishHarami, ohlc([4]float64{10, 11.1, 9.9, 11}, [4]float64{10.8, 10.9, 10.3, 10.5})},
+		{MorningStar, ohlc([4]float64{12, 12.1, 10.9, 11}, [4]float64{10.6, 10.8, 10.4, 10.7}, [4]float64{10.8, 11.9, 10.7, 11.8})},
+		{EveningStar, ohlc([4]float64{11, 12.1, 10.9, 12}, [4]float64{12.4, 12.6, 12.2, 12.3}, [4]float64{12.2, 12.3, 11.1, 11.2})},
+		{ThreeWhiteSoldiers, ohlc([4]float64{10, 11.1, 9.9, 11}, [4]float64{10.5, 12.1, 10.4, 12}, [4]float64{11.5, 13.1, 11.4, 13})},
+		{GapUp, ohlc([4]float64{10, 10.5, 9.5, 10}, [4]float64{11, 11.5, 10.8, 11})},
+		{GapDown, ohlc([4]float64{10, 10.5, 9.5, 10}, [4]float64{9, 9.2, 8.5, 9})},
+	}
+	for _, c := range cases {
+		patterns := LastPatterns(c.data)
+		if !hasName(patterns, c.name) {
+			t.Errorf("Should be %s but %+v", c.name, patterns)
+			continue
+		}
+		if !HasPattern(c.data, c.name) {
+			t.Errorf("HasPattern should be true: %s", c.name)
+		}
+		for _, p := range patterns {
+			if p.Strength < 0 || p.Strength > 1 || !p.Date.Equal(c.data[len(c.data)-1].Date) {
+				t.Errorf("%s wrong: %+v", c.name, p)
+			}
+		}
+	}
+}
+
+func TestPatterns_trend(t *testing.T) {
+	// 上漲後的長下影線不是錘子線
+	var data = ohlc([4]float64{8, 9, 8, 9}, [4]float64{9, 10, 9, 10}, [4]float64{10, 11, 10, 11}, [4]float64{10.8, 11, 9, 11})
+	if HasPattern(data, Hammer) {
+		t.Error("Should not be Hammer")
+	}
+	if len(Patterns(data)) < len(LastPatterns(data)) {
+		t.Error("Patterns should contain LastPatterns")
+	}
+}
@@ -0,0 +1,5 @@
+// Package indicator - 技術指標
+// 由 twse.FmtData 序列（twse.Data.FormatData()、twse.Bars）計算 KD、RSI、MACD、布林通道、ATR、OBV、量比等技術指標、K 線型態與支撐壓力，
+// 結果皆與日期對齊。
+//
+package indicator
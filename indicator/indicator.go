@@ -0,0 +1,91 @@
+package indicator
+
+import (
+	"time"
+
+	"github.com/DoubleChuang/gogrs/twse"
+)
+
+// Series 與日期對齊的指標序列
+//
+// 資料不足以計算的前幾日不會出現在序列中，Date[i] 為 Value[i] 的日期。
+type Series struct {
+	Date  []time.Time
+	Value []float64
+}
+
+// Len 返回序列長度
+func (s Series) Len() int {
+	return len(s.Value)
+}
+
+// Last 返回最後一個數值，序列為空時回傳 0
+func (s Series) Last() float64 {
+	if len(s.Value) == 0 {
+		return 0
+	}
+	return s.Value[len(s.Value)-1]
+}
+
+// At 返回指定日期的數值
+func (s Series) At(date time.Time) (float64, bool) {
+	for i := len(s.Date) - 1; i >= 0; i-- {
+		if s.Date[i].Equal(date) {
+			return s.Value[i], true
+		}
+	}
+	return 0, false
+}
+
+// newSeries 由 dates 的第 start 筆開始建立序列
+func newSeries(dates []time.Time, values []float64, start int) Series {
+	if start < 0 || start > len(values) {
+		start = len(values)
+	}
+	return Series{Date: dates[start:], Value: values[start:]}
+}
+
+// clean 移除無法解析日期的資料
+func clean(data []twse.FmtData) []twse.FmtData {
+	var result = make([]twse.FmtData, 0, len(data))
+	for _, v := range data {
+		if !v.Date.IsZero() {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// dateList 取出日期序列
+func dateList(data []twse.FmtData) []time.Time {
+	var result = make([]time.Time, len(data))
+	for i, v := range data {
+		result[i] = v.Date
+	}
+	return result
+}
+
+// sma 計算 list[start:start+n] 的平均
+func sma(list []float64, start, n int) float64 {
+	var sum float64
+	for _, v := range list[start : start+n] {
+		sum += v
+	}
+	return sum / float64(n)
+}
+
+// ema 計算指數移動平均，以前 n 筆的平均為起始值，前 n-1 筆為 0
+func ema(list []float64, n int) []float64 {
+	var (
+		result = make([]float64, len(list))
+		alpha  = 2 / float64(n+1)
+	)
+	if n < 1 || len(list) < n {
+		return result
+	}
+	result[n-1] = sma(list, 0, n)
+	for i := n; i < len(list); i++ {
+		result[i] = result[i-1] + alpha*(list[i]-result[i-1])
+	}
+	return result
+}
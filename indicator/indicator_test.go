@@ -0,0 +1,154 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/twse"
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+// sample 依序建立 (最高, 最低, 收盤) 的日資料
+func sample(hlc ...[3]float64) []twse.FmtData {
+	var result = make([]twse.FmtData, len(hlc))
+	for i, v := range hlc {
+		result[i] = twse.FmtData{
+			Date:   time.Date(2015, 3, 2+i, 0, 0, 0, 0, utils.TaipeiTimeZone),
+			Volume: 1000,
+			Open:   v[2],
+			High:   v[0],
+			Low:    v[1],
+			Price:  v[2],
+		}
+	}
+	return result
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 0.001
+}
+
+func TestKD(t *testing.T) {
+	var data = sample([3]float64{10, 8, 9}, [3]float64{11, 9, 10}, [3]float64{12, 10, 11}, [3]float64{12, 9, 9})
+	K, D := KD(data, 3, 3, 3)
+	if K.Len() != 2 || !K.Date[0].Equal(data[2].Date) {
+		t.Fatalf("K should start at %s: %+v", data[2].Date, K)
+	}
+	if !closeTo(K.Value[0], 58.333) || !closeTo(D.Value[0], 52.778) {
+		t.Errorf("Should be 58.333, 52.778 but %v, %v", K.Value[0], D.Value[0])
+	}
+	if !closeTo(K.Last(), 38.889) || !closeTo(D.Last(), 48.148) {
+		t.Errorf("Should be 38.889, 48.148 but %v, %v", K.Last(), D.Last())
+	}
+}
+
+func TestRSI(t *testing.T) {
+	var data = sample([3]float64{10, 10, 10}, [3]float64{11, 11, 11}, [3]float64{10, 10, 10}, [3]float64{12, 12, 12})
+	rsi := RSI(data, 2)
+	if rsi.Len() != 2 || !rsi.Date[0].Equal(data[2].Date) {
+		t.Fatalf("RSI should start at %s: %+v", data[2].Date, rsi)
+	}
+	if !closeTo(rsi.Value[0], 50) || !closeTo(rsi.Last(), 83.333) {
+		t.Errorf("Should be 50, 83.333 but %v", rsi.Value)
+	}
+	if v, ok := rsi.At(data[3].Date); !ok || v != rsi.Last() {
+		t.Errorf("At should be %v but %v", rsi.Last(), v)
+	}
+	if RSI(data, 4).Len() != 0 {
+		t.Error("Should be empty")
+	}
+}
+
+func TestMACD(t *testing.T) {
+	var hlc = make([][3]float64, 40)
+	for i := range hlc {
+		hlc[i] = [3]float64{10, 10, 10}
+	}
+	dif, macd, osc := MACD(sample(hlc...), 12, 26, 9)
+	if dif.Len() != 15 || macd.Len() != 7 || osc.Len() != 7 {
+		t.Fatalf("Length wrong: %d %d %d", dif.Len(), macd.Len(), osc.Len())
+	}
+	if dif.Last() != 0 || macd.Last() != 0 || osc.Last() != 0 {
+		t.Errorf("Flat price should be 0: %v %v %v", dif.Last(), macd.Last(), osc.Last())
+	}
+
+	for i := range hlc {
+		p := float64(10 + i)
+		hlc[i] = [3]float64{p, p, p}
+	}
+	dif, macd, osc = MACD(sample(hlc...), 12, 26, 9)
+	if dif.Last() <= 0 || macd.Last() <= 0 || !closeTo(osc.Last(), dif.Last()-macd.Last()) {
+		t.Errorf("Rising price wrong: %v %v %v", dif.Last(), macd.Last(), osc.Last())
+	}
+}
+
+func TestBars(t *testing.T) {
+	var hlc = make([][3]float64, 40)
+	for i := range hlc {
+		hlc[i] = [3]float64{12, 8, 10}
+	}
+	var bars = twse.Resample(sample(hlc...), twse.NDays(2), nil)
+	if len(bars) >= 40 || len(bars) < 9 {
+		t.Fatalf("Bars length wrong: %d", len(bars))
+	}
+	if K, _ := KD(bars, 9, 3, 3); K.Len() != len(bars)-8 || !K.Date[0].Equal(bars[8].Date) {
+		t.Errorf("Should be %d but %d", len(bars)-8, K.Len())
+	}
+}
+
+func TestBollinger(t *testing.T) {
+	var data = sample([3]float64{1, 1, 1}, [3]float64{2, 2, 2}, [3]float64{3, 3, 3})
+	b := Bollinger(data, 3, 2)
+	if b.Middle.Len() != 1 || !b.Upper.Date[0].Equal(data[2].Date) {
+		t.Fatalf("Should start at %s: %+v", data[2].Date, b.Middle)
+	}
+	if b.Middle.Last() != 2 || !closeTo(b.Upper.Last(), 3.633) || !closeTo(b.Lower.Last(), 0.367) {
+		t.Errorf("Bands wrong: %v %v %v", b.Upper.Last(), b.Middle.Last(), b.Lower.Last())
+	}
+	if !closeTo(b.PercentB.Last(), 0.806) || !closeTo(b.Bandwidth.Last(), 1.633) {
+		t.Errorf("%%B, bandwidth wrong: %v %v", b.PercentB.Last(), b.Bandwidth.Last())
+	}
+	if Bollinger(data, 4, 2).Upper.Len() != 0 {
+		t.Error("Should be empty")
+	}
+}
+
+func TestATR(t *testing.T) {
+	// 第二日跳空上漲，真實區間為 13 - 9
+	var data = sample([3]float64{10, 8, 9}, [3]float64{13, 11, 12}, [3]float64{12, 10, 11})
+	atr := ATR(data, 2)
+	if atr.Len() != 2 || atr.Value[0] != 3 || atr.Value[1] != 2.5 {
+		t.Errorf("ATR wrong: %v", atr.Value)
+	}
+
+	k := Keltner(data, 2, 2, 1)
+	if k.Middle.Len() != 2 || k.Upper.Value[0] != 13.5 || !closeTo(k.Lower.Last(), 8.333) {
+		t.Errorf("Keltner wrong: %v %v %v", k.Upper.Value, k.Middle.Value, k.Lower.Value)
+	}
+}
+
+func TestVolume(t *testing.T) {
+	var data = sample([3]float64{10, 8, 9}, [3]float64{11, 9, 10}, [3]float64{10, 8, 8}, [3]float64{10, 8, 8})
+	for i, v := range []uint64{1000, 2000, 4000, 9000} {
+		data[i].Volume = v
+		data[i].TotalPrice = v * 9
+	}
+
+	if obv := OBV(data); obv.Len() != 4 || obv.Value[1] != 2000 || obv.Last() != -2000 {
+		t.Errorf("OBV wrong: %v", obv.Value)
+	}
+	if vwap := VWAP(data); vwap.Last() != 9 {
+		t.Errorf("VWAP wrong: %v", vwap.Value)
+	}
+	if ad := AD(data); ad.Value[0] != 0 || ad.Value[1] != 0 || ad.Last() != -13000 {
+		t.Errorf("AD wrong: %v", ad.Value)
+	}
+	// 正流量 10 * 2000，負流量 (26 / 3) * 4000
+	if mfi := MFI(data, 2); mfi.Len() != 2 || !closeTo(mfi.Value[0], 36.585) || mfi.Last() != 0 {
+		t.Errorf("MFI wrong: %v", mfi.Value)
+	}
+	if vr := VolumeRatio(data, 2); vr.Len() != 2 || !closeTo(vr.Value[0], 2.667) || vr.Last() != 3 {
+		t.Errorf("VolumeRatio wrong: %v", vr.Value)
+	}
+}
@@ -0,0 +1,127 @@
+package indicator
+
+import (
+	"github.com/DoubleChuang/gogrs/twse"
+)
+
+// KD 計算隨機指標，台灣常用參數為 KD(data, 9, 3, 3)
+//
+// RSV = (收盤 - n 日最低) / (n 日最高 - n 日最低) * 100，最高等於最低時 RSV 為 50；
+// K = 前日 K * (kn-1)/kn + RSV / kn，D = 前日 D * (dn-1)/dn + K / dn，
+// K、D 起始值為 50，自第 n 日開始計算。
+func KD(data []twse.FmtData, n, kn, dn int) (K, D Series) {
+	data = clean(data)
+	var (
+		dates = dateList(data)
+		kList = make([]float64, len(data))
+		dList = make([]float64, len(data))
+		k     = 50.0
+		d     = 50.0
+	)
+	if n < 1 || kn < 1 || dn < 1 {
+		return newSeries(dates, kList, -1), newSeries(dates, dList, -1)
+	}
+	for i := n - 1; i < len(data); i++ {
+		var (
+			high = data[i].High
+			low  = data[i].Low
+			rsv  = 50.0
+		)
+		for _, v := range data[i-n+1 : i] {
+			if v.High > high {
+				high = v.High
+			}
+			if v.Low < low {
+				low = v.Low
+			}
+		}
+		if high > low {
+			rsv = (data[i].Price - low) / (high - low) * 100
+		}
+		k = k*float64(kn-1)/float64(kn) + rsv/float64(kn)
+		d = d*float64(dn-1)/float64(dn) + k/float64(dn)
+		kList[i] = k
+		dList[i] = d
+	}
+	return newSeries(dates, kList, n-1), newSeries(dates, dList, n-1)
+}
+
+// RSI 計算相對強弱指標，台灣常用 RSI(data, 6)、RSI(data, 12)
+//
+// 以前 n 日漲跌幅的平均為起始值，之後使用 Wilder 平滑：
+// 平均 = (前日平均 * (n-1) + 當日) / n。自第 n+1 日開始計算。
+func RSI(data []twse.FmtData, n int) Series {
+	data = clean(data)
+	var (
+		dates  = dateList(data)
+		result = make([]float64, len(data))
+		up     float64
+		down   float64
+	)
+	if n < 1 || len(data) <= n {
+		return newSeries(dates, result, -1)
+	}
+	for i := 1; i < len(data); i++ {
+		var u, dn float64
+		if diff := data[i].Price - data[i-1].Price; diff > 0 {
+			u = diff
+		} else {
+			dn = -diff
+		}
+		if i <= n {
+			up += u / float64(n)
+			down += dn / float64(n)
+			if i < n {
+				continue
+			}
+		} else {
+			up = (up*float64(n-1) + u) / float64(n)
+			down = (down*float64(n-1) + dn) / float64(n)
+		}
+		switch {
+		case up+down == 0:
+			result[i] = 50
+		default:
+			result[i] = up / (up + down) * 100
+		}
+	}
+	return newSeries(dates, result, n)
+}
+
+// MACD 計算指數平滑異同移動平均，台灣常用參數為 MACD(data, 12, 26, 9)
+//
+// 依台灣券商慣例以需求指數 DI = (最高 + 最低 + 2 * 收盤) / 4 計算：
+// DIF = EMA(DI, fast) - EMA(DI, slow)，MACD = EMA(DIF, signal)，OSC = DIF - MACD。
+// DIF 自第 slow 日開始，MACD、OSC 自第 slow+signal-1 日開始。
+func MACD(data []twse.FmtData, fast, slow, signal int) (DIF, MACD, OSC Series) {
+	data = clean(data)
+	var (
+		dates = dateList(data)
+		di    = make([]float64, len(data))
+		dif   = make([]float64, len(data))
+		macd  = make([]float64, len(data))
+		osc   = make([]float64, len(data))
+	)
+	if fast < 1 || slow < fast || signal < 1 || len(data) < slow {
+		return newSeries(dates, dif, -1), newSeries(dates, macd, -1), newSeries(dates, osc, -1)
+	}
+	for i, v := range data {
+		di[i] = (v.High + v.Low + 2*v.Price) / 4
+	}
+	var (
+		emaFast = ema(di, fast)
+		emaSlow = ema(di, slow)
+	)
+	for i := slow - 1; i < len(data); i++ {
+		dif[i] = emaFast[i] - emaSlow[i]
+	}
+	var start = slow + signal - 2
+	if start < len(data) {
+		signalList := ema(dif[slow-1:], signal)
+		for i := start; i < len(data); i++ {
+			macd[i] = signalList[i-slow+1]
+			osc[i] = dif[i] - macd[i]
+		}
+	}
+	return newSeries(dates, dif, slow-1), newSeries(dates, macd, start), newSeries(dates, osc, start)
+}
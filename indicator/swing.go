@@ -0,0 +1,178 @@
+package indicator
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/twse"
+)
+
+// SwingPoint 波段高低點
+type SwingPoint struct {
+	Date  time.Time
+	Index int // 在 data 中的位置
+	Price float64
+	High  bool // true 為波段高點，false 為波段低點
+}
+
+// SwingPoints 以轉折幅度（ZigZag）找出波段高低點，threshold 為比例，如 0.05 為 5%
+//
+// 自前一個高（低）點回檔（反彈）超過 threshold 才確認該點，
+// 最後一段尚未確認的走勢不列入。
+func SwingPoints(data []twse.FmtData, threshold float64) []SwingPoint {
+	data = clean(data)
+	if len(data) == 0 {
+		return nil
+	}
+	var (
+		result []SwingPoint
+		high   = SwingPoint{Date: data[0].Date, Price: data[0].High, High: true}
+		low    = SwingPoint{Date: data[0].Date, Price: data[0].Low}
+		trend  int // 1 找高點、-1 找低點、0 尚未確定
+	)
+	for i, v := range data {
+		if v.High > high.Price {
+			high = SwingPoint{Date: v.Date, Index: i, Price: v.High, High: true}
+		}
+		if v.Low < low.Price {
+			low = SwingPoint{Date: v.Date, Index: i, Price: v.Low}
+		}
+		switch trend {
+		case 0:
+			if high.Index > low.Index && high.Price >= low.Price*(1+threshold) {
+				result = append(result, low)
+				trend = 1
+			} else if low.Index > high.Index && low.Price <= high.Price*(1-threshold) {
+				result = append(result, high)
+				trend = -1
+			}
+		case 1:
+			if v.Low <= high.Price*(1-threshold) {
+				result = append(result, high)
+				low = SwingPoint{Date: v.Date, Index: i, Price: v.Low}
+				trend = -1
+			}
+		case -1:
+			if v.High >= low.Price*(1+threshold) {
+				result = append(result, low)
+				high = SwingPoint{Date: v.Date, Index: i, Price: v.High, High: true}
+				trend = 1
+			}
+		}
+	}
+	return result
+}
+
+// Fractals 以碎形找出波段高低點，最高（最低）價高於（低於）左右各 n 根 K 線
+func Fractals(data []twse.FmtData, n int) []SwingPoint {
+	data = clean(data)
+	var result []SwingPoint
+	if n < 1 {
+		return result
+	}
+	for i := n; i < len(data)-n; i++ {
+		var isHigh, isLow = true, true
+		for j := i - n; j <= i+n; j++ {
+			if j == i {
+				continue
+			}
+			if data[j].High >= data[i].High {
+				isHigh = false
+			}
+			if data[j].Low <= data[i].Low {
+				isLow = false
+			}
+		}
+		if isHigh {
+			result = append(result, SwingPoint{Date: data[i].Date, Index: i, Price: data[i].High, High: true})
+		}
+		if isLow {
+			result = append(result, SwingPoint{Date: data[i].Date, Index: i, Price: data[i].Low})
+		}
+	}
+	return result
+}
+
+// Level 支撐、壓力價位
+type Level struct {
+	Price   float64   // 觸及價位的平均
+	Touches int       // 觸及次數
+	First   time.Time // 第一次觸及
+	Last    time.Time // 最後一次觸及
+}
+
+// Levels 將相近的波段高低點合併為價位，tolerance 為比例，如 0.02 為 2%，依價位排序
+func Levels(points []SwingPoint, tolerance float64) []Level {
+	var sorted = make([]SwingPoint, len(points))
+	copy(sorted, points)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Price < sorted[j].Price })
+
+	var (
+		result []Level
+		sum    float64
+	)
+	for _, p := range sorted {
+		if n := len(result); n > 0 && math.Abs(p.Price-result[n-1].Price) <= result[n-1].Price*tolerance {
+			l := &result[n-1]
+			sum += p.Price
+			l.Touches++
+			l.Price = sum / float64(l.Touches)
+			if p.Date.Before(l.First) {
+				l.First = p.Date
+			}
+			if p.Date.After(l.Last) {
+				l.Last = p.Date
+			}
+			continue
+		}
+		sum = p.Price
+		result = append(result, Level{Price: p.Price, Touches: 1, First: p.Date, Last: p.Date})
+	}
+	return result
+}
+
+// SupportResistance 以最後收盤價區分支撐（低於收盤價）與壓力（高於收盤價），依與收盤價的距離排序
+func SupportResistance(data []twse.FmtData, levels []Level) (support, resistance []Level) {
+	data = clean(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var price = data[len(data)-1].Price
+	for _, l := range levels {
+		if l.Price < price {
+			support = append([]Level{l}, support...)
+		} else {
+			resistance = append(resistance, l)
+		}
+	}
+	return support, resistance
+}
+
+// Breakout 突破或跌破價位
+type Breakout struct {
+	Date  time.Time
+	Level Level
+	Up    bool // true 為收盤突破，false 為收盤跌破
+}
+
+// Breakouts 找出價位形成（最後一次觸及）後，收盤價由下往上突破或由上往下跌破的日子，依日期排序
+func Breakouts(data []twse.FmtData, levels []Level) []Breakout {
+	data = clean(data)
+	var result []Breakout
+	for i := 1; i < len(data); i++ {
+		var prev, price = data[i-1].Price, data[i].Price
+		for _, l := range levels {
+			if !data[i].Date.After(l.Last) {
+				continue
+			}
+			switch {
+			case prev < l.Price && price > l.Price:
+				result = append(result, Breakout{Date: data[i].Date, Level: l, Up: true})
+			case prev > l.Price && price < l.Price:
+				result = append(result, Breakout{Date: data[i].Date, Level: l})
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,38 @@
+package indicator
+
+import "testing"
+
+func TestSwingPoints(t *testing.T) {
+	var hlc [][3]float64
+	for _, v := range []float64{10, 11, 12, 11, 10, 11, 12.5, 13} {
+		hlc = append(hlc, [3]float64{v, v, v})
+	}
+	var data = sample(hlc...)
+
+	points := SwingPoints(data, 0.1)
+	if len(points) != 3 || points[0].High || !points[1].High || points[1].Price != 12 || points[2].Index != 4 {
+		t.Fatalf("SwingPoints wrong: %+v", points)
+	}
+	if fractals := Fractals(data, 1); len(fractals) != 2 || fractals[0].Index != 2 || fractals[1].Index != 4 {
+		t.Errorf("Fractals wrong: %+v", fractals)
+	}
+
+	levels := Levels(points, 0.02)
+	if len(levels) != 2 || levels[0].Price != 10 || levels[0].Touches != 2 ||
+		!levels[0].First.Equal(data[0].Date) || !levels[0].Last.Equal(data[4].Date) {
+		t.Fatalf("Levels wrong: %+v", levels)
+	}
+
+	support, resistance := SupportResistance(data, levels)
+	if len(support) != 2 || support[0].Price != 12 || len(resistance) != 0 {
+		t.Errorf("SupportResistance wrong: %+v %+v", support, resistance)
+	}
+	if support, resistance = SupportResistance(data[:6], levels); len(support) != 1 || len(resistance) != 1 {
+		t.Errorf("SupportResistance wrong: %+v %+v", support, resistance)
+	}
+
+	breakouts := Breakouts(data, levels)
+	if len(breakouts) != 1 || !breakouts[0].Up || !breakouts[0].Date.Equal(data[6].Date) || breakouts[0].Level.Price != 12 {
+		t.Errorf("Breakouts wrong: %+v", breakouts)
+	}
+}
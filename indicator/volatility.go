@@ -0,0 +1,144 @@
+package indicator
+
+import (
+	"math"
+
+	"github.com/DoubleChuang/gogrs/twse"
+)
+
+// Bands 通道指標
+type Bands struct {
+	Upper     Series // 上軌
+	Middle    Series // 中軌
+	Lower     Series // 下軌
+	PercentB  Series // %B = (收盤 - 下軌) / (上軌 - 下軌)
+	Bandwidth Series // 帶寬 = (上軌 - 下軌) / 中軌
+}
+
+// newBands 由中軌與上下軌寬度建立通道，自第 start 筆開始
+func newBands(data []twse.FmtData, middle, width []float64, start int) Bands {
+	var (
+		dates     = dateList(data)
+		upper     = make([]float64, len(data))
+		lower     = make([]float64, len(data))
+		percentB  = make([]float64, len(data))
+		bandwidth = make([]float64, len(data))
+	)
+	if start < 0 || start >= len(data) {
+		start = -1
+	} else {
+		for i := start; i < len(data); i++ {
+			upper[i] = middle[i] + width[i]
+			lower[i] = middle[i] - width[i]
+			percentB[i] = 0.5
+			if upper[i] > lower[i] {
+				percentB[i] = (data[i].Price - lower[i]) / (upper[i] - lower[i])
+			}
+			if middle[i] != 0 {
+				bandwidth[i] = (upper[i] - lower[i]) / middle[i]
+			}
+		}
+	}
+	return Bands{
+		Upper:     newSeries(dates, upper, start),
+		Middle:    newSeries(dates, middle, start),
+		Lower:     newSeries(dates, lower, start),
+		PercentB:  newSeries(dates, percentB, start),
+		Bandwidth: newSeries(dates, bandwidth, start),
+	}
+}
+
+// Bollinger 計算布林通道，常用參數為 Bollinger(data, 20, 2)
+//
+// 中軌為 n 日收盤價平均，上下軌為中軌加減 k 倍的 n 日收盤價標準差（母體）。
+func Bollinger(data []twse.FmtData, n int, k float64) Bands {
+	data = clean(data)
+	var (
+		middle = make([]float64, len(data))
+		width  = make([]float64, len(data))
+		price  = make([]float64, len(data))
+	)
+	if n < 1 || len(data) < n {
+		return newBands(data, middle, width, -1)
+	}
+	for i, v := range data {
+		price[i] = v.Price
+	}
+	for i := n - 1; i < len(data); i++ {
+		middle[i] = sma(price, i-n+1, n)
+		var variance float64
+		for _, v := range price[i-n+1 : i+1] {
+			variance += (v - middle[i]) * (v - middle[i])
+		}
+		width[i] = k * math.Sqrt(variance/float64(n))
+	}
+	return newBands(data, middle, width, n-1)
+}
+
+// trueRange 計算真實區間，含與前一日收盤價的跳空缺口，第一日為最高減最低
+func trueRange(data []twse.FmtData) []float64 {
+	var result = make([]float64, len(data))
+	for i, v := range data {
+		result[i] = v.High - v.Low
+		if i > 0 {
+			prev := data[i-1].Price
+			result[i] = math.Max(result[i], math.Max(math.Abs(v.High-prev), math.Abs(v.Low-prev)))
+		}
+	}
+	return result
+}
+
+// wilderATR 以前 n 日真實區間平均為起始值，之後 ATR = (前日 ATR * (n-1) + TR) / n
+func wilderATR(data []twse.FmtData, n int) []float64 {
+	var (
+		tr     = trueRange(data)
+		result = make([]float64, len(data))
+	)
+	if n < 1 || len(data) < n {
+		return result
+	}
+	result[n-1] = sma(tr, 0, n)
+	for i := n; i < len(data); i++ {
+		result[i] = (result[i-1]*float64(n-1) + tr[i]) / float64(n)
+	}
+	return result
+}
+
+// ATR 計算 Wilder 平均真實區間，常用參數為 ATR(data, 14)，自第 n 日開始
+func ATR(data []twse.FmtData, n int) Series {
+	data = clean(data)
+	var start = n - 1
+	if n < 1 {
+		start = -1
+	}
+	return newSeries(dateList(data), wilderATR(data, n), start)
+}
+
+// Keltner 計算肯特納通道，常用參數為 Keltner(data, 20, 10, 2)
+//
+// 中軌為 n 日收盤價 EMA，上下軌為中軌加減 k 倍的 atrN 日 ATR。
+func Keltner(data []twse.FmtData, n, atrN int, k float64) Bands {
+	data = clean(data)
+	var (
+		price = make([]float64, len(data))
+		width = make([]float64, len(data))
+		start = n - 1
+	)
+	if atrN > n {
+		start = atrN - 1
+	}
+	if n < 1 || atrN < 1 || len(data) <= start {
+		return newBands(data, price, width, -1)
+	}
+	for i, v := range data {
+		price[i] = v.Price
+	}
+	var (
+		middle = ema(price, n)
+		atr    = wilderATR(data, atrN)
+	)
+	for i := start; i < len(data); i++ {
+		width[i] = k * atr[i]
+	}
+	return newBands(data, middle, width, start)
+}
@@ -0,0 +1,120 @@
+package indicator
+
+import (
+	"github.com/DoubleChuang/gogrs/twse"
+)
+
+// OBV 計算能量潮，收盤上漲加上當日成交股數、下跌則減去，第一日為 0
+func OBV(data []twse.FmtData) Series {
+	data = clean(data)
+	var result = make([]float64, len(data))
+	for i := 1; i < len(data); i++ {
+		result[i] = result[i-1]
+		switch {
+		case data[i].Price > data[i-1].Price:
+			result[i] += float64(data[i].Volume)
+		case data[i].Price < data[i-1].Price:
+			result[i] -= float64(data[i].Volume)
+		}
+	}
+	return newSeries(dateList(data), result, 0)
+}
+
+// VWAP 計算每日成交均價（成交金額 / 成交股數），無成交時為收盤價
+func VWAP(data []twse.FmtData) Series {
+	data = clean(data)
+	var result = make([]float64, len(data))
+	for i, v := range data {
+		result[i] = v.Price
+		if v.Volume > 0 {
+			result[i] = float64(v.TotalPrice) / float64(v.Volume)
+		}
+	}
+	return newSeries(dateList(data), result, 0)
+}
+
+// MFI 計算資金流量指標，常用參數為 MFI(data, 14)
+//
+// 典型價格 TP = (最高 + 最低 + 收盤) / 3，資金流量 = TP * 成交股數，
+// 依 TP 與前一日比較分為正、負資金流量，MFI = 100 - 100 / (1 + n 日正流量 / n 日負流量)。
+// 自第 n+1 日開始計算。
+func MFI(data []twse.FmtData, n int) Series {
+	data = clean(data)
+	var (
+		result = make([]float64, len(data))
+		pos    = make([]float64, len(data))
+		neg    = make([]float64, len(data))
+	)
+	if n < 1 || len(data) <= n {
+		return newSeries(dateList(data), result, -1)
+	}
+	for i := 1; i < len(data); i++ {
+		var (
+			tp     = (data[i].High + data[i].Low + data[i].Price) / 3
+			prevTP = (data[i-1].High + data[i-1].Low + data[i-1].Price) / 3
+		)
+		switch {
+		case tp > prevTP:
+			pos[i] = tp * float64(data[i].Volume)
+		case tp < prevTP:
+			neg[i] = tp * float64(data[i].Volume)
+		}
+	}
+	for i := n; i < len(data); i++ {
+		var (
+			posFlow = sma(pos, i-n+1, n)
+			negFlow = sma(neg, i-n+1, n)
+		)
+		switch {
+		case posFlow+negFlow == 0:
+			result[i] = 50
+		case negFlow == 0:
+			result[i] = 100
+		default:
+			result[i] = 100 - 100/(1+posFlow/negFlow)
+		}
+	}
+	return newSeries(dateList(data), result, n)
+}
+
+// AD 計算累積／派發線，第一日開始累加
+//
+// CLV = ((收盤 - 最低) - (最高 - 收盤)) / (最高 - 最低)，最高等於最低時為 0，
+// AD = 前日 AD + CLV * 成交股數。
+func AD(data []twse.FmtData) Series {
+	data = clean(data)
+	var (
+		result = make([]float64, len(data))
+		ad     float64
+	)
+	for i, v := range data {
+		if v.High > v.Low {
+			ad += ((v.Price - v.Low) - (v.High - v.Price)) / (v.High - v.Low) * float64(v.Volume)
+		}
+		result[i] = ad
+	}
+	return newSeries(dateList(data), result, 0)
+}
+
+// VolumeRatio 計算量比，當日成交股數 / 前 n 日（不含當日）平均成交股數，常用 n 為 5
+//
+// 自第 n+1 日開始計算，前 n 日皆無成交時為 0。
+func VolumeRatio(data []twse.FmtData, n int) Series {
+	data = clean(data)
+	var (
+		result = make([]float64, len(data))
+		volume = make([]float64, len(data))
+	)
+	if n < 1 || len(data) <= n {
+		return newSeries(dateList(data), result, -1)
+	}
+	for i, v := range data {
+		volume[i] = float64(v.Volume)
+	}
+	for i := n; i < len(data); i++ {
+		if avg := sma(volume, i-n, n); avg > 0 {
+			result[i] = volume[i] / avg
+		}
+	}
+	return newSeries(dateList(data), result, n)
+}
@@ -1,5 +1,5 @@
 // Package realtime - Fetch realtime stock data info
-// 擷取盤中個股、指數即時股價資訊
+// 擷取盤中個股、盤中零股、指數即時股價資訊
 //
 package realtime
 
@@ -26,6 +26,8 @@ type StockRealTime struct {
 	Date        time.Time   // 擷取時間
 	UnixMapData unixMapData // 時間資料暫存
 	Exchange    string      // tse, otc
+	Host        string      // 即時資料主機，空白為 utils.TWSEURL
+	OddLot      bool        // 盤中零股，成交量與五檔量單位為股
 }
 
 // StockBlob return map data.
@@ -38,11 +40,23 @@ type StockBlob struct {
 	QueryTime map[string]interface{}
 }
 
+// host return realtime host, default is utils.TWSEURL.
+func (stock StockRealTime) host() string {
+	if stock.Host != "" {
+		return stock.Host
+	}
+	return utils.TWSEURL
+}
+
 // URL return realtime url path.
 func (stock StockRealTime) URL() string {
 	if utils.ExchangeMap[stock.Exchange] {
-		return fmt.Sprintf("%s%s", utils.TWSEURL,
-			fmt.Sprintf(utils.TWSEREAL,
+		var path = utils.TWSEREAL
+		if stock.OddLot {
+			path = utils.TWSEODDREAL
+		}
+		return fmt.Sprintf("%s%s", stock.host(),
+			fmt.Sprintf(path,
 				stock.Exchange,
 				stock.No,
 				time.Now().Unix()*1000,
@@ -78,6 +92,7 @@ type Data struct {
 	YesterdayPrice float64                // 昨日收盤價格
 	TradeTime      time.Time              // 交易時間
 	Info           StockInfo              // 相關資訊
+	OddLot         bool                   // 盤中零股
 	SysInfo        map[string]interface{} // 系統回傳資訊
 }
 
@@ -106,14 +121,14 @@ func (stock *StockRealTime) get() (StockBlob, error) {
 		},
 		Jar: cookieJar,
 	}
-	resp, _ = client.Get(utils.TWSEURL + utils.HOME)
+	resp, _ = client.Get(stock.host() + utils.HOME)
 
 	req, _ := http.NewRequest("GET", stock.URL(), nil)
 	req.Header.Set("Accept", "application/json, text/javascript, */*; q=0.01")
 	req.Header.Set("Accept-Encoding", "gzip, deflate, sdch")
 	req.Header.Set("Accept-Language", "zh-TW,zh;q=0.8,en-US;q=0.6,en;q=0.4")
 	req.Header.Set("Connection", "keep-alive")
-	req.Header.Set("Referer", "http://mis.twse.com.tw/stock/fibest.jsp?stock="+stock.No)
+	req.Header.Set("Referer", stock.host()+"/stock/fibest.jsp?stock="+stock.No)
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_12_4) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/58.0.3029.54 Safari/537.36")
 	req.Header.Set("X-Requested-With", "XMLHttpRequest")
 
@@ -140,56 +155,64 @@ func (stock *StockRealTime) Get() (Data, error) {
 	)
 
 	if value, err = stock.get(); err == nil && len(value.MsgArray) != 0 {
-		aList := strings.Split(value.MsgArray[0]["a"], "_")
-		result.BestAskPrice = make([]float64, len(aList)-1)
-		for i, v := range aList[:len(aList)-1] {
-			result.BestAskPrice[i], _ = strconv.ParseFloat(v, 10)
-		}
+		result = stock.format(value)
 
-		bList := strings.Split(value.MsgArray[0]["b"], "_")
-		result.BestBidPrice = make([]float64, len(bList)-1)
-		for i, v := range bList[:len(bList)-1] {
-			result.BestBidPrice[i], _ = strconv.ParseFloat(v, 10)
-		}
+		// Record
+		stock.UnixMapData[result.TradeTime.Unix()] = result
+	}
+	return result, err
+}
 
-		fList := strings.Split(value.MsgArray[0]["f"], "_")
-		result.BestAskVolume = make([]int64, len(fList)-1)
-		for i, v := range fList[:len(fList)-1] {
-			result.BestAskVolume[i], _ = strconv.ParseInt(v, 10, 64)
-		}
+// format 將回傳資料轉為 Data
+func (stock StockRealTime) format(value StockBlob) Data {
+	var result = Data{OddLot: stock.OddLot}
 
-		gList := strings.Split(value.MsgArray[0]["g"], "_")
-		result.BestBidVolume = make([]int64, len(gList)-1)
-		for i, v := range gList[:len(gList)-1] {
-			result.BestBidVolume[i], _ = strconv.ParseInt(v, 10, 64)
-		}
+	aList := strings.Split(value.MsgArray[0]["a"], "_")
+	result.BestAskPrice = make([]float64, len(aList)-1)
+	for i, v := range aList[:len(aList)-1] {
+		result.BestAskPrice[i], _ = strconv.ParseFloat(v, 10)
+	}
 
-		result.Open, _ = strconv.ParseFloat(value.MsgArray[0]["o"], 10)
-		result.Highest, _ = strconv.ParseFloat(value.MsgArray[0]["h"], 10)
-		result.Lowest, _ = strconv.ParseFloat(value.MsgArray[0]["l"], 10)
-		result.Price, _ = strconv.ParseFloat(value.MsgArray[0]["z"], 10)
-		result.LimitUp, _ = strconv.ParseFloat(value.MsgArray[0]["u"], 10)
-		result.LimitDown, _ = strconv.ParseFloat(value.MsgArray[0]["w"], 10)
-		result.Volume, _ = strconv.ParseFloat(value.MsgArray[0]["tv"], 10)
-		result.VolumeAcc, _ = strconv.ParseFloat(value.MsgArray[0]["v"], 10)
-		result.YesterdayPrice, _ = strconv.ParseFloat(value.MsgArray[0]["y"], 10)
-		tlong, _ := strconv.ParseInt(value.MsgArray[0]["tlong"], 10, 64)
-		result.TradeTime = time.Unix(tlong/1000, 0)
-
-		result.Info.No = value.MsgArray[0]["c"]
-		result.Info.FullName = value.MsgArray[0]["nf"]
-		result.Info.Name = value.MsgArray[0]["n"]
-		result.Info.Ticker = value.MsgArray[0]["ch"]
-		result.Info.Exchange = value.MsgArray[0]["ex"]
-		result.Info.Category = value.MsgArray[0]["i"]
-
-		result.SysInfo = make(map[string]interface{})
-		result.SysInfo = value.QueryTime
+	bList := strings.Split(value.MsgArray[0]["b"], "_")
+	result.BestBidPrice = make([]float64, len(bList)-1)
+	for i, v := range bList[:len(bList)-1] {
+		result.BestBidPrice[i], _ = strconv.ParseFloat(v, 10)
+	}
 
-		// Record
-		stock.UnixMapData[tlong/1000] = result
+	fList := strings.Split(value.MsgArray[0]["f"], "_")
+	result.BestAskVolume = make([]int64, len(fList)-1)
+	for i, v := range fList[:len(fList)-1] {
+		result.BestAskVolume[i], _ = strconv.ParseInt(v, 10, 64)
 	}
-	return result, err
+
+	gList := strings.Split(value.MsgArray[0]["g"], "_")
+	result.BestBidVolume = make([]int64, len(gList)-1)
+	for i, v := range gList[:len(gList)-1] {
+		result.BestBidVolume[i], _ = strconv.ParseInt(v, 10, 64)
+	}
+
+	result.Open, _ = strconv.ParseFloat(value.MsgArray[0]["o"], 10)
+	result.Highest, _ = strconv.ParseFloat(value.MsgArray[0]["h"], 10)
+	result.Lowest, _ = strconv.ParseFloat(value.MsgArray[0]["l"], 10)
+	result.Price, _ = strconv.ParseFloat(value.MsgArray[0]["z"], 10)
+	result.LimitUp, _ = strconv.ParseFloat(value.MsgArray[0]["u"], 10)
+	result.LimitDown, _ = strconv.ParseFloat(value.MsgArray[0]["w"], 10)
+	result.Volume, _ = strconv.ParseFloat(value.MsgArray[0]["tv"], 10)
+	result.VolumeAcc, _ = strconv.ParseFloat(value.MsgArray[0]["v"], 10)
+	result.YesterdayPrice, _ = strconv.ParseFloat(value.MsgArray[0]["y"], 10)
+	tlong, _ := strconv.ParseInt(value.MsgArray[0]["tlong"], 10, 64)
+	result.TradeTime = time.Unix(tlong/1000, 0)
+
+	result.Info.No = value.MsgArray[0]["c"]
+	result.Info.FullName = value.MsgArray[0]["nf"]
+	result.Info.Name = value.MsgArray[0]["n"]
+	result.Info.Ticker = value.MsgArray[0]["ch"]
+	result.Info.Exchange = value.MsgArray[0]["ex"]
+	result.Info.Category = value.MsgArray[0]["i"]
+
+	result.SysInfo = make(map[string]interface{})
+	result.SysInfo = value.QueryTime
+	return result
 }
 
 // NewTWSE 建立一個上市股票
@@ -212,6 +235,20 @@ func NewOTC(No string, Date time.Time) *StockRealTime {
 	}
 }
 
+// NewTWSEOddLot 建立一個上市股票的盤中零股
+func NewTWSEOddLot(No string, Date time.Time) *StockRealTime {
+	stock := NewTWSE(No, Date)
+	stock.OddLot = true
+	return stock
+}
+
+// NewOTCOddLot 建立一個上櫃股票的盤中零股
+func NewOTCOddLot(No string, Date time.Time) *StockRealTime {
+	stock := NewOTC(No, Date)
+	stock.OddLot = true
+	return stock
+}
+
 // NewWeight 大盤指數
 func NewWeight(Date time.Time) *StockRealTime {
 	return &StockRealTime{
@@ -2,6 +2,7 @@ package realtime
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -114,3 +115,19 @@ func ExampleNewOTC() {
 	// output:
 	// {Exchange:otc FullName:華研國際音樂股份有限公司 Name:華研 No:8446 Ticker:8446.tw Category:32}
 }
+
+func TestStockRealTime_oddLot(t *testing.T) {
+	r := NewTWSEOddLot("2330", time.Date(2020, 10, 30, 0, 0, 0, 0, utils.TaipeiTimeZone))
+	if !strings.Contains(r.URL(), "/stock/api/getOddInfo.jsp?ex_ch=tse_2330.tw") {
+		t.Errorf("Wrong url: %s", r.URL())
+	}
+	data := r.format(StockBlob{MsgArray: msgArray{{
+		"a": "436.5000_437.0000_", "b": "436.0000_435.5000_",
+		"f": "1200_300_", "g": "800_50_",
+		"z": "436.0000", "v": "123456", "tlong": "1604037600000",
+		"c": "2330", "n": "台積電", "ex": "tse",
+	}}})
+	if !data.OddLot || data.BestBidPrice[0] != 436 || data.BestAskVolume[0] != 1200 || data.VolumeAcc != 123456 {
+		t.Errorf("Wrong data: %+v", data)
+	}
+}
@@ -5,21 +5,74 @@ package tradingdays
 
 import (
 	"bytes"
+	_ "embed" // list.csv
 	"encoding/csv"
 	"io"
 	"io/ioutil"
 	"net/http"
-	"path"
-	"runtime"
+	"sync"
 	"time"
 
 	"github.com/DoubleChuang/gogrs/utils"
 )
 
+// Calendar 開休市表，記錄非週末的休市日與週末的補班日
+type Calendar struct {
+	mu         sync.RWMutex
+	csvEtag    string
+	exceptDays map[int64]bool
+}
+
+// NewCalendar 建立一個使用內建 list.csv 的開休市表
+func NewCalendar() *Calendar {
+	c := &Calendar{exceptDays: make(map[int64]bool)}
+	c.LoadCSV(bytes.NewReader(listCSV), false)
+	return c
+}
+
+var (
+	defaultCalendar     *Calendar
+	defaultCalendarOnce sync.Once
+)
+
+// DefaultCalendar 回傳套件層級函式所使用的開休市表
+func DefaultCalendar() *Calendar {
+	defaultCalendarOnce.Do(func() {
+		defaultCalendar = NewCalendar()
+	})
+	return defaultCalendar
+}
+
 // IsOpen 判斷是否為開休日
 func IsOpen(year int, month time.Month, day int) bool {
+	return DefaultCalendar().IsOpen(year, month, day)
+}
+
+// FindRecentlyOpened 回傳最近一個開市時間（UTC 0）
+func FindRecentlyOpened(date time.Time) time.Time {
+	return DefaultCalendar().FindRecentlyOpened(date)
+}
+
+// FindRecentlyOpenedTaipeiZone 回傳最近一個開市時間（TaipeiZone 0）
+func FindRecentlyOpenedTaipeiZone(date time.Time) time.Time {
+	return DefaultCalendar().FindRecentlyOpenedTaipeiZone(date)
+}
+
+// DownloadCSV 更新開休市表
+//
+// 從 https://s3-ap-northeast-1.amazonaws.com/toomore/gogrs/list.csv
+// 下載表更新，主要發生在非國定假日，如：颱風假。
+func DownloadCSV(replace bool) {
+	DefaultCalendar().DownloadCSV(replace)
+}
+
+// IsOpen 判斷是否為開休日
+func (c *Calendar) IsOpen(year int, month time.Month, day int) bool {
 	d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
-	if openornot, ok := exceptDays[d.Unix()]; ok {
+	c.mu.RLock()
+	openornot, ok := c.exceptDays[d.Unix()]
+	c.mu.RUnlock()
+	if ok {
 		return openornot
 	}
 	if d.In(utils.TaipeiTimeZone).Weekday() == 0 || d.In(utils.TaipeiTimeZone).Weekday() == 6 {
@@ -29,38 +82,18 @@ func IsOpen(year int, month time.Month, day int) bool {
 }
 
 // FindRecentlyOpened 回傳最近一個開市時間（UTC 0）
-func FindRecentlyOpened(date time.Time) time.Time {
-	var (
-		d     = date.In(utils.TaipeiTimeZone)
-		days  = d.Day()
-		index int
-		tp    *TimePeriod
-	)
-
-	for {
-		if IsOpen(d.Year(), d.Month(), days) {
-			if index == 0 {
-				tp = NewTimePeriod(time.Date(d.Year(), d.Month(), days, d.Hour(), d.Minute(), d.Second(), d.Nanosecond(), utils.TaipeiTimeZone))
-				if tp.AtBefore() || tp.AtOpen() {
-					days--
-					for {
-						if IsOpen(d.Year(), d.Month(), days) {
-							break
-						}
-						days--
-					}
-				}
-			}
-			return time.Date(d.Year(), d.Month(), days, 0, 0, 0, 0, time.UTC)
-		}
-		days--
-		index++
-	}
-
+func (c *Calendar) FindRecentlyOpened(date time.Time) time.Time {
+	year, month, days := c.findRecentlyOpened(date)
+	return time.Date(year, month, days, 0, 0, 0, 0, time.UTC)
 }
 
 // FindRecentlyOpenedTaipeiZone 回傳最近一個開市時間（TaipeiZone 0）
-func FindRecentlyOpenedTaipeiZone(date time.Time) time.Time {
+func (c *Calendar) FindRecentlyOpenedTaipeiZone(date time.Time) time.Time {
+	year, month, days := c.findRecentlyOpened(date)
+	return time.Date(year, month, days, 0, 0, 0, 0, utils.TaipeiTimeZone)
+}
+
+func (c *Calendar) findRecentlyOpened(date time.Time) (int, time.Month, int) {
 	var (
 		d     = date.In(utils.TaipeiTimeZone)
 		days  = d.Day()
@@ -69,80 +102,77 @@ func FindRecentlyOpenedTaipeiZone(date time.Time) time.Time {
 	)
 
 	for {
-		if IsOpen(d.Year(), d.Month(), days) {
+		if c.IsOpen(d.Year(), d.Month(), days) {
 			if index == 0 {
 				tp = NewTimePeriod(time.Date(d.Year(), d.Month(), days, d.Hour(), d.Minute(), d.Second(), d.Nanosecond(), utils.TaipeiTimeZone))
 				if tp.AtBefore() || tp.AtOpen() {
 					days--
 					for {
-						if IsOpen(d.Year(), d.Month(), days) {
+						if c.IsOpen(d.Year(), d.Month(), days) {
 							break
 						}
 						days--
 					}
 				}
 			}
-			return time.Date(d.Year(), d.Month(), days, 0, 0, 0, 0, utils.TaipeiTimeZone)
+			return d.Year(), d.Month(), days
 		}
 		days--
 		index++
 	}
-
 }
 
-var (
-	csvEtag    string
-	exceptDays map[int64]bool
-)
-
-func readCSV() {
-	_, filename, _, _ := runtime.Caller(1)
-	data, _ := ioutil.ReadFile(path.Join(path.Dir(filename), "list.csv"))
-	processCSV(bytes.NewReader(data))
-}
-
-// DownloadCSV 更新開休市表
-//
-// 從 https://s3-ap-northeast-1.amazonaws.com/toomore/gogrs/list.csv
-// 下載表更新，主要發生在非國定假日，如：颱風假。
-func DownloadCSV(replace bool) {
+// DownloadCSV 從 utils.S3CSV 下載表更新
+func (c *Calendar) DownloadCSV(replace bool) {
 	req, _ := http.NewRequest("GET", utils.S3CSV, nil)
-	req.Header.Set("If-None-Match", csvEtag)
-	resp, _ := utils.HTTPClient.Do(req)
-	if resp.StatusCode != http.StatusNotModified && resp.StatusCode == http.StatusOK {
-		csvEtag = resp.Header.Get("Etag")
-		updateCSVData(resp, replace)
+	c.mu.RLock()
+	req.Header.Set("If-None-Match", c.csvEtag)
+	c.mu.RUnlock()
+	resp, err := utils.HTTPClient.Do(req)
+	if err != nil {
+		return
 	}
-}
-
-func updateCSVData(resp *http.Response, replace bool) {
 	defer resp.Body.Close()
-	if data, err := ioutil.ReadAll(resp.Body); err == nil {
-		if replace {
-			exceptDays = make(map[int64]bool)
+	if resp.StatusCode != http.StatusNotModified && resp.StatusCode == http.StatusOK {
+		if data, err := ioutil.ReadAll(resp.Body); err == nil {
+			c.mu.Lock()
+			c.csvEtag = resp.Header.Get("Etag")
+			c.mu.Unlock()
+			c.LoadCSV(bytes.NewReader(data), replace)
 		}
-		processCSV(bytes.NewReader(data))
 	}
 }
 
-func processCSV(data io.Reader) {
+// LoadCSV 讀入開休市表（"2006/1/2",0 格式），replace 為是否清除原有資料
+func (c *Calendar) LoadCSV(data io.Reader, replace bool) {
 	csvdata := csv.NewReader(data)
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if replace {
+		c.exceptDays = make(map[int64]bool)
+	}
 	for {
 		record, err := csvdata.Read()
 		if err == io.EOF {
 			break
 		}
+		if err != nil || len(record) < 2 {
+			continue
+		}
 		if t, err := time.ParseInLocation("2006/1/2", record[0], time.UTC); err == nil {
 			var isopen bool
 			if record[1] == "1" {
 				isopen = true
 			}
-			exceptDays[t.Unix()] = isopen
+			c.exceptDays[t.Unix()] = isopen
 		}
 	}
 }
 
+//go:embed list.csv
+var listCSV []byte
+
 // TimePeriod 簡單計算時間是否在當日的開盤前、盤中、盤後盤、收盤的時間
 /*
 相關時間：
@@ -215,8 +245,3 @@ type lazyTime struct {
 func (t lazyTime) time(hour, min int) time.Time {
 	return time.Date(t.date.Year(), t.date.Month(), t.date.Day(), hour, min, 0, 0, t.date.Location())
 }
-
-func init() {
-	exceptDays = make(map[int64]bool)
-	readCSV()
-}
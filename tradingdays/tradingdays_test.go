@@ -2,6 +2,7 @@ package tradingdays
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -152,3 +153,21 @@ func BenchmarkFindRecentlyOpened(b *testing.B) {
 func init() {
 	DownloadCSV(true)
 }
+
+func TestCalendar_LoadCSV(t *testing.T) {
+	c := NewCalendar()
+	c.LoadCSV(strings.NewReader("\"2015/4/17\",0\n\"2015/4/18\",1\n"), false)
+	if c.IsOpen(2015, 4, 17) != false {
+		t.Error("Should be `false`")
+	}
+	if c.IsOpen(2015, 4, 18) != true {
+		t.Error("Should be `true`")
+	}
+	if DefaultCalendar().IsOpen(2015, 4, 17) != true {
+		t.Error("Default calendar should not be changed")
+	}
+	if c.FindRecentlyOpened(time.Date(2015, 4, 17, 20, 0, 0, 0, utils.TaipeiTimeZone)).Unix() !=
+		time.Date(2015, 4, 16, 0, 0, 0, 0, time.UTC).Unix() {
+		t.Error("Should be at 2015/4/16")
+	}
+}
@@ -0,0 +1,563 @@
+package twse
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/tradingdays"
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+// Client 擷取資料所需的主機、快取、存取間隔與開休市表設定
+//
+// 由 Client 建立的資料集會共用同一份設定，不同的 Client 之間互不影響。
+type Client struct {
+	twseHost   string
+	otcHost    string
+	mopsHost   string
+	cacheDir   string
+	encoding   string
+	httpClient *http.Client
+	rateLimits map[string]time.Duration
+	fetcher    Fetcher
+	calendar   *tradingdays.Calendar
+	store      Store
+	concurrent int
+	strict     bool
+	transport  Transport
+	// utf8Fetcher TransportJSON 與公開資訊觀測站使用的 Fetcher，不轉換編碼
+	utf8Fetcher Fetcher
+}
+
+// ClientOption 設定 Client 的選項
+type ClientOption func(*Client)
+
+// WithTWSEHost 指定上市資料主機，預設為 utils.TWSEHOST
+func WithTWSEHost(host string) ClientOption {
+	return func(c *Client) {
+		c.twseHost = host
+	}
+}
+
+// WithOTCHost 指定上櫃資料主機，預設為 utils.OTCHOST
+func WithOTCHost(host string) ClientOption {
+	return func(c *Client) {
+		c.otcHost = host
+	}
+}
+
+// WithMOPSHost 指定公開資訊觀測站主機，預設為 utils.MOPSHOST
+func WithMOPSHost(host string) ClientOption {
+	return func(c *Client) {
+		c.mopsHost = host
+	}
+}
+
+// WithCacheDir 指定快取位置，預設為 utils.GetOSRamdiskPath
+func WithCacheDir(dir string) ClientOption {
+	return func(c *Client) {
+		c.cacheDir = dir
+	}
+}
+
+// WithEncoding 指定來源檔案編碼，預設為 cp950
+func WithEncoding(encoding string) ClientOption {
+	return func(c *Client) {
+		c.encoding = encoding
+	}
+}
+
+// WithHTTPClient 指定連線使用的 http.Client
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = client
+	}
+}
+
+// WithRateLimit 指定造訪上市、上櫃主機的最小間隔
+//
+// 未指定任何間隔時沿用 utils.TWSEDURTION、utils.OTCDURTION、utils.MOPSDURTION、utils.TDCCDURTION 的全域間隔；
+// 有指定時未指定的主機以相同的秒數建立 Client 自己的間隔。
+func WithRateLimit(twse, otc time.Duration) ClientOption {
+	return func(c *Client) {
+		c.setRateLimit("twse", twse)
+		c.setRateLimit("otc", otc)
+	}
+}
+
+// WithMOPSRateLimit 指定造訪公開資訊觀測站主機的最小間隔
+func WithMOPSRateLimit(interval time.Duration) ClientOption {
+	return func(c *Client) {
+		c.setRateLimit("mops", interval)
+	}
+}
+
+// WithTDCCRateLimit 指定造訪集保結算所開放資料主機的最小間隔
+func WithTDCCRateLimit(interval time.Duration) ClientOption {
+	return func(c *Client) {
+		c.setRateLimit("tdcc", interval)
+	}
+}
+
+func (c *Client) setRateLimit(key string, interval time.Duration) {
+	if c.rateLimits == nil {
+		c.rateLimits = make(map[string]time.Duration)
+	}
+	c.rateLimits[key] = interval
+}
+
+// rateLimit 回傳指定的間隔，未指定時為 seconds 秒
+func (c *Client) rateLimit(key string, seconds time.Duration) time.Duration {
+	if v, ok := c.rateLimits[key]; ok {
+		return v
+	}
+	return seconds * time.Second
+}
+
+// WithMaxConcurrency 指定 LoadRange 同時擷取的月份數，預設為 4
+func WithMaxConcurrency(n int) ClientOption {
+	return func(c *Client) {
+		c.concurrent = n
+	}
+}
+
+// WithFetcher 指定擷取資料的 Fetcher，
+// 指定後 WithCacheDir、WithEncoding、WithHTTPClient、WithRateLimit、WithMOPSRateLimit、WithTDCCRateLimit 不會生效
+func WithFetcher(f Fetcher) ClientOption {
+	return func(c *Client) {
+		c.fetcher = f
+	}
+}
+
+// WithCalendar 指定開休市表，預設為 tradingdays.DefaultCalendar
+func WithCalendar(calendar *tradingdays.Calendar) ClientOption {
+	return func(c *Client) {
+		c.calendar = calendar
+	}
+}
+
+// WithStore 指定本地歷史資料，Data 會先讀取 Store 再連線擷取
+func WithStore(store Store) ClientOption {
+	return func(c *Client) {
+		c.store = store
+	}
+}
+
+// WithStrict 指定是否使用嚴格模式解析資料
+//
+// 嚴格模式下欄位解析失敗時回傳 *ParseError；預設的寬鬆模式會略過或記錄該欄位，
+// 並記錄在資料集的 Report()。
+func WithStrict(strict bool) ClientOption {
+	return func(c *Client) {
+		c.strict = strict
+	}
+}
+
+// WithTransport 指定上市資料的傳輸格式，預設為 TransportCSV
+//
+// TransportJSON 時預設的快取不轉換編碼；有指定 WithFetcher 時由該 Fetcher 處理。
+func WithTransport(t Transport) ClientOption {
+	return func(c *Client) {
+		c.transport = t
+	}
+}
+
+// NewClient 建立一個 Client
+func NewClient(opts ...ClientOption) *Client {
+	c := &Client{
+		twseHost:   utils.TWSEHOST,
+		otcHost:    utils.OTCHOST,
+		mopsHost:   utils.MOPSHOST,
+		cacheDir:   utils.GetOSRamdiskPath(""),
+		encoding:   "cp950",
+		concurrent: 4,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.calendar == nil {
+		c.calendar = tradingdays.DefaultCalendar()
+	}
+	if c.fetcher == nil {
+		var limits map[string]*utils.RateLimit
+		if c.rateLimits != nil {
+			limits = map[string]*utils.RateLimit{
+				c.twseHost:     utils.NewRateLimit(c.rateLimit("twse", utils.TWSEDURTION)),
+				c.otcHost:      utils.NewRateLimit(c.rateLimit("otc", utils.OTCDURTION)),
+				c.mopsHost:     utils.NewRateLimit(c.rateLimit("mops", utils.MOPSDURTION)),
+				utils.TDCCHOST: utils.NewRateLimit(c.rateLimit("tdcc", utils.TDCCDURTION)),
+			}
+		}
+		c.fetcher = c.newHTTPCache(c.encoding, limits)
+		c.utf8Fetcher = c.newHTTPCache("utf8", limits)
+	}
+	return c
+}
+
+// newHTTPCache 依 Client 的設定建立 HTTPCache，同一個 Client 的 HTTPCache 共用存取間隔
+func (c *Client) newHTTPCache(encoding string, limits map[string]*utils.RateLimit) *utils.HTTPCache {
+	hc := utils.NewHTTPCache(c.cacheDir, encoding).SetHTTPClient(c.httpClient)
+	for host, r := range limits {
+		hc.SetRateLimiter(host, r)
+	}
+	return hc
+}
+
+var (
+	defaultClient     *Client
+	defaultClientOnce sync.Once
+)
+
+// DefaultClient 回傳 NewTWSE、NewLists 等套件層級建構函式所使用的 Client
+//
+// 第一次呼叫時才會建立快取資料夾。
+func DefaultClient() *Client {
+	defaultClientOnce.Do(func() {
+		defaultClient = NewClient()
+	})
+	return defaultClient
+}
+
+// use 回傳 c，c 為 nil 時回傳 DefaultClient
+func (c *Client) use() *Client {
+	if c == nil {
+		return DefaultClient()
+	}
+	return c
+}
+
+// fetch 回傳擷取資料的 Fetcher，f 不為 nil 時優先使用
+func (c *Client) fetch(f Fetcher) Fetcher {
+	if f != nil {
+		return f
+	}
+	return c.use().fetcher
+}
+
+// fetchUTF8 回傳擷取 UTF-8 資料的 Fetcher，f 不為 nil 時優先使用；
+// 有指定 WithFetcher 時由該 Fetcher 處理
+func (c *Client) fetchUTF8(f Fetcher) Fetcher {
+	if f != nil {
+		return f
+	}
+	var client = c.use()
+	if client.utf8Fetcher != nil {
+		return client.utf8Fetcher
+	}
+	return client.fetcher
+}
+
+// Fetcher 回傳 Client 擷取資料的 Fetcher
+func (c *Client) Fetcher() Fetcher {
+	return c.use().fetcher
+}
+
+// UTF8Fetcher 回傳 Client 擷取 UTF-8 資料（如公開資訊觀測站）的 Fetcher
+func (c *Client) UTF8Fetcher() Fetcher {
+	return c.fetchUTF8(nil)
+}
+
+// Calendar 回傳 Client 使用的開休市表
+func (c *Client) Calendar() *tradingdays.Calendar {
+	return c.use().calendar
+}
+
+// Store 回傳 Client 使用的本地歷史資料，未指定時為 nil
+func (c *Client) Store() Store {
+	return c.use().store
+}
+
+// Strict 回傳是否使用嚴格模式解析資料
+func (c *Client) Strict() bool {
+	return c.use().strict
+}
+
+// Transport 回傳上市資料的傳輸格式
+func (c *Client) Transport() Transport {
+	return c.use().transport
+}
+
+// TWSEHost 回傳上市資料主機
+func (c *Client) TWSEHost() string {
+	return c.use().twseHost
+}
+
+// OTCHost 回傳上櫃資料主機
+func (c *Client) OTCHost() string {
+	return c.use().otcHost
+}
+
+// MOPSHost 回傳公開資訊觀測站主機
+func (c *Client) MOPSHost() string {
+	return c.use().mopsHost
+}
+
+// maxConcurrency 回傳 LoadRange 同時擷取的月份數
+func (c *Client) maxConcurrency() int {
+	if n := c.use().concurrent; n > 0 {
+		return n
+	}
+	return 1
+}
+
+// NewTWSE 建立一個 TWSE 上市股票
+func (c *Client) NewTWSE(No string, Date time.Time) *Data {
+	d := NewTWSE(No, Date)
+	d.client = c
+	return d
+}
+
+// NewOTC 建立一個 OTC 上櫃股票
+func (c *Client) NewOTC(No string, Date time.Time) *Data {
+	d := NewOTC(No, Date)
+	d.client = c
+	return d
+}
+
+// NewLists 建立上市類股清單
+func (c *Client) NewLists(t time.Time) *Lists {
+	l := NewLists(t)
+	l.client = c
+	return l
+}
+
+// NewOTCLists 建立上櫃類股清單
+func (c *Client) NewOTCLists(date time.Time) *OTCLists {
+	o := NewOTCLists(date)
+	o.client = c
+	return o
+}
+
+// NewOddLots 建立上市零股每日行情
+func (c *Client) NewOddLots(date time.Time, session OddLotSession) *OddLots {
+	o := NewOddLots(date, session)
+	o.client = c
+	return o
+}
+
+// NewOTCOddLots 建立上櫃零股每日行情
+func (c *Client) NewOTCOddLots(date time.Time, session OddLotSession) *OddLots {
+	o := NewOTCOddLots(date, session)
+	o.client = c
+	return o
+}
+
+// NewValuation 建立上市個股每日本益比、殖利率及股價淨值比
+func (c *Client) NewValuation(date time.Time) *Valuation {
+	v := NewValuation(date)
+	v.client = c
+	return v
+}
+
+// NewOTCValuation 建立上櫃個股每日本益比、殖利率及股價淨值比
+func (c *Client) NewOTCValuation(date time.Time) *Valuation {
+	v := NewOTCValuation(date)
+	v.client = c
+	return v
+}
+
+// NewValuationHistory 建立上市個股本益比、殖利率及股價淨值比的歷史資料
+func (c *Client) NewValuationHistory(no string) *ValuationHistory {
+	h := NewValuationHistory(no)
+	h.client = c
+	return h
+}
+
+// NewOTCValuationHistory 建立上櫃個股本益比、殖利率及股價淨值比的歷史資料
+func (c *Client) NewOTCValuationHistory(no string) *ValuationHistory {
+	h := NewOTCValuationHistory(no)
+	h.client = c
+	return h
+}
+
+// NewRevenue 建立上市公司每月營業收入彙總表
+func (c *Client) NewRevenue(month time.Time) *Revenue {
+	r := NewRevenue(month)
+	r.client = c
+	return r
+}
+
+// NewOTCRevenue 建立上櫃公司每月營業收入彙總表
+func (c *Client) NewOTCRevenue(month time.Time) *Revenue {
+	r := NewOTCRevenue(month)
+	r.client = c
+	return r
+}
+
+// NewRevenueHistory 建立上市公司的營收歷史資料
+func (c *Client) NewRevenueHistory() *RevenueHistory {
+	h := NewRevenueHistory()
+	h.client = c
+	return h
+}
+
+// NewOTCRevenueHistory 建立上櫃公司的營收歷史資料
+func (c *Client) NewOTCRevenueHistory() *RevenueHistory {
+	h := NewOTCRevenueHistory()
+	h.client = c
+	return h
+}
+
+// NewTDCC 建立集保戶股權分散表
+func (c *Client) NewTDCC() *TDCC {
+	t := NewTDCC()
+	t.client = c
+	return t
+}
+
+// NewDayTrade 建立上市當日沖銷交易標的及成交量值
+func (c *Client) NewDayTrade(date time.Time) *DayTrade {
+	d := NewDayTrade(date)
+	d.client = c
+	return d
+}
+
+// NewOTCDayTrade 建立上櫃當日沖銷交易標的及成交量值
+func (c *Client) NewOTCDayTrade(date time.Time) *DayTrade {
+	d := NewOTCDayTrade(date)
+	d.client = c
+	return d
+}
+
+// NewDayTradeHistory 建立個股的當沖歷史資料
+func (c *Client) NewDayTradeHistory(stock *Data) *DayTradeHistory {
+	h := NewDayTradeHistory(stock)
+	h.client = c
+	return h
+}
+
+// NewQFIISTOP20 外資及陸資持股比率前二十名彙總表
+func (c *Client) NewQFIISTOP20(date time.Time) *QFIISTOP20 {
+	return &QFIISTOP20{Date: date, client: c}
+}
+
+// NewQFIIS 外資及陸資投資持股統計（全部個股）
+func (c *Client) NewQFIIS(date time.Time) *QFIIS {
+	q := NewQFIIS(date)
+	q.client = c
+	return q
+}
+
+// NewForeignHoldingHistory 建立外資及陸資持股的歷史資料
+func (c *Client) NewForeignHoldingHistory() *ForeignHoldingHistory {
+	h := NewForeignHoldingHistory()
+	h.client = c
+	return h
+}
+
+// NewBFI82U 三大法人買賣金額統計表
+func (c *Client) NewBFI82U(begin, end time.Time) *BFI82U {
+	b := NewBFI82U(begin, end)
+	b.client = c
+	return b
+}
+
+// NewT86 三大法人買賣超日報
+func (c *Client) NewT86(date time.Time) *T86 {
+	return &T86{Date: date, client: c}
+}
+
+// NewTWT38U 外資及陸資買賣超彙總表
+func (c *Client) NewTWT38U(date time.Time) *TWT38U {
+	t := NewTWT38U(date)
+	t.client = c
+	return t
+}
+
+// NewTWT43U 自營商買賣超彙總表
+func (c *Client) NewTWT43U(date time.Time) *TWT43U {
+	t := NewTWT43U(date)
+	t.client = c
+	return t
+}
+
+// NewTWT44U 投信買賣超彙總表
+func (c *Client) NewTWT44U(date time.Time) *TWT44U {
+	t := NewTWT44U(date)
+	t.client = c
+	return t
+}
+
+// NewTWMTSS 融資融券匯總
+func (c *Client) NewTWMTSS(date time.Time, category string) *TWMTSS {
+	t := NewTWMTSS(date, category)
+	t.client = c
+	return t
+}
+
+// NewOTCT86 上櫃三大法人買賣明細資訊
+func (c *Client) NewOTCT86(date time.Time) *T86 {
+	return &T86{Date: date, exchange: "otc", client: c}
+}
+
+// NewOTCTWT38U 上櫃外資及陸資買賣超
+func (c *Client) NewOTCTWT38U(date time.Time) *TWT38U {
+	t := NewOTCTWT38U(date)
+	t.client = c
+	return t
+}
+
+// NewOTCTWT43U 上櫃自營商買賣超
+func (c *Client) NewOTCTWT43U(date time.Time) *TWT43U {
+	t := NewOTCTWT43U(date)
+	t.client = c
+	return t
+}
+
+// NewOTCTWT44U 上櫃投信買賣超
+func (c *Client) NewOTCTWT44U(date time.Time) *TWT44U {
+	t := NewOTCTWT44U(date)
+	t.client = c
+	return t
+}
+
+// NewOTCTWMTSS 上櫃融資融券餘額
+func (c *Client) NewOTCTWMTSS(date time.Time) *TWMTSS {
+	t := NewOTCTWMTSS(date)
+	t.client = c
+	return t
+}
+
+// NewTWT93U 融券借券賣出餘額
+func (c *Client) NewTWT93U(date time.Time) *TWT93U {
+	t := NewTWT93U(date)
+	t.client = c
+	return t
+}
+
+// NewSBLHistory 建立融券與借券賣出餘額的歷史資料
+func (c *Client) NewSBLHistory() *SBLHistory {
+	h := NewSBLHistory()
+	h.client = c
+	return h
+}
+
+// NewTWT49U 上市除權除息計算結果表
+func (c *Client) NewTWT49U(begin, end time.Time) *TWT49U {
+	t := NewTWT49U(begin, end)
+	t.client = c
+	return t
+}
+
+// NewOTCExRight 上櫃除權除息計算結果表
+func (c *Client) NewOTCExRight(begin, end time.Time) *OTCExRight {
+	o := NewOTCExRight(begin, end)
+	o.client = c
+	return o
+}
+
+// Weight 擷取大盤成交資訊
+func (c *Client) Weight(date time.Time) []*WeightData {
+	result, _, _ := c.GetWeight(date)
+	return result
+}
+
+// GetWeight 擷取大盤成交資訊與驗證報告，嚴格模式下欄位解析失敗時回傳 *ParseError
+func (c *Client) GetWeight(date time.Time) ([]*WeightData, *Report, error) {
+	csvData, err := getWeight(c.Fetcher(), c.TWSEHost(), date)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseWeightCSV(csvData, c.Strict())
+}
@@ -0,0 +1,97 @@
+package twse
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/tradingdays"
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+func TestClient_localServer(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/exchangeReport/STOCK_DAY" || r.FormValue("stockNo") != "2618" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(fixtureSTOCKDAY))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "gogrs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := NewClient(
+		WithTWSEHost(ts.URL),
+		WithCacheDir(dir),
+		WithEncoding("utf8"),
+		WithRateLimit(0, 0),
+	)
+	d := c.NewTWSE("2618", time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone))
+	if !strings.HasPrefix(d.URL(), ts.URL) {
+		t.Errorf("Should start with %s but %s", ts.URL, d.URL())
+	}
+	if _, err := d.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Len() != 3 {
+		t.Errorf("Should be 3 but %d", d.Len())
+	}
+}
+
+func TestClient_mopsRateLimit(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.FormValue("season")))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "gogrs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		c     = NewClient(WithMOPSHost(ts.URL), WithCacheDir(dir), WithRateLimit(0, 0), WithMOPSRateLimit(200*time.Millisecond))
+		start = time.Now()
+	)
+	for _, season := range []string{"01", "02"} {
+		if _, err := c.UTF8Fetcher().PostForm(c.MOPSHost()+"/mops/web/t163sb04", url.Values{"season": {season}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d < 200*time.Millisecond {
+		t.Errorf("MOPS should be rate limited but %s", d)
+	}
+}
+
+func TestClient_isolated(t *testing.T) {
+	date := time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone)
+	calendar := tradingdays.NewCalendar()
+	calendar.LoadCSV(strings.NewReader(`"2015/3/4",0`), false)
+
+	c1 := NewClient(WithFetcher(memFetcher{}), WithTWSEHost("http://a.example"), WithCalendar(calendar))
+	c2 := NewClient(WithFetcher(memFetcher{}), WithTWSEHost("http://b.example"))
+
+	if c1.NewTWSE("2618", date).URL() == c2.NewTWSE("2618", date).URL() {
+		t.Error("Should use different host")
+	}
+	if c1.Calendar().IsOpen(2015, 3, 4) {
+		t.Error("Should be closed in c1")
+	}
+	if !c2.Calendar().IsOpen(2015, 3, 4) {
+		t.Error("Should be open in c2")
+	}
+	if c1.NewTWMTSS(date, "ALL").URL() != "http://a.example/exchangeReport/MI_MARGN?response=csv&date=20150304&selectType=ALL" {
+		t.Error("Wrong URL", c1.NewTWMTSS(date, "ALL").URL())
+	}
+}
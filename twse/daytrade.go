@@ -0,0 +1,200 @@
+package twse
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+// DayTradeData 個股當日沖銷交易，成交量單位為股
+type DayTradeData struct {
+	Date      time.Time `json:"date"`
+	No        string    `json:"no"`
+	Name      string    `json:"name"`
+	Suspended bool      `json:"suspended"`  // 暫停現股賣出後現款買進當沖
+	Volume    uint64    `json:"volume"`     // 當日沖銷交易成交股數
+	BuyValue  uint64    `json:"buy_value"`  // 當日沖銷交易買進成交金額
+	SellValue uint64    `json:"sell_value"` // 當日沖銷交易賣出成交金額
+	Ratio     float64   `json:"ratio"`      // 當沖成交股數占總成交股數(%)，以 SetVolume 計算
+}
+
+// SetVolume 以當日總成交股數（如 FmtData.Volume、FmtListData.Volume）計算當沖比率
+func (d *DayTradeData) SetVolume(volume uint64) *DayTradeData {
+	d.Ratio = 0
+	if volume > 0 {
+		d.Ratio = round(float64(d.Volume) / float64(volume) * 100)
+	}
+	return d
+}
+
+// dayTradeSchema 上市（TWTB4U）與上櫃當日沖銷交易標的的欄位
+var dayTradeSchema = []column{
+	col("No", "證券代號", "代號"),
+	col("Name", "證券名稱", "名稱"),
+	optCol("Suspended", "暫停現股賣出後現款買進當沖註記"),
+	col("Volume", "當日沖銷交易成交股數", "成交股數"),
+	col("BuyValue", "當日沖銷交易買進成交金額", "買進成交金額"),
+	col("SellValue", "當日沖銷交易賣出成交金額", "賣出成交金額"),
+}
+
+// DayTrade 上市/上櫃當日沖銷交易標的及成交量值
+type DayTrade struct {
+	Date     time.Time
+	FmtData  map[string]DayTradeData
+	exchange string
+	report   *Report
+	fetcher  Fetcher
+	client   *Client
+}
+
+// NewDayTrade 建立上市當日沖銷交易標的及成交量值
+func NewDayTrade(date time.Time) *DayTrade {
+	return &DayTrade{Date: date, FmtData: make(map[string]DayTradeData), exchange: "tse"}
+}
+
+// NewOTCDayTrade 建立上櫃當日沖銷交易標的及成交量值
+func NewOTCDayTrade(date time.Time) *DayTrade {
+	return &DayTrade{Date: date, FmtData: make(map[string]DayTradeData), exchange: "otc"}
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (d *DayTrade) SetFetcher(f Fetcher) *DayTrade {
+	d.fetcher = f
+	return d
+}
+
+// URL 擷取網址
+func (d DayTrade) URL() string {
+	switch d.exchange {
+	case "tse":
+		return d.client.twseURL(fmt.Sprintf(utils.TWTB4U, d.Date.Year(), d.Date.Month(), d.Date.Day()))
+	case "otc":
+		return fmt.Sprintf("%s%s", d.client.OTCHost(), fmt.Sprintf(utils.OTCDAYTRADE,
+			fmt.Sprintf("%d/%02d/%02d", d.Date.Year()-1911, d.Date.Month(), d.Date.Day())))
+	}
+	return ""
+}
+
+// Get 擷取當日全部當沖標的的成交量值，欄位解析失敗時記錄在 Report()
+func (d *DayTrade) Get() (map[string]DayTradeData, error) {
+	var s = schema{name: fmt.Sprintf("%s day trade %s", d.exchange, d.Date.Format("20060102")), columns: dayTradeSchema}
+	t, err := d.client.fetchTable(d.fetcher, d.exchange, d.URL(), s)
+	if err != nil {
+		return nil, err
+	}
+
+	var p = newParser(s.name, d.client.Strict())
+	d.report = p.report
+	for _, r := range t.records {
+		p.next()
+		var v = DayTradeData{
+			Date:      d.Date,
+			No:        r.get("No"),
+			Name:      r.get("Name"),
+			Suspended: strings.ToUpper(r.get("Suspended")) == "Y",
+			Volume:    p.uint("Volume", r.get("Volume")),
+			BuyValue:  p.uint("BuyValue", r.get("BuyValue")),
+			SellValue: p.uint("SellValue", r.get("SellValue")),
+		}
+		if err := p.err(); err != nil {
+			return nil, err
+		}
+		d.FmtData[v.No] = v
+	}
+	return d.FmtData, nil
+}
+
+// SetVolumes 以上市/上櫃股票列表（Lists.FmtData、OTCLists.FmtData）的成交股數計算當沖比率
+func (d *DayTrade) SetVolumes(lists map[string]FmtListData) *DayTrade {
+	for no, v := range d.FmtData {
+		if l, ok := lists[no]; ok {
+			v.SetVolume(l.Volume)
+			d.FmtData[no] = v
+		}
+	}
+	return d
+}
+
+// Report 回傳最近一次 Get 的驗證報告
+func (d DayTrade) Report() *Report {
+	return d.report
+}
+
+// DayTradeHistory 個股每日的當沖資料，以 Stock 的每日成交股數計算當沖比率
+//
+// 上櫃每日資料的成交量單位為千股，計算時換算為股。
+type DayTradeHistory struct {
+	Stock   *Data
+	Data    []DayTradeData // 依日期排序，非當沖標的的日期不會列出
+	report  *Report
+	fetcher Fetcher
+	client  *Client
+}
+
+// NewDayTradeHistory 建立個股的當沖歷史資料，stock 為同一個 Client 建立的上市或上櫃股票
+func NewDayTradeHistory(stock *Data) *DayTradeHistory {
+	return &DayTradeHistory{Stock: stock, client: stock.client}
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (h *DayTradeHistory) SetFetcher(f Fetcher) *DayTradeHistory {
+	h.fetcher = f
+	return h
+}
+
+// LoadRange 擷取 from 到 to 之間每個開市日的當沖資料，並以 Stock.LoadRange 的成交股數計算當沖比率；
+// 沒有資料的日期、月份（如停牌）略過
+func (h *DayTradeHistory) LoadRange(from, to time.Time) error {
+	if err := h.Stock.LoadRange(from, to); err != nil {
+		if loadErr, ok := err.(*LoadError); !ok || !loadErr.NoData() {
+			return err
+		}
+	}
+	var volumes = make(map[int64]uint64)
+	for _, v := range h.Stock.FormatData() {
+		if h.Stock.exchange == "otc" {
+			v.Volume *= 1000
+		}
+		volumes[v.Date.Unix()] = v.Volume
+	}
+
+	var (
+		report = &Report{Dataset: fmt.Sprintf("%s day trade %s", h.Stock.exchange, h.Stock.No)}
+		result []DayTradeData
+	)
+	err := loadEach(openDays(h.client.Calendar(), from, to), nil, func(day time.Time) error {
+		d := &DayTrade{Date: day, FmtData: make(map[string]DayTradeData), exchange: h.Stock.exchange, fetcher: h.fetcher, client: h.client}
+		data, err := d.Get()
+		if err != nil {
+			return err
+		}
+		report.merge(d.Report())
+		if v, ok := data[h.Stock.No]; ok {
+			v.SetVolume(volumes[day.Unix()])
+			result = append(result, v)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	h.Data = result
+	h.report = report
+	return nil
+}
+
+// Ratios 每日的當沖比率(%)，依日期排序
+func (h DayTradeHistory) Ratios() []float64 {
+	var result = make([]float64, len(h.Data))
+	for i, v := range h.Data {
+		result[i] = v.Ratio
+	}
+	return result
+}
+
+// Report 回傳最近一次 LoadRange 的驗證報告
+func (h DayTradeHistory) Report() *Report {
+	return h.report
+}
@@ -0,0 +1,67 @@
+package twse
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+func twtb4UFixture(date, volume string) string {
+	return fmt.Sprintf(`"%s 當日沖銷交易標的及成交量值"
+"當日沖銷交易總成交股數","當日沖銷交易總成交股數占市場比重%%","當日沖銷交易總買進成交金額","當日沖銷交易總買進成交金額占市場比重%%",
+"300,000,000","12.50","9,000,000,000","10.20",
+"證券代號","證券名稱","暫停現股賣出後現款買進當沖註記","當日沖銷交易成交股數","當日沖銷交易買進成交金額","當日沖銷交易賣出成交金額",
+"2618","長榮航","","%s","91,600","92,100",
+"2330","台積電","Y","1,000","183,000","184,000",
+"說明:"
+`, date, volume)
+}
+
+func TestDayTradeHistory_LoadRange(t *testing.T) {
+	var (
+		url = func(day int) string {
+			return fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.TWTB4U, 2015, 3, day))
+		}
+		f = dayFetcher(url, map[int]string{
+			2: twtb4UFixture("104年03月02日", "4,015,313"),
+			3: twtb4UFixture("104年03月03日", "1,006,120"),
+			4: fixtureNoData,
+		})
+		client = NewClient(WithFetcher(f))
+		h      = client.NewDayTradeHistory(client.NewTWSE("2618", time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone)))
+	)
+	f[fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.TWSECSV, 2015, 3, 4, "2618"))] = fixtureSTOCKDAY
+	if err := h.LoadRange(time.Date(2015, 3, 2, 0, 0, 0, 0, utils.TaipeiTimeZone), time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone)); err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Data) != 2 || h.Data[0].Volume != 4015313 || h.Data[0].BuyValue != 91600 || h.Data[0].Suspended {
+		t.Fatalf("Wrong data: %+v", h.Data)
+	}
+	if ratios := h.Ratios(); ratios[0] != 30 || ratios[1] != 10 {
+		t.Errorf("Wrong ratios: %v", ratios)
+	}
+
+	// 停牌的月份沒有每日資料，仍保留其他月份的當沖資料
+	f[fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.TWSECSV, 2015, 2, 1, "2618"))] = fixtureNoData
+	for day := 23; day <= 27; day++ {
+		f[fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.TWTB4U, 2015, 2, day))] = fixtureNoData
+	}
+	h = client.NewDayTradeHistory(client.NewTWSE("2618", time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone)))
+	if err := h.LoadRange(time.Date(2015, 2, 23, 0, 0, 0, 0, utils.TaipeiTimeZone), time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone)); err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Data) != 2 || h.Data[1].Volume != 1006120 {
+		t.Errorf("Wrong data: %+v", h.Data)
+	}
+
+	d := client.NewDayTrade(time.Date(2015, 3, 2, 0, 0, 0, 0, utils.TaipeiTimeZone))
+	if _, err := d.Get(); err != nil {
+		t.Fatal(err)
+	}
+	d.SetVolumes(map[string]FmtListData{"2330": {Volume: 4000}})
+	if v := d.FmtData["2330"]; !v.Suspended || v.Ratio != 25 || d.FmtData["2618"].Ratio != 0 {
+		t.Errorf("Wrong data: %+v", d.FmtData)
+	}
+}
@@ -1,5 +1,5 @@
 // Package twse - Fetch stock data from TWSE, OTC
-// 擷取台灣股市上市/上櫃股票資訊、上市/上櫃類股清單、外資及陸資持股比率前二十名彙總表、
-// 三大法人買賣金額統計表、三大法人買賣超日報、自營商、投信、外資及陸資買賣超彙總表
+// 擷取台灣股市上市/上櫃股票資訊、上市/上櫃類股清單、零股行情、個股本益比、殖利率及股價淨值比、每月營業收入、集保戶股權分散表、融券借券賣出餘額、當日沖銷交易、外資及陸資投資持股統計、外資及陸資持股比率前二十名彙總表、
+// 三大法人買賣金額統計表、上市/上櫃三大法人買賣超日報、自營商、投信、外資及陸資買賣超彙總表、融資融券
 //
 package twse
@@ -0,0 +1,306 @@
+package twse
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+	"github.com/pkg/errors"
+)
+
+// ExRight 除權除息計算結果
+type ExRight struct {
+	Date     time.Time // 除權息日期
+	No       string
+	Name     string
+	PreClose float64 // 除權息前收盤價
+	RefPrice float64 // 除權息參考價
+	Value    float64 // 權值+息值
+	Kind     string  // 權/息
+}
+
+// Factor 還原權值的調整係數（參考價 / 前收盤價）
+func (e ExRight) Factor() float64 {
+	if e.PreClose <= 0 || e.RefPrice <= 0 {
+		return 1
+	}
+	return e.RefPrice / e.PreClose
+}
+
+// exRightSchema 除權除息計算結果表的欄位，上市與上櫃的標題不同
+var exRightSchema = []column{
+	col("Date", "資料日期", "除權息日期"),
+	col("No", "股票代號", "代號"),
+	col("Name", "股票名稱", "名稱"),
+	col("PreClose", "除權息前收盤價"),
+	col("RefPrice", "除權息參考價"),
+	col("Value", "權值+息值"),
+	col("Kind", "權/息"),
+}
+
+// TWT49U 取得上市「除權除息計算結果表」
+type TWT49U struct {
+	Begin   time.Time
+	End     time.Time
+	report  *Report
+	fetcher Fetcher
+	client  *Client
+}
+
+// NewTWT49U 上市除權除息計算結果表
+func NewTWT49U(begin, end time.Time) *TWT49U {
+	return &TWT49U{Begin: begin, End: end}
+}
+
+// URL 擷取網址
+func (t TWT49U) URL() string {
+	return fmt.Sprintf("%s%s", t.client.TWSEHost(),
+		fmt.Sprintf(utils.TWT49U, t.Begin.Year(), t.Begin.Month(), t.Begin.Day(), t.End.Year(), t.End.Month(), t.End.Day()))
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (t *TWT49U) SetFetcher(f Fetcher) *TWT49U {
+	t.fetcher = f
+	return t
+}
+
+// Get 擷取資料，欄位解析失敗的資料列略過並記錄在 Report()
+func (t *TWT49U) Get() ([]ExRight, error) {
+	data, err := t.client.fetch(t.fetcher).PostForm(t.URL(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var p = newParser("TWT49U "+t.Begin.Format("20060102"), t.client.Strict())
+	t.report = p.report
+	return parseExRight(data, p)
+}
+
+// Report 回傳最近一次 Get 的驗證報告
+func (t TWT49U) Report() *Report {
+	return t.report
+}
+
+// OTCExRight 取得上櫃「除權除息計算結果表」
+type OTCExRight struct {
+	Begin   time.Time
+	End     time.Time
+	report  *Report
+	fetcher Fetcher
+	client  *Client
+}
+
+// NewOTCExRight 上櫃除權除息計算結果表
+func NewOTCExRight(begin, end time.Time) *OTCExRight {
+	return &OTCExRight{Begin: begin, End: end}
+}
+
+// URL 擷取網址
+func (o OTCExRight) URL() string {
+	return fmt.Sprintf("%s%s", o.client.OTCHost(),
+		fmt.Sprintf(utils.OTCEXRIGHT, o.Begin.Year()-1911, o.Begin.Month(), o.Begin.Day(), o.End.Year()-1911, o.End.Month(), o.End.Day()))
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (o *OTCExRight) SetFetcher(f Fetcher) *OTCExRight {
+	o.fetcher = f
+	return o
+}
+
+// Get 擷取資料，欄位解析失敗的資料列略過並記錄在 Report()
+func (o *OTCExRight) Get() ([]ExRight, error) {
+	data, err := o.client.fetch(o.fetcher).Get(o.URL(), false)
+	if err != nil {
+		return nil, err
+	}
+	var p = newParser("otc exright "+o.Begin.Format("20060102"), o.client.Strict())
+	o.report = p.report
+	return parseExRight(data, p)
+}
+
+// Report 回傳最近一次 Get 的驗證報告
+func (o OTCExRight) Report() *Report {
+	return o.report
+}
+
+// parseExRight 依表頭解析除權除息計算結果表，沒有資料時回傳 errorFileNoData；
+// 日期或價格無法解析的資料列略過，避免以錯誤的係數還原權值
+func parseExRight(data []byte, p *parser) ([]ExRight, error) {
+	table, err := schema{name: p.report.Dataset, columns: exRightSchema}.parse(data)
+	if errors.Cause(err) == errorNotEnoughData {
+		return nil, errorFileNoData
+	} else if err != nil {
+		return nil, err
+	}
+	var result = make([]ExRight, 0, len(table.records))
+	for _, r := range table.records {
+		p.next()
+		var e = ExRight{
+			Date:     utils.ParseDate(r.get("Date")),
+			No:       r.get("No"),
+			Name:     r.get("Name"),
+			PreClose: p.float("PreClose", r.get("PreClose")),
+			RefPrice: p.float("RefPrice", r.get("RefPrice")),
+			Value:    p.optFloat("Value", r.get("Value")),
+			Kind:     r.get("Kind"),
+		}
+		if e.Date.IsZero() {
+			p.fail("Date", r.get("Date"), errDate)
+		}
+		if err := p.err(); err != nil {
+			return nil, err
+		}
+		if p.bad {
+			p.skip()
+			continue
+		}
+		result = append(result, e)
+	}
+	if len(result) == 0 {
+		return nil, errorFileNoData
+	}
+	return result, nil
+}
+
+// SetExRights 指定除權除息資料，只保留相同股票代號的資料
+//
+// 指定後不會再自動擷取除權除息計算結果表。
+func (d *Data) SetExRights(list []ExRight) *Data {
+	d.exRights = make([]ExRight, 0)
+	for _, v := range list {
+		if v.No == d.No {
+			d.exRights = append(d.exRights, v)
+		}
+	}
+	sort.Slice(d.exRights, func(i, j int) bool { return d.exRights[i].Date.Before(d.exRights[j].Date) })
+	d.exRightsFrom = time.Time{}
+	d.exRightsTo = time.Time{}
+	d.exRightsManual = true
+	d.clearCache()
+	return d
+}
+
+// LoadExRights 依上市、上櫃擷取 from ~ to 的除權除息計算結果表
+func (d *Data) LoadExRights(from, to time.Time) error {
+	var (
+		list []ExRight
+		err  error
+	)
+	switch d.exchange {
+	case "tse":
+		list, err = d.client.NewTWT49U(from, to).SetFetcher(d.fetcher).Get()
+	case "otc":
+		list, err = d.client.NewOTCExRight(from, to).SetFetcher(d.fetcher).Get()
+	}
+	if err != nil && err != errorFileNoData {
+		return err
+	}
+	d.SetExRights(list)
+	d.exRightsFrom = from
+	d.exRightsTo = to
+	d.exRightsManual = false
+	d.exRightsErr = nil
+	return nil
+}
+
+// SetAdjusted 設定 MA、MABR 是否使用還原權值的收盤價
+func (d *Data) SetAdjusted(adjusted bool) *Data {
+	d.adjusted = adjusted
+	return d
+}
+
+// ExRightsErr 最近一次自動擷取除權除息資料的錯誤，發生錯誤時還原權值的價格未經調整；
+// 失敗後不會重複擷取，直到資料範圍改變或呼叫 LoadExRights
+func (d *Data) ExRightsErr() error {
+	return d.exRightsErr
+}
+
+// ensureExRights 資料範圍超出已擷取的除權除息資料時重新擷取，錯誤記錄在 ExRightsErr()
+func (d *Data) ensureExRights() error {
+	if d.exRightsManual || d.Len() == 0 {
+		return nil
+	}
+	var dates = d.GetDateList()
+	from, to := dates[0], dates[len(dates)-1]
+	if d.exRights != nil && !from.Before(d.exRightsFrom) && !to.After(d.exRightsTo) {
+		return nil
+	}
+	d.exRightsErr = d.LoadExRights(from, to)
+	return d.exRightsErr
+}
+
+// getAdjustFactors 計算每一日的還原係數，為之後（不含當日）所有除權息係數的乘積；
+// 擷取除權除息資料失敗時係數皆為 1，錯誤記錄在 ExRightsErr()
+func (d *Data) getAdjustFactors() []float64 {
+	if d.adjustFactors == nil {
+		if err := d.ensureExRights(); err != nil {
+			d.adjustFactors = make([]float64, len(d.GetDateList()))
+			for i := range d.adjustFactors {
+				d.adjustFactors[i] = 1
+			}
+			return d.adjustFactors
+		}
+		var (
+			dates   = d.GetDateList()
+			factors = make([]float64, len(dates))
+			factor  = 1.0
+			j       = len(d.exRights) - 1
+		)
+		if len(dates) > 0 {
+			last := dateKey(dates[len(dates)-1])
+			for j >= 0 && dateKey(d.exRights[j].Date) > last {
+				j--
+			}
+		}
+		for i := len(dates) - 1; i >= 0; i-- {
+			// 除權息日無交易（如停牌）仍需調整之前的資料
+			for j >= 0 && dateKey(d.exRights[j].Date) > dateKey(dates[i]) {
+				factor *= d.exRights[j].Factor()
+				j--
+			}
+			factors[i] = factor
+		}
+		d.adjustFactors = factors
+	}
+	return d.adjustFactors
+}
+
+// adjustFloat64 依還原係數調整價格序列
+func (d *Data) adjustFloat64(list []float64) []float64 {
+	var (
+		factors = d.getAdjustFactors()
+		result  = make([]float64, len(list))
+	)
+	for i, v := range list {
+		result[i] = v * factors[i]
+	}
+	return result
+}
+
+// GetAdjustedPriceList 取得還原權值的 收盤價 序列
+func (d *Data) GetAdjustedPriceList() []float64 {
+	if d.adjPriceList == nil {
+		d.adjPriceList = d.adjustFloat64(d.GetPriceList())
+	}
+	return d.adjPriceList
+}
+
+// FormatAdjustedData 還原權值的每日資料，開高低收與漲跌價差乘上還原係數，成交股數除以還原係數
+func (d *Data) FormatAdjustedData() []FmtData {
+	var (
+		result  = d.FormatData()
+		factors = d.getAdjustFactors()
+	)
+	for i := range result {
+		if result[i].Date.IsZero() {
+			continue
+		}
+		result[i].Open *= factors[i]
+		result[i].High *= factors[i]
+		result[i].Low *= factors[i]
+		result[i].Price *= factors[i]
+		result[i].Range *= factors[i]
+		result[i].Volume = uint64(float64(result[i].Volume)/factors[i] + 0.5)
+	}
+	return result
+}
@@ -0,0 +1,164 @@
+package twse
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+const fixtureTWT49U = `"104年03月02日至104年03月04日 除權除息計算結果表"
+"資料日期","股票代號","股票名稱","除權息前收盤價","除權息參考價","權值+息值","權/息","漲停價格","跌停價格","開盤競價基準","減除股利參考價","詳細資料","最近一次申報資料 季別/日期","最近一次申報每股 (單位)淨值","最近一次申報每股 (單位)盈餘",
+"104年03月03日","2618","長榮航","22.90","20.61","2.29","權","22.05","19.17","20.61","20.61","","","","",
+"104年03月03日","2330","台積電","143.00","140.00","3.00","息","149.50","130.50","140.00","140.00","","","","",
+"說明:"
+`
+
+func TestData_FormatAdjustedData(t *testing.T) {
+	var (
+		date  = time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		begin = time.Date(2015, 3, 2, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		d     = NewTWSE("2618", date)
+	)
+	d.SetFetcher(memFetcher{
+		d.URL(): fixtureSTOCKDAY,
+		fmt.Sprintf("%s/exchangeReport/TWT49U?response=csv&strDate=20150302&endDate=20150304", utils.TWSEHOST): fixtureTWT49U,
+	})
+	if _, err := d.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.LoadExRights(begin, date); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.exRights) != 1 || d.exRights[0].Kind != "權" {
+		t.Fatalf("Should be 1 `權` but %+v", d.exRights)
+	}
+
+	price := d.GetAdjustedPriceList()
+	if fmt.Sprintf("%.2f", price[0]) != "20.61" || price[1] != 22.90 || price[2] != 23.40 {
+		t.Errorf("Adjusted price wrong: %v", price)
+	}
+	if raw := d.GetPriceList(); raw[0] != 22.90 {
+		t.Errorf("Raw price should not be changed: %v", raw)
+	}
+
+	data := d.FormatAdjustedData()
+	if data[0].Volume != 14871531 {
+		t.Errorf("Adjusted volume wrong: %d", data[0].Volume)
+	}
+	if fmt.Sprintf("%.2f", data[0].Open) != "20.70" {
+		t.Errorf("Adjusted open wrong: %v", data[0].Open)
+	}
+
+	if ma := d.MA(2); ma[0] < 22 {
+		t.Errorf("MA should use raw price: %v", ma)
+	}
+	if ma := d.SetAdjusted(true).MA(2); ma[0] != 21.75 {
+		t.Errorf("MA should use adjusted price: %v", ma)
+	}
+}
+
+func TestData_SetExRights(t *testing.T) {
+	var d = NewTWSE("2618", time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone))
+	d.SetFetcher(memFetcher{d.URL(): fixtureSTOCKDAY})
+	if _, err := d.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 除權息日在資料範圍之後不調整
+	d.SetExRights([]ExRight{
+		{Date: time.Date(2015, 3, 5, 0, 0, 0, 0, utils.TaipeiTimeZone), No: "2618", PreClose: 23.40, RefPrice: 11.70},
+		{Date: time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone), No: "2618", PreClose: 22.90, RefPrice: 11.45},
+		{Date: time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone), No: "2330", PreClose: 143, RefPrice: 140},
+	})
+	price := d.GetAdjustedPriceList()
+	if price[0] != 11.45 || price[1] != 11.45 || price[2] != 23.40 {
+		t.Errorf("Adjusted price wrong: %v", price)
+	}
+}
+
+func TestData_ensureExRights(t *testing.T) {
+	var (
+		d     = NewTWSE("2618", time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone)).SetAdjusted(true)
+		twt49 = fmt.Sprintf("%s/exchangeReport/TWT49U?response=csv&strDate=20150302&endDate=20150304", utils.TWSEHOST)
+		f     = &countFetcher{memFetcher: memFetcher{d.URL(): fixtureSTOCKDAY}}
+	)
+	d.SetFetcher(f)
+	if _, err := d.Get(); err != nil {
+		t.Fatal(err)
+	}
+	f.count = 0
+
+	if ma := d.MA(2); ma[0] != 22.90 || d.ExRightsErr() == nil {
+		t.Errorf("Should be unadjusted with error but %v %v", ma, d.ExRightsErr())
+	}
+
+	f.memFetcher[twt49] = fixtureTWT49U
+	d.MA(2)
+	d.MABR(1, 2)
+	if ma := d.MA(2); ma[0] != 22.90 || d.ExRightsErr() == nil || f.count != 1 {
+		t.Errorf("Failed fetch should be cached but %v %v %d", ma, d.ExRightsErr(), f.count)
+	}
+
+	if err := d.LoadExRights(d.GetDateList()[0], d.Date); err != nil {
+		t.Fatal(err)
+	}
+	if ma := d.MA(2); ma[0] != 21.75 || d.ExRightsErr() != nil {
+		t.Errorf("Should be adjusted but %v %v", ma, d.ExRightsErr())
+	}
+	if f.count != 2 {
+		t.Errorf("TWT49U should be fetched once after LoadExRights but %d", f.count)
+	}
+}
+
+func TestTWT49U_badRow(t *testing.T) {
+	var (
+		begin  = time.Date(2015, 3, 2, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		end    = time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		url    = fmt.Sprintf("%s/exchangeReport/TWT49U?response=csv&strDate=20150302&endDate=20150304", utils.TWSEHOST)
+		data   = strings.Replace(fixtureTWT49U, `"143.00","140.00"`, `"143.00","--"`, 1)
+		client = NewClient(WithFetcher(memFetcher{url: data}))
+		w      = client.NewTWT49U(begin, end)
+	)
+	list, err := w.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].No != "2618" || list[0].Factor() != 20.61/22.90 {
+		t.Errorf("Bad row should be skipped but %+v", list)
+	}
+	if r := w.Report(); r.Rows != 2 || r.Skipped != 1 || len(r.Errors) != 1 || r.Errors[0].Column != "RefPrice" {
+		t.Errorf("Wrong report: %s %v", r, r.Errors)
+	}
+
+	client = NewClient(WithStrict(true), WithFetcher(memFetcher{url: data}))
+	if _, err := client.NewTWT49U(begin, end).Get(); err == nil {
+		t.Error("Should be error in strict mode")
+	}
+}
+
+func TestOTCExRight_Get(t *testing.T) {
+	var (
+		begin  = time.Date(2015, 3, 2, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		end    = time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		client = NewClient()
+		o      = client.NewOTCExRight(begin, end)
+	)
+	o.SetFetcher(memFetcher{o.URL(): `"除權除息計算結果表"
+"除權息日期","代號","名稱","除權息前收盤價","除權息參考價","權值","息值","權值+息值","權/息","漲停價格","跌停價格"
+"104/03/03","8446","華研","120.00","117.00","0.00","3.00","3.00","息","128.50","105.50"
+"共1筆"
+`})
+	list, err := o.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].No != "8446" || list[0].Value != 3 || list[0].Kind != "息" || list[0].Date.Day() != 3 {
+		t.Errorf("Wrong data: %+v", list)
+	}
+	if _, err := client.NewOTCExRight(begin, end).SetFetcher(memFetcher{o.URL(): "共0筆"}).Get(); err != errorFileNoData {
+		t.Errorf("Should be errorFileNoData but %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package twse
+
+import "net/url"
+
+// Fetcher 擷取原始資料的介面，預設為 utils.HTTPCache
+//
+// 可替換成其他快取位置或記憶體中的測試資料。
+type Fetcher interface {
+	Get(url string, rand bool) ([]byte, error)
+	PostForm(url string, data url.Values) ([]byte, error)
+}
+
+// cacheRemover 可移除快取的 Fetcher（如 utils.HTTPCache）
+type cacheRemover interface {
+	Remove(url string) error
+}
+
+// removeCache 移除 url 對應的快取，Fetcher 不支援時略過
+func removeCache(f Fetcher, url string) error {
+	if r, ok := f.(cacheRemover); ok {
+		return r.Remove(url)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package twse
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+// memFetcher 以網址對應記憶體中的測試資料
+type memFetcher map[string]string
+
+func (m memFetcher) Get(url string, rand bool) ([]byte, error) {
+	if v, ok := m[url]; ok {
+		return []byte(v), nil
+	}
+	return nil, fmt.Errorf("no fixture: %s", url)
+}
+
+func (m memFetcher) PostForm(url string, data url.Values) ([]byte, error) {
+	return m.Get(url, false)
+}
+
+// fixtureNoData 查無資料的回應
+const fixtureNoData = `"很抱歉，沒有符合條件的資料!"`
+
+// dayFetcher 以 url(day) 對應每日的測試資料，fixtures 的 key 為日
+func dayFetcher(url func(day int) string, fixtures map[int]string) memFetcher {
+	var m = make(memFetcher, len(fixtures))
+	for day, v := range fixtures {
+		m[url(day)] = v
+	}
+	return m
+}
+
+const fixtureSTOCKDAY = `"104年03月 2618 長榮航           各日成交資訊"
+"日期","成交股數","成交金額","開盤價","最高價","最低價","收盤價","漲跌價差","成交筆數",
+"104/03/02","13,384,378","305,046,992","23.00","23.05","22.50","22.90","-0.10","3,793",
+"104/03/03","10,061,202","229,871,452","22.90","23.05","22.75","22.90","0.00","2,893",
+"104/03/04","19,225,398","447,870,545","22.90","23.50","22.90","23.40","+0.50","5,148",
+"說明:"
+`
+
+func TestData_SetFetcher(t *testing.T) {
+	var d = NewTWSE("2618", time.Date(2015, 3, 3, 0, 0, 0, 0, utils.TaipeiTimeZone))
+	d.SetFetcher(memFetcher{d.URL(): fixtureSTOCKDAY})
+
+	if _, err := d.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Name != "長榮航" {
+		t.Errorf("Should be `長榮航` but `%s`", d.Name)
+	}
+	// 只取到 BackupDate 為止
+	if d.Len() != 2 {
+		t.Errorf("Should be 2 but %d", d.Len())
+	}
+	if price := d.GetPriceList(); price[len(price)-1] != 22.90 {
+		t.Errorf("Should be 22.90 but %v", price)
+	}
+}
+
+func TestData_SetFetcher_notEnoughData(t *testing.T) {
+	var d = NewTWSE("2618", time.Date(2015, 3, 3, 0, 0, 0, 0, utils.TaipeiTimeZone))
+	d.SetFetcher(memFetcher{d.URL(): ""})
+
+	if _, err := d.Get(); err == nil {
+		t.Error("Should be error")
+	}
+}
+
+func TestWeightFrom(t *testing.T) {
+	var date = time.Date(2017, 3, 5, 0, 0, 0, 0, utils.TaipeiTimeZone)
+	var f = memFetcher{
+		fmt.Sprintf("%s/exchangeReport/FMTQIK?response=csv&date=20170305", utils.TWSEHOST): `"106年03月市場成交資訊"
+"日期","成交股數","成交金額","成交筆數","發行量加權股價指數","漲跌點數",
+"106/03/01","4,352,913,007","99,915,928,382","981,872","9,674.78","-75.69",
+"106/03/02","4,226,390,493","97,384,946,604","916,584","9,647.61","-27.17",
+`}
+
+	result := WeightFrom(f, date)
+	if len(result) != 2 {
+		t.Fatalf("Should be 2 but %d", len(result))
+	}
+	for _, v := range result {
+		if v.Date.Equal(time.Date(2017, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone)) && v.Point != 9674.78 {
+			t.Errorf("Should be 9674.78 but %v", v.Point)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package twse
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+// ForeignHolding 個股外資及陸資投資持股統計，股數單位為股，比率單位為 %
+type ForeignHolding struct {
+	Date           time.Time `json:"date"`
+	No             string    `json:"no"`
+	Name           string    `json:"name"`
+	Issued         uint64    `json:"issued"`          // 發行股數
+	Available      uint64    `json:"available"`       // 外資及陸資尚可投資股數
+	Held           uint64    `json:"held"`            // 全體外資及陸資持有股數
+	AvailableRatio float64   `json:"available_ratio"` // 外資及陸資尚可投資比率
+	Ratio          float64   `json:"ratio"`           // 全體外資及陸資持股比率
+	Limit          float64   `json:"limit"`           // 外資及陸資共用法令投資上限比率
+	ChinaLimit     float64   `json:"china_limit"`     // 陸資法令投資上限比率
+	Reason         string    `json:"reason"`          // 與前日異動原因
+}
+
+// Room 距離投資上限的比率（百分點）
+func (f ForeignHolding) Room() float64 {
+	return round(f.Limit - f.Ratio)
+}
+
+// NearLimit 持股比率距離投資上限在 points 個百分點以內
+func (f ForeignHolding) NearLimit(points float64) bool {
+	return f.Limit > 0 && f.Room() <= points
+}
+
+// qfiisSchema 外資及陸資投資持股統計（MI_QFIIS）的欄位
+var qfiisSchema = []column{
+	col("No", "證券代號"),
+	col("Name", "證券名稱"),
+	col("Issued", "發行股數"),
+	col("Available", "外資及陸資尚可投資股數", "外資尚可投資股數"),
+	col("Held", "全體外資及陸資持有股數", "全體外資持有股數"),
+	col("AvailableRatio", "外資及陸資尚可投資比率", "外資尚可投資比率"),
+	col("Ratio", "全體外資及陸資持股比率", "全體外資持股比率"),
+	col("Limit", "外資及陸資共用法令投資上限比率", "法令投資上限比率"),
+	optCol("ChinaLimit", "陸資法令投資上限比率"),
+	optCol("Reason", "與前日異動原因(註)", "與前日異動原因"),
+}
+
+// foreignHolding 解析 qfiisSchema 的一列
+func foreignHolding(p *parser, r record, date time.Time) ForeignHolding {
+	return ForeignHolding{
+		Date:           date,
+		No:             strings.TrimSpace(r.get("No")),
+		Name:           strings.TrimSpace(r.get("Name")),
+		Issued:         p.uint("Issued", r.get("Issued")),
+		Available:      p.uint("Available", r.get("Available")),
+		Held:           p.uint("Held", r.get("Held")),
+		AvailableRatio: p.float("AvailableRatio", r.get("AvailableRatio")),
+		Ratio:          p.float("Ratio", r.get("Ratio")),
+		Limit:          p.float("Limit", r.get("Limit")),
+		ChinaLimit:     p.optFloat("ChinaLimit", r.get("ChinaLimit")),
+		Reason:         strings.TrimSpace(r.get("Reason")),
+	}
+}
+
+// QFIIS 上市外資及陸資投資持股統計（全部個股）
+type QFIIS struct {
+	Date     time.Time
+	Category string // 類別，預設為 ALLBUT0999（全部，不含權證、牛熊證）
+	FmtData  map[string]ForeignHolding
+	report   *Report
+	fetcher  Fetcher
+	client   *Client
+}
+
+// NewQFIIS 建立外資及陸資投資持股統計
+func NewQFIIS(date time.Time) *QFIIS {
+	return &QFIIS{Date: date, Category: "ALLBUT0999", FmtData: make(map[string]ForeignHolding)}
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (q *QFIIS) SetFetcher(f Fetcher) *QFIIS {
+	q.fetcher = f
+	return q
+}
+
+// SetCategory 指定類別，如 ALLBUT0999、01（水泥工業）
+func (q *QFIIS) SetCategory(category string) *QFIIS {
+	q.Category = category
+	return q
+}
+
+// URL 擷取網址
+func (q QFIIS) URL() string {
+	return q.client.twseURL(fmt.Sprintf(utils.QFIIS, q.Date.Year(), q.Date.Month(), q.Date.Day(), q.Category))
+}
+
+// Get 擷取當日全部股票的外資及陸資持股，欄位解析失敗時記錄在 Report()
+func (q *QFIIS) Get() (map[string]ForeignHolding, error) {
+	var s = schema{name: "MI_QFIIS " + q.Date.Format("20060102"), columns: qfiisSchema}
+	data, err := q.client.fetchTWSE(q.fetcher).PostForm(q.URL(), nil)
+	if err != nil {
+		return nil, fmt.Errorf(errorNetworkFail.Error(), err)
+	}
+	table, err := q.client.parse(s, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var p = newParser(s.name, q.client.Strict())
+	q.report = p.report
+	for _, r := range table.records {
+		p.next()
+		var v = foreignHolding(p, r, q.Date)
+		if err := p.err(); err != nil {
+			return nil, err
+		}
+		if p.bad {
+			continue
+		}
+		q.FmtData[v.No] = v
+	}
+	return q.FmtData, nil
+}
+
+// NearLimit 持股比率距離投資上限在 points 個百分點以內的股票，依剩餘比率排序
+func (q QFIIS) NearLimit(points float64) []ForeignHolding {
+	var result []ForeignHolding
+	for _, v := range q.FmtData {
+		if v.NearLimit(points) {
+			result = append(result, v)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Room() == result[j].Room() {
+			return result[i].No < result[j].No
+		}
+		return result[i].Room() < result[j].Room()
+	})
+	return result
+}
+
+// Report 回傳最近一次 Get 的驗證報告
+func (q QFIIS) Report() *Report {
+	return q.report
+}
+
+// ForeignHoldingHistory 以股票代碼取得依日期排序的外資及陸資持股
+type ForeignHoldingHistory struct {
+	Data    map[string][]ForeignHolding
+	dates   map[int64]bool
+	report  *Report
+	fetcher Fetcher
+	client  *Client
+}
+
+// NewForeignHoldingHistory 建立外資及陸資持股的歷史資料
+func NewForeignHoldingHistory() *ForeignHoldingHistory {
+	return &ForeignHoldingHistory{Data: make(map[string][]ForeignHolding), dates: make(map[int64]bool)}
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (h *ForeignHoldingHistory) SetFetcher(f Fetcher) *ForeignHoldingHistory {
+	h.fetcher = f
+	return h
+}
+
+// LoadRange 擷取 from 到 to 之間每個開市日的資料，已擷取的日期不會重複擷取，
+// 沒有資料的日期略過
+func (h *ForeignHoldingHistory) LoadRange(from, to time.Time) error {
+	var report = &Report{Dataset: "MI_QFIIS"}
+	err := loadEach(openDays(h.client.Calendar(), from, to), h.dates, func(day time.Time) error {
+		q := &QFIIS{Date: day, Category: "ALLBUT0999", FmtData: make(map[string]ForeignHolding), fetcher: h.fetcher, client: h.client}
+		data, err := q.Get()
+		if err != nil {
+			return err
+		}
+		report.merge(q.Report())
+		for no, v := range data {
+			h.Data[no] = append(h.Data[no], v)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for no := range h.Data {
+		list := h.Data[no]
+		sort.Slice(list, func(i, j int) bool { return list[i].Date.Before(list[j].Date) })
+	}
+	h.report = report
+	return nil
+}
+
+// Stock 回傳個股依日期排序的外資及陸資持股
+func (h ForeignHoldingHistory) Stock(no string) []ForeignHolding {
+	return h.Data[no]
+}
+
+// Ratios 個股每日的外資及陸資持股比率(%)，依日期排序
+func (h ForeignHoldingHistory) Ratios(no string) []float64 {
+	var (
+		data   = h.Data[no]
+		result = make([]float64, len(data))
+	)
+	for i, v := range data {
+		result[i] = v.Ratio
+	}
+	return result
+}
+
+// NearLimit 最後一個交易日持股比率距離投資上限在 points 個百分點以內的股票
+func (h ForeignHoldingHistory) NearLimit(points float64) []ForeignHolding {
+	var latest = QFIIS{FmtData: make(map[string]ForeignHolding, len(h.Data))}
+	for no, list := range h.Data {
+		if len(list) > 0 {
+			latest.FmtData[no] = list[len(list)-1]
+		}
+	}
+	return latest.NearLimit(points)
+}
+
+// Report 回傳最近一次 LoadRange 的驗證報告
+func (h ForeignHoldingHistory) Report() *Report {
+	return h.report
+}
@@ -0,0 +1,54 @@
+package twse
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+func qfiisFixture(date, held, ratio string) string {
+	return fmt.Sprintf(`"%s 外資及陸資投資持股統計"
+"證券代號","證券名稱","國際證券編碼","發行股數","外資及陸資尚可投資股數","全體外資及陸資持有股數","外資及陸資尚可投資比率","全體外資及陸資持股比率","外資及陸資共用法令投資上限比率","陸資法令投資上限比率","與前日異動原因(註)","最近一次上市公司申報外資持股異動日期",
+"2618","長榮航","TW0002618006","4,000,000,000","2,000,000,000","%s","50.00","%s","100.00","50.00","","1060301",
+"2412","中華電","TW0002412004","7,757,446,545","205,000,000","2,274,000,000","2.64","29.31","49.00","0.00","","1060301",
+"說明:"
+`, date, held, ratio)
+}
+
+func TestForeignHoldingHistory_LoadRange(t *testing.T) {
+	var (
+		url = func(day int) string {
+			return fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.QFIIS, 2017, 3, day, "ALLBUT0999"))
+		}
+		client = NewClient(WithFetcher(dayFetcher(url, map[int]string{
+			1: qfiisFixture("106年03月01日", "2,000,000,000", "50.00"),
+			2: qfiisFixture("106年03月02日", "2,040,000,000", "51.00"),
+			3: fixtureNoData,
+		})))
+		h = client.NewForeignHoldingHistory()
+	)
+	if err := h.LoadRange(time.Date(2017, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone), time.Date(2017, 3, 3, 0, 0, 0, 0, utils.TaipeiTimeZone)); err != nil {
+		t.Fatal(err)
+	}
+	data := h.Stock("2618")
+	if len(data) != 2 || len(h.Data) != 2 || data[1].Held != 2040000000 || data[1].Date.Day() != 2 {
+		t.Fatalf("Wrong data: %+v", h.Data)
+	}
+	if v := h.Stock("2412")[0]; v.Name != "中華電" || v.Issued != 7757446545 || v.Available != 205000000 ||
+		v.AvailableRatio != 2.64 || v.Limit != 49 || v.Room() != 19.69 {
+		t.Errorf("Wrong data: %+v", v)
+	}
+	if r := h.Ratios("2618"); len(r) != 2 || r[0] != 50 || r[1] != 51 {
+		t.Errorf("Wrong ratios: %v", r)
+	}
+
+	near := h.NearLimit(20)
+	if len(near) != 1 || near[0].No != "2412" {
+		t.Errorf("Wrong near limit: %+v", near)
+	}
+	if near := h.NearLimit(50); len(near) != 2 || near[0].No != "2412" || near[1].No != "2618" {
+		t.Errorf("Wrong near limit: %+v", near)
+	}
+}
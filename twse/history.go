@@ -0,0 +1,227 @@
+package twse
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/tradingdays"
+	"github.com/DoubleChuang/gogrs/utils"
+	"github.com/pkg/errors"
+)
+
+// MonthError 單一月份擷取失敗的原因
+type MonthError struct {
+	Month time.Time
+	Err   error
+}
+
+// LoadError LoadRange、LoadDays 擷取失敗的月份列表
+//
+// 其他成功的月份仍會合併到 RawData，存入 Store 失敗（*StoreError）的月份資料也會合併。
+type LoadError struct {
+	No     string
+	Months []MonthError
+}
+
+func (e *LoadError) Error() string {
+	var months = make([]string, len(e.Months))
+	for i, v := range e.Months {
+		months[i] = fmt.Sprintf("%d/%02d(%s)", v.Month.Year(), v.Month.Month(), strings.TrimSpace(v.Err.Error()))
+	}
+	return fmt.Sprintf("[%s] load fail: %s", e.No, strings.Join(months, ", "))
+}
+
+// NoData 失敗的月份是否皆為查無資料（如新上市、停牌）
+func (e *LoadError) NoData() bool {
+	for _, v := range e.Months {
+		if errors.Cause(v.Err) != errorNotEnoughData {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadRange 擷取 from ~ to（含）之間的資料，依日期排序合併至 RawData
+//
+// 所需月份依開休市表計算並同時擷取，同時擷取的數量由 WithMaxConcurrency 設定，
+// 存取間隔則由 Client 的 Fetcher 控制。有月份擷取失敗時回傳 *LoadError。
+func (d *Data) LoadRange(from, to time.Time) error {
+	var (
+		calendar = d.client.Calendar()
+		months   []time.Time
+	)
+
+	if now := time.Now(); to.After(now) {
+		to = now
+	}
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, d.Date.Location())
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, d.Date.Location())
+
+	for month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location()); !month.After(to); month = month.AddDate(0, 1, 0) {
+		for day := month; day.Month() == month.Month() && !day.After(to); day = day.AddDate(0, 0, 1) {
+			if !day.Before(from) && calendar.IsOpen(day.Year(), day.Month(), day.Day()) {
+				months = append(months, month)
+				break
+			}
+		}
+	}
+
+	type monthResult struct {
+		month  time.Time
+		rows   [][]string
+		name   string
+		report *Report
+		err    error
+	}
+
+	var (
+		results = make([]monthResult, len(months))
+		limit   = make(chan struct{}, d.client.maxConcurrency())
+		wg      sync.WaitGroup
+	)
+	for i, month := range months {
+		wg.Add(1)
+		go func(i int, month time.Time) {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+			var fetchDate, until = month, month.AddDate(0, 1, -1)
+			if month.Year() == to.Year() && month.Month() == to.Month() {
+				fetchDate, until = to, to // 與 Get 使用相同的網址
+			}
+			rows, name, report, err := d.fetchMonth(fetchDate, until)
+			results[i] = monthResult{month: month, rows: rows, name: name, report: report, err: err}
+		}(i, month)
+	}
+	wg.Wait()
+
+	var (
+		loadErr *LoadError
+		merged  = make(map[int64][]string)
+		report  = &Report{Dataset: d.dataset()}
+	)
+	for _, v := range d.RawData {
+		if date := utils.ParseDate(v[0]); !date.IsZero() {
+			merged[date.Unix()] = v
+		}
+	}
+	for _, r := range results {
+		report.merge(r.report)
+		if r.err != nil {
+			if loadErr == nil {
+				loadErr = &LoadError{No: d.No}
+			}
+			loadErr.Months = append(loadErr.Months, MonthError{Month: r.month, Err: r.err})
+			if _, ok := r.err.(*StoreError); !ok {
+				continue
+			}
+		}
+		if d.Name == "" {
+			d.Name = r.name
+		}
+		pickData := make([][]string, 0)
+		for _, v := range r.rows {
+			date := utils.ParseDate(v[0])
+			if date.IsZero() || dateKey(date) < dateKey(from) || dateKey(date) > dateKey(to) {
+				continue
+			}
+			pickData = append(pickData, v)
+			merged[date.Unix()] = v
+		}
+		d.UnixMapData[r.month.Unix()] = pickData
+	}
+
+	var dates = make([]int64, 0, len(merged))
+	for k := range merged {
+		dates = append(dates, k)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i] < dates[j] })
+	d.RawData = make([][]string, len(dates))
+	for i, k := range dates {
+		d.RawData[i] = merged[k]
+	}
+	if len(months) > 0 {
+		d.Date = months[0]
+	}
+	d.BackupDate = to
+	d.report = report
+	d.clearCache()
+
+	if loadErr != nil {
+		return loadErr
+	}
+	return nil
+}
+
+// dateKey 以年月日比較日期，忽略時區
+func dateKey(date time.Time) int {
+	year, month, day := date.Date()
+	return year*10000 + int(month)*100 + day
+}
+
+// openDays from 到 to（含）之間的開市日
+func openDays(calendar *tradingdays.Calendar, from, to time.Time) []time.Time {
+	var result []time.Time
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, utils.TaipeiTimeZone)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if calendar.IsOpen(day.Year(), day.Month(), day.Day()) {
+			result = append(result, day)
+		}
+	}
+	return result
+}
+
+// monthsBetween from 到 to（含）之間每個月份的第一天
+func monthsBetween(from, to time.Time) []time.Time {
+	var result []time.Time
+	for month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, utils.TaipeiTimeZone); !month.After(to); month = month.AddDate(0, 1, 0) {
+		result = append(result, month)
+	}
+	return result
+}
+
+// loadEach 依序以 get 擷取 dates 中尚未擷取（done）的日期，沒有資料的日期略過，其他錯誤時停止並回傳；
+// 擷取成功的日期記錄在 done，done 為 nil 時不記錄
+func loadEach(dates []time.Time, done map[int64]bool, get func(date time.Time) error) error {
+	for _, date := range dates {
+		if done[date.Unix()] {
+			continue
+		}
+		if err := get(date); errors.Cause(err) == errorNotEnoughData {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if done != nil {
+			done[date.Unix()] = true
+		}
+	}
+	return nil
+}
+
+// LoadDays 擷取至 BackupDate 為止最近 n 個開市日的資料
+//
+// 停牌的日子不會有資料，所以 Len() 可能少於 n。
+func (d *Data) LoadDays(n int) error {
+	var (
+		calendar = d.client.Calendar()
+		to       = d.BackupDate
+		from     = to
+	)
+	if n <= 0 {
+		return nil
+	}
+	if now := time.Now(); to.After(now) {
+		to = now
+	}
+	for day, count := to, 0; count < n; day = day.AddDate(0, 0, -1) {
+		if calendar.IsOpen(day.Year(), day.Month(), day.Day()) {
+			count++
+			from = day
+		}
+	}
+	return d.LoadRange(from, to)
+}
@@ -0,0 +1,76 @@
+package twse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+const fixtureSTOCKDAY201502 = `"104年02月 2618 長榮航           各日成交資訊"
+"日期","成交股數","成交金額","開盤價","最高價","最低價","收盤價","漲跌價差","成交筆數",
+"104/02/24","11,142,870","254,530,215","22.80","22.95","22.70","22.90","+0.10","3,171",
+"104/02/25","9,852,120","225,498,716","22.90","23.00","22.80","22.90","0.00","2,711",
+"104/02/26","16,390,050","377,978,046","22.95","23.20","22.85","23.00","+0.10","4,036",
+"說明:"
+`
+
+func TestData_LoadRange(t *testing.T) {
+	var (
+		date = time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		d    = NewTWSE("2618", date)
+		feb  = NewTWSE("2618", time.Date(2015, 2, 1, 0, 0, 0, 0, utils.TaipeiTimeZone))
+	)
+	d.SetFetcher(memFetcher{d.URL(): fixtureSTOCKDAY, feb.URL(): fixtureSTOCKDAY201502})
+
+	if err := d.LoadRange(time.Date(2015, 2, 25, 0, 0, 0, 0, utils.TaipeiTimeZone), date); err != nil {
+		t.Fatal(err)
+	}
+	if d.Len() != 5 {
+		t.Fatalf("Should be 5 but %d", d.Len())
+	}
+	dates := d.GetDateList()
+	for i := 1; i < len(dates); i++ {
+		if !dates[i-1].Before(dates[i]) {
+			t.Errorf("Should be in date order: %v", dates)
+		}
+	}
+	if d.RawData[0][0] != "104/02/25" || d.RawData[4][0] != "104/03/04" {
+		t.Errorf("Wrong range: %v", d.RawData)
+	}
+	if d.Name != "長榮航" {
+		t.Errorf("Should be `長榮航` but `%s`", d.Name)
+	}
+}
+
+func TestData_LoadRange_loadError(t *testing.T) {
+	var d = NewTWSE("2618", time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone))
+	d.SetFetcher(memFetcher{d.URL(): fixtureSTOCKDAY})
+
+	err := d.LoadRange(time.Date(2015, 1, 20, 0, 0, 0, 0, utils.TaipeiTimeZone), d.Date)
+	loadErr, ok := err.(*LoadError)
+	if !ok {
+		t.Fatalf("Should be *LoadError but %v", err)
+	}
+	if len(loadErr.Months) != 2 || loadErr.Months[0].Month.Month() != time.January || loadErr.Months[1].Month.Month() != time.February {
+		t.Errorf("Should fail at 2015/01, 2015/02 but %s", loadErr)
+	}
+	if d.Len() != 3 {
+		t.Errorf("Should keep 3 days of 2015/03 but %d", d.Len())
+	}
+}
+
+func TestData_LoadDays(t *testing.T) {
+	var (
+		d   = NewTWSE("2618", time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone))
+		feb = NewTWSE("2618", time.Date(2015, 2, 1, 0, 0, 0, 0, utils.TaipeiTimeZone))
+	)
+	d.SetFetcher(memFetcher{d.URL(): fixtureSTOCKDAY, feb.URL(): fixtureSTOCKDAY201502})
+
+	if err := d.LoadDays(4); err != nil {
+		t.Fatal(err)
+	}
+	if d.Len() != 4 || d.RawData[0][0] != "104/02/26" {
+		t.Errorf("Should start at 104/02/26 but %v", d.RawData)
+	}
+}
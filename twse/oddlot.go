@@ -0,0 +1,176 @@
+package twse
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+// OddLotSession 零股交易時段
+type OddLotSession int
+
+// 零股交易時段
+const (
+	OddLotAfterHours OddLotSession = iota // 盤後零股
+	OddLotIntraday                        // 盤中零股，2020/10/26 起
+)
+
+// OddLots 上市/上櫃零股每日行情，成交量單位為股
+type OddLots struct {
+	Date            time.Time
+	Session         OddLotSession
+	FmtData         map[string]FmtListData
+	exchange        string
+	categoryRawData map[string][][]string
+	categoryNoList  map[string][]StockInfo
+	report          *Report
+	err             error // 最近一次 GetCategoryList 擷取失敗的錯誤
+	fetcher         Fetcher
+	client          *Client
+}
+
+// NewOddLots 建立上市零股每日行情
+func NewOddLots(date time.Time, session OddLotSession) *OddLots {
+	return newOddLots("tse", date, session)
+}
+
+// NewOTCOddLots 建立上櫃零股每日行情
+func NewOTCOddLots(date time.Time, session OddLotSession) *OddLots {
+	return newOddLots("otc", date, session)
+}
+
+func newOddLots(exchange string, date time.Time, session OddLotSession) *OddLots {
+	return &OddLots{
+		Date:            date,
+		Session:         session,
+		FmtData:         make(map[string]FmtListData),
+		exchange:        exchange,
+		categoryRawData: make(map[string][][]string),
+		categoryNoList:  make(map[string][]StockInfo),
+	}
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (o *OddLots) SetFetcher(f Fetcher) *OddLots {
+	o.fetcher = f
+	return o
+}
+
+// URL 擷取類別的網址，上市類別同 TWSECLASS，上櫃類別同 OTCCLASS
+func (o OddLots) URL(category string) string {
+	switch o.exchange {
+	case "tse":
+		var path = utils.TWSEODDCSV
+		if o.Session == OddLotIntraday {
+			path = utils.TWSEINTODD
+		}
+		return o.client.twseURL(fmt.Sprintf(path, o.Date.Year(), o.Date.Month(), o.Date.Day(), category))
+	case "otc":
+		var path = utils.OTCODDCSV
+		if o.Session == OddLotIntraday {
+			path = utils.OTCINTODD
+		}
+		return fmt.Sprintf("%s%s", o.client.OTCHost(), fmt.Sprintf(path,
+			fmt.Sprintf("%d/%02d/%02d", o.Date.Year()-1911, o.Date.Month(), o.Date.Day()), category))
+	}
+	return ""
+}
+
+// oddLotSchema 零股行情的欄位，盤後零股只有成交價格，沒有開高低與漲跌
+var oddLotSchema = []column{
+	col("No", "證券代號", "代號"),
+	col("Name", "證券名稱", "名稱"),
+	col("Volume", "成交股數"),
+	col("TotalPrice", "成交金額", "成交金額(元)"),
+	col("Price", "收盤價", "成交價格", "收盤"),
+	optCol("Totalsale", "成交筆數"),
+	optCol("Open", "開盤價", "開盤"),
+	optCol("High", "最高價", "最高"),
+	optCol("Low", "最低價", "最低"),
+	optCol("Sign", "漲跌(+/-)"),
+	optCol("Range", "漲跌價差", "漲跌"),
+	optCol("LastBuyPrice", "最後揭示買價", "最後買價"),
+	optCol("LastBuyVolume", "最後揭示買量", "最後買量"),
+	optCol("LastSellPrice", "最後揭示賣價", "最後賣價"),
+	optCol("LastSellVolume", "最後揭示賣量", "最後賣量"),
+}
+
+// Get 擷取類別的零股行情，欄位解析失敗時記錄在 Report()
+func (o *OddLots) Get(category string) ([][]string, error) {
+	var s = schema{name: fmt.Sprintf("%s odd lot %s", o.exchange, category), columns: oddLotSchema}
+	if o.exchange == "tse" && TWSECLASS[category] == "" {
+		return nil, errorNotSupport
+	}
+	t, err := o.client.fetchTable(o.fetcher, o.exchange, o.URL(category), s)
+	if err != nil {
+		return nil, err
+	}
+	o.categoryRawData[category] = t.rows
+	if err := o.formatData(s.name, category, t.records); err != nil {
+		return nil, err
+	}
+	return t.rows, nil
+}
+
+// formatData 解析類別的每一列，無成交的價格為 0
+func (o *OddLots) formatData(dataset, category string, records []record) error {
+	var (
+		p    = newParser(dataset, o.client.Strict())
+		list = make([]StockInfo, 0, len(records))
+	)
+	o.report = p.report
+	for _, r := range records {
+		p.next()
+		var data FmtListData
+		data.No = r.get("No")
+		data.Name = r.get("Name")
+		data.Volume = p.uint("Volume", r.get("Volume"))
+		data.TotalPrice = p.uint("TotalPrice", r.get("TotalPrice"))
+		data.Price = p.optFloat("Price", r.get("Price"))
+		data.Totalsale = p.optUint("Totalsale", r.get("Totalsale"))
+		data.Open = p.optFloat("Open", r.get("Open"))
+		data.High = p.optFloat("High", r.get("High"))
+		data.Low = p.optFloat("Low", r.get("Low"))
+		if v := p.change("Range", strings.Replace(r.get("Range"), " ", "", -1)); strings.Contains(r.get("Sign"), "-") {
+			data.Range = -v
+		} else {
+			data.Range = v
+		}
+		data.LastBuyPrice = p.optFloat("LastBuyPrice", r.get("LastBuyPrice"))
+		data.LastBuyVolume = p.optUint("LastBuyVolume", r.get("LastBuyVolume"))
+		data.LastSellPrice = p.optFloat("LastSellPrice", r.get("LastSellPrice"))
+		data.LastSellVolume = p.optUint("LastSellVolume", r.get("LastSellVolume"))
+		if err := p.err(); err != nil {
+			return err
+		}
+
+		o.FmtData[data.No] = data
+		list = append(list, StockInfo{No: data.No, Name: data.Name})
+	}
+	o.categoryNoList[category] = list
+	return nil
+}
+
+// GetCategoryList 取得分類的股票代碼與名稱列表，擷取失敗時回傳空列表並記錄在 Err()
+func (o *OddLots) GetCategoryList(category string) []StockInfo {
+	o.err = nil
+	if _, ok := o.categoryNoList[category]; !ok {
+		if _, err := o.Get(category); err != nil {
+			o.err = err
+			return nil
+		}
+	}
+	return o.categoryNoList[category]
+}
+
+// Err 回傳最近一次 GetCategoryList 擷取失敗的錯誤
+func (o OddLots) Err() error {
+	return o.err
+}
+
+// Report 回傳最近一次 Get 的驗證報告
+func (o OddLots) Report() *Report {
+	return o.report
+}
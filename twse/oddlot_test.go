@@ -0,0 +1,61 @@
+package twse
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+func TestOddLots_Get(t *testing.T) {
+	var (
+		date   = time.Date(2020, 10, 30, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		client = NewClient(WithFetcher(memFetcher{
+			fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.TWSEODDCSV, 2020, 10, 30, "ALLBUT0999")): `"109年10月30日 零股交易行情單"
+"證券代號","證券名稱","成交股數","成交筆數","成交金額","成交價格","最後揭示買價","最後揭示買量","最後揭示賣價","最後揭示賣量",
+="2330","台積電","123,456","1,024","53,826,816","436.00","435.50","1,200","436.00","3,400",
+="2618","長榮航","8,000","12","103,200","--","12.85","500","12.95","1,000",
+"備註:"
+`,
+			fmt.Sprintf("%s%s", utils.OTCHOST, fmt.Sprintf(utils.OTCINTODD, "109/10/30", "EW")): `"盤中零股交易行情"
+"資料日期:109/10/30"
+"代號","名稱","收盤","漲跌","開盤","最高","最低","成交股數","成交金額(元)","成交筆數","最後買價","最後賣價"
+"8446","華研","168.00","-1.50","170.00","170.00","167.50","2,345","395,960","38","167.50","168.00"
+"共1筆"
+`}))
+	)
+	odd := client.NewOddLots(date, OddLotAfterHours)
+	if _, err := odd.Get("ALLBUT0999"); err != nil {
+		t.Fatal(err)
+	}
+	if data := odd.FmtData["2330"]; data.Price != 436 || data.Volume != 123456 || data.LastSellVolume != 3400 || data.Open != 0 {
+		t.Errorf("Wrong data: %+v", data)
+	}
+	if data := odd.FmtData["2618"]; data.Price != 0 || !odd.Report().OK() {
+		t.Errorf("Should be no trade: %+v %v", data, odd.Report().Errors)
+	}
+
+	otc := client.NewOTCOddLots(date, OddLotIntraday)
+	list := otc.GetCategoryList("EW")
+	if len(list) != 1 || list[0].Name != "華研" {
+		t.Fatalf("Wrong list: %v", list)
+	}
+	if data := otc.FmtData["8446"]; data.Range != -1.5 || data.High != 170 || data.Totalsale != 38 {
+		t.Errorf("Wrong data: %+v", data)
+	}
+	if _, err := client.NewOddLots(date, OddLotIntraday).Get("XX"); err != errorNotSupport {
+		t.Errorf("Should be errorNotSupport but %v", err)
+	}
+
+	var lists BaseLists = client.NewOddLots(date, OddLotIntraday)
+	if list := lists.GetCategoryList("XX"); len(list) != 0 {
+		t.Errorf("Should be empty but %v", list)
+	}
+	if err := lists.(*OddLots).Err(); err != errorNotSupport {
+		t.Errorf("Should be errorNotSupport but %v", err)
+	}
+	if list := otc.GetCategoryList("EW"); len(list) != 1 || otc.Err() != nil {
+		t.Errorf("Wrong list: %v %v", list, otc.Err())
+	}
+}
@@ -0,0 +1,118 @@
+package twse
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+	"github.com/pkg/errors"
+)
+
+// otcVolumeColumns 上櫃三大法人買賣明細的買進、賣出、買賣超欄位，
+// unit 為現行標題的單位名稱，old 為改版前的單位名稱
+func otcVolumeColumns(prefix, unit, old string) []column {
+	return []column{
+		col(prefix+"Buy", unit+"-買進股數", old+"買股數"),
+		col(prefix+"Sell", unit+"-賣出股數", old+"賣股數"),
+		col(prefix+"Total", unit+"-買賣超股數", old+"淨買股數"),
+	}
+}
+
+// otcInstiSchema 上櫃三大法人買賣明細資訊的欄位，columns 接在代號、名稱之後
+func otcInstiSchema(columns ...[]column) []column {
+	var result = []column{
+		col("No", "代號", "證券代號"),
+		col("Name", "名稱", "證券名稱"),
+	}
+	for _, c := range columns {
+		result = append(result, c...)
+	}
+	return result
+}
+
+var (
+	otcFII    = otcVolumeColumns("FII.", "外資及陸資(不含外資自營商)", "外資及陸資")
+	otcSIT    = otcVolumeColumns("SIT.", "投信", "投信")
+	otcDProp  = otcVolumeColumns("DProp.", "自營商(自行買賣)", "自營商(自行買賣)")
+	otcDHedge = otcVolumeColumns("DHedge.", "自營商(避險)", "自營商(避險)")
+
+	// otcT86Schema 上櫃三大法人買賣明細資訊，欄位名稱同 t86Schema
+	otcT86Schema = otcInstiSchema(otcFII, otcSIT, otcDProp, otcDHedge,
+		[]column{col("Diff", "三大法人買賣超股數合計", "三大法人買賣超股數")})
+
+	// otcTWTXXUSchema 以上櫃三大法人買賣明細資訊取代外資、自營商、投信買賣超彙總表，欄位名稱同上市
+	otcTWTXXUSchema = map[string][]column{
+		"TWT38U": otcInstiSchema(otcVolumeColumns("", "外資及陸資(不含外資自營商)", "外資及陸資")),
+		"TWT43U": otcInstiSchema(otcDProp, otcDHedge),
+		"TWT44U": otcInstiSchema(otcVolumeColumns("", "投信", "投信")),
+	}
+
+	// otcMTSSSchema 上櫃融資融券餘額的欄位，欄位名稱同 mtssSchema
+	otcMTSSSchema = []column{
+		col("No", "代號", "股票代號"),
+		col("Name", "名稱", "股票名稱"),
+		col("MT.Buy", "資買"),
+		col("MT.Sell", "資賣"),
+		col("SS.Buy", "券買"),
+		col("SS.Sell", "券賣"),
+	}
+)
+
+// otcDate 上櫃網址使用的民國日期 yyy/mm/dd
+func otcDate(date time.Time) string {
+	return fmt.Sprintf("%d/%02d/%02d", date.Year()-1911, date.Month(), date.Day())
+}
+
+// otcInstiURL 上櫃三大法人買賣明細資訊的擷取網址
+func otcInstiURL(c *Client, date time.Time) string {
+	return fmt.Sprintf("%s%s", c.OTCHost(), fmt.Sprintf(utils.OTCINSTI, otcDate(date)))
+}
+
+// getOTC 擷取上櫃的 CSV 並依表頭解析，查無資料時移除快取並回傳 errorFileNoData
+func getOTC(f Fetcher, url string, s schema) (*table, error) {
+	data, err := f.Get(url, false)
+	if err != nil {
+		return nil, err
+	}
+	table, err := s.parse(data)
+	if errors.Cause(err) == errorNotEnoughData {
+		if err := removeCache(f, url); err != nil {
+			return nil, err
+		}
+		return nil, errorFileNoData
+	}
+	return table, err
+}
+
+// NewOTCT86 上櫃三大法人買賣明細資訊
+func NewOTCT86(date time.Time) *T86 {
+	return &T86{Date: date, exchange: "otc"}
+}
+
+// NewOTCTWT38U 上櫃外資及陸資買賣超，取自三大法人買賣明細資訊
+func NewOTCTWT38U(date time.Time) *TWT38U {
+	t := NewTWT38U(date)
+	t.exchange = "otc"
+	return t
+}
+
+// NewOTCTWT43U 上櫃自營商買賣超，取自三大法人買賣明細資訊
+func NewOTCTWT43U(date time.Time) *TWT43U {
+	t := NewTWT43U(date)
+	t.exchange = "otc"
+	return t
+}
+
+// NewOTCTWT44U 上櫃投信買賣超，取自三大法人買賣明細資訊
+func NewOTCTWT44U(date time.Time) *TWT44U {
+	t := NewTWT44U(date)
+	t.exchange = "otc"
+	return t
+}
+
+// NewOTCTWMTSS 上櫃融資融券餘額
+func NewOTCTWMTSS(date time.Time) *TWMTSS {
+	t := NewTWMTSS(date, "")
+	t.exchange = "otc"
+	return t
+}
@@ -0,0 +1,119 @@
+package twse
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+const fixtureOTCInsti = `"三大法人買賣明細資訊"
+"資料日期:106/03/01"
+"代號","名稱","外資及陸資(不含外資自營商)-買進股數","外資及陸資(不含外資自營商)-賣出股數","外資及陸資(不含外資自營商)-買賣超股數","外資自營商-買進股數","外資自營商-賣出股數","外資自營商-買賣超股數","外資及陸資-買進股數","外資及陸資-賣出股數","外資及陸資-買賣超股數","投信-買進股數","投信-賣出股數","投信-買賣超股數","自營商(自行買賣)-買進股數","自營商(自行買賣)-賣出股數","自營商(自行買賣)-買賣超股數","自營商(避險)-買進股數","自營商(避險)-賣出股數","自營商(避險)-買賣超股數","自營商-買進股數","自營商-賣出股數","自營商-買賣超股數","三大法人買賣超股數合計"
+"6488","環球晶","1,000,000","400,000","600,000","0","0","0","1,000,000","400,000","600,000","50,000","20,000","30,000","10,000","30,000","-20,000","5,000","1,000","4,000","15,000","31,000","-16,000","614,000"
+"共1筆"
+`
+
+const fixtureOTCMTSS = `"上櫃股票融資融券餘額"
+"資料日期:106/03/01"
+"代號","名稱","前資餘額(張)","資買","資賣","現償","資餘額","資屬證金","資使用率(%)","資限額","前券餘額(張)","券賣","券買","券償","券餘額","券屬證金","券使用率(%)","券限額","資券相抵(張)","備註"
+"6488","環球晶","1,000","300","100","0","1,200","0","1.20","100,000","200","80","30","0","250","0","0.25","100,000","10",""
+"合計","","1,000","300","100","0","1,200","","","","200","80","30","0","250","","","","10",""
+`
+
+func TestOTCInsti_Get(t *testing.T) {
+	var (
+		date   = time.Date(2017, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		client = NewClient(WithFetcher(memFetcher{
+			fmt.Sprintf("%s%s", utils.OTCHOST, fmt.Sprintf(utils.OTCINSTI, "106/03/01")): fixtureOTCInsti,
+			fmt.Sprintf("%s%s", utils.OTCHOST, fmt.Sprintf(utils.OTCMTSS, "106/03/01")):  fixtureOTCMTSS,
+		}))
+	)
+
+	t86, err := client.NewOTCT86(date).Get("EW")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(t86) != 1 {
+		t.Fatalf("Wrong data: %+v", t86)
+	}
+	if v := t86[0]; v.No != "6488" || v.FII.Total != 600000 || v.SIT.Buy != 50000 || v.DProp.Total != -20000 || v.DHedge.Sell != 1000 || v.Diff != 614000 {
+		t.Errorf("Wrong data: %+v", v)
+	}
+
+	t38, err := client.NewOTCTWT38U(date).Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t43, err := client.NewOTCTWT43U(date).Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t44, err := client.NewOTCTWT44U(date).Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if t38["6488"].Volume.Total != 600000 || t43["6488"].Volume.Total != -16000 || t44["6488"].Volume != (TradingVolume{50000, 20000, 30000}) {
+		t.Errorf("Wrong data: %+v %+v %+v", t38, t43, t44)
+	}
+
+	mtss, err := client.NewOTCTWMTSS(date).Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := mtss["6488"]; len(mtss) != 1 || v.Name != "環球晶" || v.MT.Buy != 300 || v.MT.Total != 200 || v.SS.Sell != 80 || v.SS.Total != 50 {
+		t.Errorf("Wrong data: %+v", mtss)
+	}
+}
+
+func TestOTCInsti_badRow(t *testing.T) {
+	var (
+		date    = time.Date(2017, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		fixture = strings.Replace(fixtureOTCInsti, `"共1筆"`,
+			`"3105","穩懋","1,0x0","0","0","0","0","0","0","0","0","5,000","0","5,000","0","0","0","0","0","0","0","0","0","5,000"
+"共2筆"`, 1)
+		client = NewClient(WithFetcher(memFetcher{
+			fmt.Sprintf("%s%s", utils.OTCHOST, fmt.Sprintf(utils.OTCINSTI, "106/03/01")): fixture,
+		}))
+	)
+
+	t86 := client.NewOTCT86(date)
+	data, err := t86.Get("EW")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 1 || data[0].No != "6488" {
+		t.Errorf("Should skip bad row: %+v", data)
+	}
+	if r := t86.Report(); r.Rows != 2 || r.Skipped != 1 || len(r.Errors) != 1 {
+		t.Errorf("Wrong report: %s", r)
+	}
+
+	t38 := client.NewOTCTWT38U(date)
+	if v, err := t38.Get(); err != nil {
+		t.Fatal(err)
+	} else if _, ok := v["3105"]; ok || len(v) != 1 {
+		t.Errorf("Should skip bad row: %+v", v)
+	}
+	if r := t38.Report(); r.Skipped != 1 || r.Errors[0].Column != "Buy" {
+		t.Errorf("Wrong report: %s", r)
+	}
+	t43 := client.NewOTCTWT43U(date)
+	if _, err := t43.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if r := t43.Report(); !r.OK() || r.Rows != 2 {
+		t.Errorf("Wrong report: %s", r)
+	}
+	t44 := client.NewOTCTWT44U(date)
+	if v, err := t44.Get(); err != nil {
+		t.Fatal(err)
+	} else if v["3105"].Volume.Buy != 5000 {
+		t.Errorf("Wrong data: %+v", v)
+	}
+	if r := t44.Report(); !r.OK() {
+		t.Errorf("Wrong report: %s", r)
+	}
+}
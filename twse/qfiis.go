@@ -1,16 +1,13 @@
 package twse
 
 import (
-	"encoding/csv"
 	"fmt"
-	"os"
-	"strconv"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 
-	"github.com/DoubleChuang/gogrs/tradingdays"
 	"github.com/DoubleChuang/gogrs/utils"
 )
 
@@ -21,45 +18,127 @@ var (
 
 // BaseSellBuy 買進賣出合計
 type BaseSellBuy struct {
-	No    string
-	Name  string
-	Buy   int64 // 買進
-	Sell  int64 // 賣出
-	Total int64 // 合計
+	No    string `json:"no"`
+	Name  string `json:"name"`
+	Buy   int64  `json:"buy"`   // 買進
+	Sell  int64  `json:"sell"`  // 賣出
+	Total int64  `json:"total"` // 合計
 }
 
+// QFIISRank 外資及陸資持股比率排名
+type QFIISRank struct {
+	Rank int `json:"rank"` // 排名
+	ForeignHolding
+}
+
+// qfiisTop20Schema 外資及陸資持股比率前二十名彙總表（MI_QFIIS_sort_20）的欄位
+var qfiisTop20Schema = append([]column{col("Rank", "排名")}, qfiisSchema...)
+
 // QFIISTOP20 取得「外資及陸資持股比率前二十名彙總表」
 type QFIISTOP20 struct {
-	Date time.Time
+	Date    time.Time
+	report  *Report
+	fetcher Fetcher
+	client  *Client
 }
 
 // URL 擷取網址
 func (q QFIISTOP20) URL() string {
-	return fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.QFIISTOP20, q.Date.Year(), q.Date.Month(), q.Date.Day()))
+	return q.client.twseURL(fmt.Sprintf(utils.QFIISTOP20, q.Date.Year(), q.Date.Month(), q.Date.Day()))
 }
 
-// Get 擷取資料
-func (q QFIISTOP20) Get() ([][]string, error) {
+// SetFetcher 指定擷取資料的 Fetcher
+func (q *QFIISTOP20) SetFetcher(f Fetcher) *QFIISTOP20 {
+	q.fetcher = f
+	return q
+}
+
+// Get 擷取資料，依排名排序，欄位解析失敗時記錄在 Report()
+func (q *QFIISTOP20) Get() ([]QFIISRank, error) {
+	var s = schema{name: "MI_QFIIS_sort_20 " + q.Date.Format("20060102"), columns: qfiisTop20Schema}
+	data, err := q.client.fetchTWSE(q.fetcher).PostForm(q.URL(), nil)
+	if err != nil {
+		return nil, fmt.Errorf(errorNetworkFail.Error(), err)
+	}
+	table, err := q.client.parse(s, data)
+	if err != nil {
+		return nil, err
+	}
+
 	var (
-		err  error
-		data []byte
+		p      = newParser(s.name, q.client.Strict())
+		result []QFIISRank
 	)
-	if data, err = hCache.PostForm(q.URL(), nil); err == nil {
-		if len(data) > 0 {
-			csvArrayContent := strings.Split(string(data), "\n")[2:]
-			for i, v := range csvArrayContent {
-				csvArrayContent[i] = strings.Replace(v, "=", "", -1)
-			}
-			return csv.NewReader(strings.NewReader(strings.Join(csvArrayContent, "\n"))).ReadAll()
+	q.report = p.report
+	for _, r := range table.records {
+		p.next()
+		var v = QFIISRank{
+			Rank:           int(p.uint("Rank", r.get("Rank"))),
+			ForeignHolding: foreignHolding(p, r, q.Date),
 		}
+		if err := p.err(); err != nil {
+			return nil, err
+		}
+		if p.bad {
+			continue
+		}
+		result = append(result, v)
 	}
-	return nil, err
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Rank < result[j].Rank })
+	return result, nil
+}
+
+// Report 回傳最近一次 Get 的驗證報告
+func (q QFIISTOP20) Report() *Report {
+	return q.report
+}
+
+// BFI82UData 一個交易日的三大法人買賣金額，單位為元
+type BFI82UData struct {
+	Date   time.Time     `json:"date"`
+	Dealer BaseSellBuy   `json:"dealer"` // 自營商（自行買賣及避險）
+	SIT    BaseSellBuy   `json:"sit"`    // 投信
+	FII    BaseSellBuy   `json:"fii"`    // 外資及陸資（含外資自營商）
+	Total  BaseSellBuy   `json:"total"`  // 合計
+	Items  []BaseSellBuy `json:"items"`  // 報表各列
+}
+
+// add 將報表的一列依單位名稱加總至自營商、投信、外資或合計
+func (d *BFI82UData) add(v BaseSellBuy) {
+	var target *BaseSellBuy
+	switch {
+	case strings.HasPrefix(v.Name, "自營商"):
+		target = &d.Dealer
+	case strings.HasPrefix(v.Name, "投信"):
+		target = &d.SIT
+	case strings.HasPrefix(v.Name, "外資"):
+		target = &d.FII
+	case strings.HasPrefix(v.Name, "合計"):
+		target = &d.Total
+	}
+	if target != nil {
+		target.Buy += v.Buy
+		target.Sell += v.Sell
+		target.Total += v.Total
+	}
+	d.Items = append(d.Items, v)
+}
+
+// bfi82uSchema 三大法人買賣金額統計表（BFI82U）的欄位
+var bfi82uSchema = []column{
+	col("Name", "單位名稱"),
+	col("Buy", "買進金額"),
+	col("Sell", "賣出金額"),
+	col("Total", "買賣差額"),
 }
 
 // BFI82U 取得「三大法人買賣金額統計表」
 type BFI82U struct {
-	Begin time.Time
-	End   time.Time
+	Begin   time.Time
+	End     time.Time
+	report  *Report
+	fetcher Fetcher
+	client  *Client
 }
 
 // NewBFI82U 三大法人買賣金額統計表
@@ -67,97 +146,218 @@ func NewBFI82U(begin, end time.Time) *BFI82U {
 	return &BFI82U{Begin: begin, End: end}
 }
 
-// URL 擷取網址
+// URL 擷取網址（Begin 當日）
 func (b BFI82U) URL() string {
-	return fmt.Sprintf("%s%s", utils.TWSEHOST,
-		fmt.Sprintf(utils.BFI82U, b.Begin.Year(), b.Begin.Month(), b.Begin.Day()))
+	return b.url(b.Begin)
 }
 
-// Get 擷取資料
-func (b BFI82U) Get() ([]BaseSellBuy, error) {
+func (b BFI82U) url(date time.Time) string {
+	return b.client.twseURL(fmt.Sprintf(utils.BFI82U, date.Year(), date.Month(), date.Day()))
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (b *BFI82U) SetFetcher(f Fetcher) *BFI82U {
+	b.fetcher = f
+	return b
+}
+
+// SetRange 指定擷取的日期區間
+func (b *BFI82U) SetRange(begin, end time.Time) *BFI82U {
+	b.Begin, b.End = begin, end
+	return b
+}
+
+// SetWeek 指定 date 所在的一週（週一至週日）
+func (b *BFI82U) SetWeek(date time.Time) *BFI82U {
 	var (
-		csvdata [][]string
-		data    []byte
-		err     error
-		result  []BaseSellBuy
+		offset = (int(date.Weekday()) + 6) % 7
+		begin  = time.Date(date.Year(), date.Month(), date.Day()-offset, 0, 0, 0, 0, utils.TaipeiTimeZone)
 	)
-	if data, err = hCache.PostForm(b.URL(), nil); err != nil {
+	return b.SetRange(begin, begin.AddDate(0, 0, 6))
+}
+
+// SetMonth 指定 date 所在的月份
+func (b *BFI82U) SetMonth(date time.Time) *BFI82U {
+	var begin = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+	return b.SetRange(begin, begin.AddDate(0, 1, -1))
+}
+
+// Get 擷取 Begin 當日的資料
+func (b *BFI82U) Get() ([]BaseSellBuy, error) {
+	data, err := b.getDay(b.Begin, newParser("BFI82U", b.client.Strict()))
+	if err != nil {
 		return nil, err
 	}
-	if csvdata, err = csv.NewReader(strings.NewReader(strings.Join(strings.Split(string(data), "\n")[2:7], "\n"))).ReadAll(); err == nil {
-		result = make([]BaseSellBuy, len(csvdata))
-		for i, v := range csvdata {
-			result[i].Name = v[0]
-			result[i].Buy, _ = strconv.ParseInt(strings.Replace(v[1], ",", "", -1), 10, 64)
-			result[i].Sell, _ = strconv.ParseInt(strings.Replace(v[2], ",", "", -1), 10, 64)
-			result[i].Total, _ = strconv.ParseInt(strings.Replace(v[3], ",", "", -1), 10, 64)
+	return data.Items, nil
+}
+
+// getDay 擷取一個交易日的資料，欄位解析失敗時記錄在 p
+func (b *BFI82U) getDay(date time.Time, p *parser) (BFI82UData, error) {
+	var result = BFI82UData{Date: date}
+	data, err := b.client.fetchTWSE(b.fetcher).PostForm(b.url(date), nil)
+	if err != nil {
+		return result, fmt.Errorf(errorNetworkFail.Error(), err)
+	}
+	table, err := b.client.parse(schema{name: "BFI82U " + date.Format("20060102"), columns: bfi82uSchema}, data)
+	if err != nil {
+		return result, err
+	}
+	b.report = p.report
+	for _, r := range table.records {
+		p.next()
+		var v = BaseSellBuy{
+			Name:  strings.TrimSpace(r.get("Name")),
+			Buy:   p.int("Buy", r.get("Buy")),
+			Sell:  p.int("Sell", r.get("Sell")),
+			Total: p.int("Total", r.get("Total")),
+		}
+		if err := p.err(); err != nil {
+			return result, err
 		}
+		if p.bad {
+			continue
+		}
+		result.add(v)
+	}
+	return result, nil
+}
+
+// GetRange 擷取 Begin 到 End 之間每個開市日的資料，依日期排序；沒有資料的日期略過
+func (b *BFI82U) GetRange() ([]BFI82UData, error) {
+	var (
+		p      = newParser("BFI82U", b.client.Strict())
+		result []BFI82UData
+	)
+	err := loadEach(openDays(b.client.Calendar(), b.Begin, b.End), nil, func(day time.Time) error {
+		data, err := b.getDay(day, p)
+		if err != nil {
+			return err
+		}
+		result = append(result, data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return result, err
+	b.report = p.report
+	return result, nil
 }
 
-// T86 取得「三大法人買賣超日報(股)」
+// Report 回傳最近一次 Get、GetRange 的驗證報告
+func (b BFI82U) Report() *Report {
+	return b.report
+}
+
+// T86 取得「三大法人買賣超日報(股)」，上櫃為「三大法人買賣明細資訊」
 type T86 struct {
-	Date time.Time
+	Date     time.Time
+	exchange string
+	report   *Report
+	fetcher  Fetcher
+	client   *Client
 }
 
 // URL 擷取網址
 func (t T86) URL() string {
-	return fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.T86, t.Date.Year(), t.Date.Month(), t.Date.Day()))
+	if t.exchange == "otc" {
+		return otcInstiURL(t.client, t.Date)
+	}
+	return t.client.twseURL(fmt.Sprintf(utils.T86, t.Date.Year(), t.Date.Month(), t.Date.Day()))
 }
 
 // T86Data 各欄位資料
 type T86Data struct {
-	No     string
-	Name   string
-	FII    BaseSellBuy // 外資
-	SIT    BaseSellBuy // 投信
-	DProp  BaseSellBuy // 自營商(自行買賣)
-	DHedge BaseSellBuy // 自營商(避險)
-	Diff   int64       // 三大法人買賣超股數
+	No     string      `json:"no"`
+	Name   string      `json:"name"`
+	FII    BaseSellBuy `json:"fii"`     // 外資
+	SIT    BaseSellBuy `json:"sit"`     // 投信
+	DProp  BaseSellBuy `json:"d_prop"`  // 自營商(自行買賣)
+	DHedge BaseSellBuy `json:"d_hedge"` // 自營商(避險)
+	Diff   int64       `json:"diff"`    // 三大法人買賣超股數
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (t *T86) SetFetcher(f Fetcher) *T86 {
+	t.fetcher = f
+	return t
 }
 
-// Get 擷取資料
-func (t T86) Get(cate string) ([]T86Data, error) {
-	data, err := hCache.PostForm(t.URL(), nil)
+// Get 擷取資料，欄位解析失敗時記錄在 Report()，嚴格模式回傳 *ParseError
+func (t *T86) Get(cate string) ([]T86Data, error) {
+	table, err := t.fetch()
 	if err != nil {
 		return nil, err
 	}
-	csvArrayContent := strings.Split(string(data), "\n")[2:]
-	for i, v := range csvArrayContent {
-		csvArrayContent[i] = strings.Replace(v, "=", "", -1)
+	var (
+		p      = newParser("T86", t.client.Strict())
+		result = make([]T86Data, 0, len(table.records))
+	)
+	t.report = p.report
+	for _, r := range table.records {
+		p.next()
+		var v = T86Data{
+			No:    r.get("No"),
+			Name:  r.get("Name"),
+			FII:   sellBuy(p, r, "FII."),
+			SIT:   sellBuy(p, r, "SIT."),
+			DProp: sellBuy(p, r, "DProp."),
+		}
+		if r.has("DHedge.Buy") {
+			v.DHedge = sellBuy(p, r, "DHedge.")
+		}
+		v.Diff = p.int("Diff", r.get("Diff"))
+		if err := p.err(); err != nil {
+			return nil, err
+		}
+		if p.bad {
+			p.skip()
+			continue
+		}
+		result = append(result, v)
 	}
-	var result []T86Data
-	if csvdata, err := csv.NewReader(strings.NewReader(strings.Join(csvArrayContent[2:len(csvArrayContent)-8], "\n"))).ReadAll(); err == nil {
-		result = make([]T86Data, len(csvdata))
-		for i, v := range csvdata {
-			if len(v) >= 11 {
-				result[i].No = v[0]
-				result[i].Name = v[1]
-
-				result[i].FII.Buy, _ = strconv.ParseInt(strings.Replace(v[2], ",", "", -1), 10, 64)
-				result[i].FII.Sell, _ = strconv.ParseInt(strings.Replace(v[3], ",", "", -1), 10, 64)
-				result[i].FII.Total = result[i].FII.Buy - result[i].FII.Sell
-
-				result[i].SIT.Buy, _ = strconv.ParseInt(strings.Replace(v[4], ",", "", -1), 10, 64)
-				result[i].SIT.Sell, _ = strconv.ParseInt(strings.Replace(v[5], ",", "", -1), 10, 64)
-				result[i].SIT.Total = result[i].SIT.Buy - result[i].SIT.Sell
+	return result, nil
+}
 
-				result[i].DProp.Buy, _ = strconv.ParseInt(strings.Replace(v[6], ",", "", -1), 10, 64)
-				result[i].DProp.Sell, _ = strconv.ParseInt(strings.Replace(v[7], ",", "", -1), 10, 64)
-				result[i].DProp.Total = result[i].DProp.Buy - result[i].DProp.Sell
+// fetch 擷取並依表頭解析上市或上櫃的資料
+func (t *T86) fetch() (*table, error) {
+	if t.exchange == "otc" {
+		return getOTC(t.client.fetch(t.fetcher), t.URL(), schema{name: "OTC T86", columns: otcT86Schema})
+	}
+	data, err := t.client.fetchTWSE(t.fetcher).PostForm(t.URL(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return t.client.parse(schema{name: "T86", columns: t86Schema}, data)
+}
 
-				result[i].DHedge.Buy, _ = strconv.ParseInt(strings.Replace(v[8], ",", "", -1), 10, 64)
-				result[i].DHedge.Sell, _ = strconv.ParseInt(strings.Replace(v[9], ",", "", -1), 10, 64)
-				result[i].DHedge.Total = result[i].DHedge.Buy - result[i].DHedge.Sell
+// t86Schema 三大法人買賣超日報（T86）的欄位，包含歷次改版的標題
+var t86Schema = []column{
+	col("No", "證券代號"),
+	col("Name", "證券名稱"),
+	col("FII.Buy", "外資買進股數", "外陸資買進股數(不含外資自營商)"),
+	col("FII.Sell", "外資賣出股數", "外陸資賣出股數(不含外資自營商)"),
+	col("SIT.Buy", "投信買進股數"),
+	col("SIT.Sell", "投信賣出股數"),
+	col("DProp.Buy", "自營商買進股數(自行買賣)", "自營商買進股數"),
+	col("DProp.Sell", "自營商賣出股數(自行買賣)", "自營商賣出股數"),
+	optCol("DHedge.Buy", "自營商買進股數(避險)"),
+	optCol("DHedge.Sell", "自營商賣出股數(避險)"),
+	col("Diff", "三大法人買賣超股數"),
+}
 
-				result[i].Diff, _ = strconv.ParseInt(strings.Replace(v[10], ",", "", -1), 10, 64)
-			}
-		}
-	} else {
-		return nil, err
+// sellBuy 解析 prefix 開頭的買進、賣出欄位，合計為買進減賣出
+func sellBuy(p *parser, r record, prefix string) BaseSellBuy {
+	var result = BaseSellBuy{
+		Buy:  p.int(prefix+"Buy", r.get(prefix+"Buy")),
+		Sell: p.int(prefix+"Sell", r.get(prefix+"Sell")),
 	}
-	return result, err
+	result.Total = result.Buy - result.Sell
+	return result
+}
+
+// Report 回傳最近一次 Get 的驗證報告
+func (t T86) Report() *Report {
+	return t.report
 }
 
 type unixMapMTSSData map[int64]map[string]BaseMTSS
@@ -166,15 +366,15 @@ type unixMapT43UData map[int64]map[string]BaseT43U
 type unixMapT44UData map[int64]map[string]BaseT44U
 
 type TradingVolume struct {
-	Buy   int64 // 買進
-	Sell  int64 // 賣出
-	Total int64 // 合計
+	Buy   int64 `json:"buy"`   // 買進
+	Sell  int64 `json:"sell"`  // 賣出
+	Total int64 `json:"total"` // 合計
 }
 type BaseMTSS struct {
-	No   string
-	Name string
-	MT   TradingVolume
-	SS   TradingVolume
+	No   string        `json:"no"`
+	Name string        `json:"name"`
+	MT   TradingVolume `json:"mt"`
+	SS   TradingVolume `json:"ss"`
 }
 type BaseT38U struct {
 	No     string
@@ -197,20 +397,36 @@ type BaseT44U struct {
 type TWT38U struct {
 	Date            time.Time
 	UnixMapT38UData unixMapT38UData
+	exchange        string
+	report          *Report
+	fetcher         Fetcher
+	client          *Client
 }
 type TWT43U struct {
 	Date            time.Time
 	UnixMapT43UData unixMapT43UData
+	exchange        string
+	report          *Report
+	fetcher         Fetcher
+	client          *Client
 }
 type TWT44U struct {
 	Date            time.Time
 	UnixMapT44UData unixMapT44UData
+	exchange        string
+	report          *Report
+	fetcher         Fetcher
+	client          *Client
 }
 
 type TWMTSS struct {
 	Date            time.Time
 	Category        string
 	UnixMapMTSSData unixMapMTSSData
+	exchange        string
+	report          *Report
+	fetcher         Fetcher
+	client          *Client
 }
 
 // NewTWMTSS 融資融券匯總 Margin Trading and Short Selling
@@ -222,14 +438,16 @@ func NewTWMTSS(date time.Time, category string) *TWMTSS {
 	}
 }
 func (t TWMTSS) URL() string {
-	return fmt.Sprintf("%s%s", utils.TWSEHOST,
-		fmt.Sprintf(utils.TWMTSS,
-			t.Date.Year(), t.Date.Month(), t.Date.Day(),
-			t.Category))
+	if t.exchange == "otc" {
+		return fmt.Sprintf("%s%s", t.client.OTCHost(), fmt.Sprintf(utils.OTCMTSS, otcDate(t.Date)))
+	}
+	return t.client.twseURL(fmt.Sprintf(utils.TWMTSS,
+		t.Date.Year(), t.Date.Month(), t.Date.Day(),
+		t.Category))
 
 }
 func (t *TWMTSS) Round() {
-	t.Date = tradingdays.FindRecentlyOpened(t.Date.AddDate(0, 0, -1))
+	t.Date = t.client.Calendar().FindRecentlyOpened(t.Date.AddDate(0, 0, -1))
 }
 
 func (t *TWMTSS) SetDate(date time.Time) *TWMTSS {
@@ -249,6 +467,30 @@ func (t *TWT44U) SetDate(date time.Time) *TWT44U {
 	return t
 }
 
+// SetFetcher 指定擷取資料的 Fetcher
+func (t *TWMTSS) SetFetcher(f Fetcher) *TWMTSS {
+	t.fetcher = f
+	return t
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (t *TWT38U) SetFetcher(f Fetcher) *TWT38U {
+	t.fetcher = f
+	return t
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (t *TWT43U) SetFetcher(f Fetcher) *TWT43U {
+	t.fetcher = f
+	return t
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (t *TWT44U) SetFetcher(f Fetcher) *TWT44U {
+	t.fetcher = f
+	return t
+}
+
 //IsOverBought 獲取該日某股的融資融券是否為增
 func (t *TWMTSS) IsOverBought(date time.Time, stockNo string) (bool, []int64, error) {
 	var (
@@ -277,59 +519,75 @@ func (t *TWMTSS) Get() (map[string]BaseMTSS, error) {
 	if v, ok := t.UnixMapMTSSData[dateUnix]; ok {
 		return v, nil
 	}
-	var (
-		csvdata   [][]string
-		data      []byte
-		err       error
-		resultMap map[string]BaseMTSS
-	)
 	//fmt.Println(t.URL())
-	if data, err = hCache.PostForm(t.URL(), nil); err != nil {
+	table, err := t.fetch()
+	if err != nil {
 		return nil, err
 	}
-	var csvArrayContent = strings.Split(string(data), "\n")
-	if len(csvArrayContent) < 14 {
-		if err := os.Remove(utils.GetMD5FilePath(t)); err != nil {
+	var p = newParser("MI_MARGN "+t.Category, t.client.Strict())
+	t.report = p.report
+	resultMap := make(map[string]BaseMTSS, len(table.records))
+	for _, v := range table.records {
+		p.next()
+		var r BaseMTSS
+		no := strings.Replace(v.get("No"), " ", "", -1)
+
+		r.Name = strings.Replace(v.get("Name"), " ", "", -1)
+
+		r.MT.Buy = p.int("MT.Buy", v.get("MT.Buy"))
+		r.MT.Sell = p.int("MT.Sell", v.get("MT.Sell"))
+		//TODO:確認是否是這樣計算總數
+		r.MT.Total = r.MT.Buy - r.MT.Sell
+
+		r.SS.Buy = p.int("SS.Buy", v.get("SS.Buy"))
+		r.SS.Sell = p.int("SS.Sell", v.get("SS.Sell"))
+		//TODO:確認是否是這樣計算總數
+		r.SS.Total = r.SS.Sell - r.SS.Buy
+		if err := p.err(); err != nil {
 			return nil, err
 		}
-		return nil, errorFileNoData
+		if p.bad || no == "合計" {
+			p.skip()
+			continue
+		}
+		resultMap[no] = r
 	}
-	//從第八列開始 然後刪掉最後面的八行(注意可能會有空白的行)
-	csvArrayContent = csvArrayContent[7 : len(csvArrayContent)-8]
+	t.UnixMapMTSSData[dateUnix] = resultMap
+	return resultMap, nil
+}
 
-	for i, v := range csvArrayContent {
-		csvArrayContent[i] = strings.Replace(v, "=", "", -1)
+// fetch 擷取並依表頭解析上市或上櫃的資料，查無資料時移除快取並回傳 errorFileNoData
+func (t *TWMTSS) fetch() (*table, error) {
+	if t.exchange == "otc" {
+		return getOTC(t.client.fetch(t.fetcher), t.URL(), schema{name: "OTC MTSS", columns: otcMTSSSchema})
 	}
-
-	if csvdata, err = csv.NewReader(strings.NewReader(strings.Join(csvArrayContent, "\n"))).ReadAll(); err == nil {
-		resultMap = make(map[string]BaseMTSS, len(csvdata))
-		for i, v := range csvdata {
-			if i == 0 {
-				if false == checkCsvDataFormat("MTSS", v) {
-					return nil, errors.New("Wrong MTSS Csv Data Format")
-				}
-				continue
-			}
-			var r BaseMTSS
-			no := strings.Replace(v[0], " ", "", -1)
-
-			r.Name = strings.Replace(v[1], " ", "", -1)
-
-			r.MT.Buy, _ = strconv.ParseInt(strings.Replace(v[2], ",", "", -1), 10, 64)
-			r.MT.Sell, _ = strconv.ParseInt(strings.Replace(v[3], ",", "", -1), 10, 64)
-			//TODO:確認是否是這樣計算總數
-			r.MT.Total = r.MT.Buy - r.MT.Sell
-
-			r.SS.Buy, _ = strconv.ParseInt(strings.Replace(v[8], ",", "", -1), 10, 64)
-			r.SS.Sell, _ = strconv.ParseInt(strings.Replace(v[9], ",", "", -1), 10, 64)
-			//TODO:確認是否是這樣計算總數
-			r.SS.Total = r.SS.Sell - r.SS.Buy
-			resultMap[no] = r
-
+	data, err := t.client.fetchTWSE(t.fetcher).PostForm(t.URL(), nil)
+	if err != nil {
+		return nil, err
+	}
+	table, err := t.client.parse(schema{name: "MI_MARGN " + t.Category, columns: mtssSchema}, data)
+	if errors.Cause(err) == errorNotEnoughData {
+		if err := removeCache(t.client.fetchTWSE(t.fetcher), t.URL()); err != nil {
+			return nil, err
 		}
-		t.UnixMapMTSSData[dateUnix] = resultMap
+		return nil, errorFileNoData
 	}
-	return resultMap, err
+	return table, err
+}
+
+// mtssSchema 融資融券彙總（MI_MARGN）的欄位，融資與融券的標題相同，依序為第一組與第二組
+var mtssSchema = []column{
+	col("No", "股票代號", "代號"),
+	col("Name", "股票名稱", "名稱"),
+	nthCol("MT.Buy", 1, "買進"),
+	nthCol("MT.Sell", 1, "賣出"),
+	nthCol("SS.Buy", 2, "買進"),
+	nthCol("SS.Sell", 2, "賣出"),
+}
+
+// Report 回傳最近一次 Get 的驗證報告
+func (t TWMTSS) Report() *Report {
+	return t.report
 }
 
 func (t *TWMTSS) GetData() (map[string]BaseMTSS, error) {
@@ -486,24 +744,26 @@ func (t *TWT44U) IsOverBoughtDates(stockNo string, days int) (bool, bool, []int6
 
 // TWTXXU 產生 自營商、投信、外資及陸資買賣超彙總表
 type TWTXXU struct {
-	Date time.Time
-	fund string
+	Date    time.Time
+	fund    string
+	fetcher Fetcher
+	client  *Client
 }
 
 func (t *TWTXXU) Round() {
-	t.Date = tradingdays.FindRecentlyOpened(t.Date)
+	t.Date = t.client.Calendar().FindRecentlyOpened(t.Date)
 }
 
 func (t *TWT38U) Round() {
-	t.Date = tradingdays.FindRecentlyOpened(t.Date)
+	t.Date = t.client.Calendar().FindRecentlyOpened(t.Date)
 }
 
 func (t *TWT43U) Round() {
-	t.Date = tradingdays.FindRecentlyOpened(t.Date)
+	t.Date = t.client.Calendar().FindRecentlyOpened(t.Date)
 }
 
 func (t *TWT44U) Round() {
-	t.Date = tradingdays.FindRecentlyOpened(t.Date)
+	t.Date = t.client.Calendar().FindRecentlyOpened(t.Date)
 }
 
 // NewTWT38U 外資及陸資買賣超彙總表
@@ -532,280 +792,247 @@ func NewTWT44U(date time.Time) *TWT44U {
 
 // URL 擷取網址
 func (t TWT38U) URL() string {
-
-	return fmt.Sprintf("%s%s", utils.TWSEHOST,
+	if t.exchange == "otc" {
+		return otcInstiURL(t.client, t.Date)
+	}
+	return fmt.Sprintf("%s%s", t.client.TWSEHost(),
 		fmt.Sprintf(utils.TWTXXU, "TWT38U", t.Date.Year(), t.Date.Month(), t.Date.Day()))
 }
 func (t TWT43U) URL() string {
-
-	return fmt.Sprintf("%s%s", utils.TWSEHOST,
+	if t.exchange == "otc" {
+		return otcInstiURL(t.client, t.Date)
+	}
+	return fmt.Sprintf("%s%s", t.client.TWSEHost(),
 		fmt.Sprintf(utils.TWTXXU, "TWT43U", t.Date.Year(), t.Date.Month(), t.Date.Day()))
 }
 func (t TWT44U) URL() string {
-
-	return fmt.Sprintf("%s%s", utils.TWSEHOST,
+	if t.exchange == "otc" {
+		return otcInstiURL(t.client, t.Date)
+	}
+	return fmt.Sprintf("%s%s", t.client.TWSEHost(),
 		fmt.Sprintf(utils.TWTXXU, "TWT44U", t.Date.Year(), t.Date.Month(), t.Date.Day()))
 }
 
-func checkCsvDataFormat(t string, data []string) bool {
+// twtSchema 外資（TWT38U）與投信（TWT44U）買賣超彙總表的欄位
+var twtSchema = []column{
+	col("No", "證券代號"),
+	col("Name", "證券名稱"),
+	col("Buy", "買進股數"),
+	col("Sell", "賣出股數"),
+	col("Total", "買賣超股數"),
+}
 
-	switch t {
-	case "TeckCsvDataFormatMTSS":
-		return "賣出" == strings.Replace(data[9], " ", "", -1) &&
-			"買進" == strings.Replace(data[8], " ", "", -1) &&
-			"賣出" == strings.Replace(data[3], " ", "", -1) &&
-			"買進" == strings.Replace(data[2], " ", "", -1) &&
-			"股票名稱" == strings.Replace(data[1], " ", "", -1) &&
-			"股票代號" == strings.Replace(data[0], " ", "", -1)
-	default:
-		return true
-	}
+// twt43USchema 自營商（TWT43U）買賣超彙總表的欄位，表頭為兩列，依序為自行買賣與避險
+var twt43USchema = []column{
+	col("No", "證券代號"),
+	col("Name", "證券名稱"),
+	nthCol("DProp.Buy", 1, "買進股數"),
+	nthCol("DProp.Sell", 1, "賣出股數"),
+	nthCol("DProp.Total", 1, "買賣超股數"),
+	nthCol("DHedge.Buy", 2, "買進股數"),
+	nthCol("DHedge.Sell", 2, "賣出股數"),
+	nthCol("DHedge.Total", 2, "買賣超股數"),
 }
 
-func (t *TWT38U) Get() (map[string]BaseT38U, error) {
-	dateUnix := time.Date(t.Date.Year(), t.Date.Month(), t.Date.Day(), 0, 0, 0, 0, t.Date.Location()).Unix()
-	if v, ok := t.UnixMapT38UData[dateUnix]; ok {
-		return v, nil
+// fetchTWTXXU 擷取並依表頭解析買賣超彙總表，查無資料時移除快取並回傳 errorFileNoData；
+// 上櫃以三大法人買賣明細資訊取代
+func fetchTWTXXU(f Fetcher, url, fund, exchange string) (*table, error) {
+	if exchange == "otc" {
+		return getOTC(f, url, schema{name: "OTC " + fund, columns: otcTWTXXUSchema[fund]})
 	}
-	var (
-		csvdata   [][]string
-		data      []byte
-		err       error
-		resultMap map[string]BaseT38U
-	)
-	//fmt.Println(t.URL())
-	if data, err = hCache.PostForm(t.URL(), nil); err != nil {
+	data, err := f.PostForm(url, nil)
+	if err != nil {
 		return nil, err
 	}
-	var csvArrayContent = strings.Split(string(data), "\n")
-	if len(csvArrayContent) < 9 {
-		if err := os.Remove(utils.GetMD5FilePath(t)); err != nil {
+	var columns = twtSchema
+	if fund == "TWT43U" {
+		columns = twt43USchema
+	}
+	table, err := schema{name: fund, columns: columns}.parse(data)
+	if errors.Cause(err) == errorNotEnoughData {
+		if err := removeCache(f, url); err != nil {
 			return nil, err
 		}
 		return nil, errorFileNoData
 	}
-	//從第八列開始 然後刪掉最後面的八行(注意可能會有空白的行)
-	csvArrayContent = csvArrayContent[3 : len(csvArrayContent)-9]
+	return table, err
+}
 
-	for i, v := range csvArrayContent {
-		csvArrayContent[i] = strings.Replace(v, "=", "", -1)
+// tradingVolume 解析 prefix 開頭的買進、賣出、買賣超欄位
+func tradingVolume(p *parser, r record, prefix string) TradingVolume {
+	return TradingVolume{
+		Buy:   p.int(prefix+"Buy", r.get(prefix+"Buy")),
+		Sell:  p.int(prefix+"Sell", r.get(prefix+"Sell")),
+		Total: p.int(prefix+"Total", r.get(prefix+"Total")),
 	}
+}
 
-	if csvdata, err = csv.NewReader(strings.NewReader(strings.Join(csvArrayContent, "\n"))).ReadAll(); err == nil {
-		resultMap = make(map[string]BaseT38U, len(csvdata))
-		for i, v := range csvdata {
-			if i == 0 {
-				if false == checkCsvDataFormat("T38U", v) {
-					return nil, errors.New("Wrong T38U Csv Data Format")
-				}
-				continue
-			}
-			var r BaseT38U
-			no := strings.Replace(v[1], " ", "", -1)
-
-			r.Name = strings.Replace(v[2], " ", "", -1)
-
-			r.Volume.Buy, _ = strconv.ParseInt(strings.Replace(v[3], ",", "", -1), 10, 64)
-			r.Volume.Sell, _ = strconv.ParseInt(strings.Replace(v[4], ",", "", -1), 10, 64)
-			r.Volume.Total, _ = strconv.ParseInt(strings.Replace(v[5], ",", "", -1), 10, 64)
-
-			resultMap[no] = r
+// add 合計兩組買賣股數
+func (v TradingVolume) add(o TradingVolume) TradingVolume {
+	return TradingVolume{Buy: v.Buy + o.Buy, Sell: v.Sell + o.Sell, Total: v.Total + o.Total}
+}
 
+func (t *TWT38U) Get() (map[string]BaseT38U, error) {
+	dateUnix := time.Date(t.Date.Year(), t.Date.Month(), t.Date.Day(), 0, 0, 0, 0, t.Date.Location()).Unix()
+	if v, ok := t.UnixMapT38UData[dateUnix]; ok {
+		return v, nil
+	}
+	//fmt.Println(t.URL())
+	table, err := fetchTWTXXU(t.client.fetch(t.fetcher), t.URL(), "TWT38U", t.exchange)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		p         = newParser("TWT38U", t.client.Strict())
+		resultMap = make(map[string]BaseT38U, len(table.records))
+	)
+	t.report = p.report
+	for _, v := range table.records {
+		p.next()
+		var r BaseT38U
+		no := strings.Replace(v.get("No"), " ", "", -1)
+		r.Name = strings.Replace(v.get("Name"), " ", "", -1)
+		r.Volume = tradingVolume(p, v, "")
+		if err := p.err(); err != nil {
+			return nil, err
+		}
+		if p.bad {
+			p.skip()
+			continue
 		}
-		t.UnixMapT38UData[dateUnix] = resultMap
+		resultMap[no] = r
 	}
-	return resultMap, err
+	t.UnixMapT38UData[dateUnix] = resultMap
+	return resultMap, nil
 }
+
+// Get 擷取資料，Volume 為自行買賣與避險的合計
 func (t *TWT43U) Get() (map[string]BaseT43U, error) {
 	dateUnix := time.Date(t.Date.Year(), t.Date.Month(), t.Date.Day(), 0, 0, 0, 0, t.Date.Location()).Unix()
 	if v, ok := t.UnixMapT43UData[dateUnix]; ok {
 		return v, nil
 	}
-	var (
-		csvdata   [][]string
-		data      []byte
-		err       error
-		resultMap map[string]BaseT43U
-	)
 	//fmt.Println(t.URL())
-	if data, err = hCache.PostForm(t.URL(), nil); err != nil {
+	table, err := fetchTWTXXU(t.client.fetch(t.fetcher), t.URL(), "TWT43U", t.exchange)
+	if err != nil {
 		return nil, err
 	}
-	var csvArrayContent = strings.Split(string(data), "\n")
-	if len(csvArrayContent) < 5 {
-		if err := os.Remove(utils.GetMD5FilePath(t)); err != nil {
+	var (
+		p         = newParser("TWT43U", t.client.Strict())
+		resultMap = make(map[string]BaseT43U, len(table.records))
+	)
+	t.report = p.report
+	for _, v := range table.records {
+		p.next()
+		var r BaseT43U
+		no := strings.Replace(v.get("No"), " ", "", -1)
+		r.Name = strings.Replace(v.get("Name"), " ", "", -1)
+		r.Volume = tradingVolume(p, v, "DProp.").add(tradingVolume(p, v, "DHedge."))
+		if err := p.err(); err != nil {
 			return nil, err
 		}
-		return nil, errorFileNoData
-	}
-	//從第八列開始 然後刪掉最後面的八行(注意可能會有空白的行)
-	csvArrayContent = csvArrayContent[3 : len(csvArrayContent)-5]
-
-	for i, v := range csvArrayContent {
-		csvArrayContent[i] = strings.Replace(v, "=", "", -1)
-	}
-
-	if csvdata, err = csv.NewReader(strings.NewReader(strings.Join(csvArrayContent, "\n"))).ReadAll(); err == nil {
-		resultMap = make(map[string]BaseT43U, len(csvdata))
-		for i, v := range csvdata {
-			if i == 0 {
-				if false == checkCsvDataFormat("T43U", v) {
-					return nil, errors.New("Wrong T43U Csv Data Format")
-				}
-				continue
-			}
-			var r BaseT43U
-			no := strings.Replace(v[0], " ", "", -1)
-
-			r.Name = strings.Replace(v[1], " ", "", -1)
-
-			r.Volume.Buy, _ = strconv.ParseInt(strings.Replace(v[3], ",", "", -1), 10, 64)
-			r.Volume.Sell, _ = strconv.ParseInt(strings.Replace(v[4], ",", "", -1), 10, 64)
-			r.Volume.Total, _ = strconv.ParseInt(strings.Replace(v[5], ",", "", -1), 10, 64)
-
-			resultMap[no] = r
-
+		if p.bad {
+			p.skip()
+			continue
 		}
-		t.UnixMapT43UData[dateUnix] = resultMap
+		resultMap[no] = r
 	}
-	return resultMap, err
+	t.UnixMapT43UData[dateUnix] = resultMap
+	return resultMap, nil
 }
+
 func (t *TWT44U) Get() (map[string]BaseT44U, error) {
 	dateUnix := time.Date(t.Date.Year(), t.Date.Month(), t.Date.Day(), 0, 0, 0, 0, t.Date.Location()).Unix()
 	if v, ok := t.UnixMapT44UData[dateUnix]; ok {
 		return v, nil
 	}
-	var (
-		csvdata   [][]string
-		data      []byte
-		err       error
-		resultMap map[string]BaseT44U
-	)
 	//fmt.Println(t.URL())
-	if data, err = hCache.PostForm(t.URL(), nil); err != nil {
+	table, err := fetchTWTXXU(t.client.fetch(t.fetcher), t.URL(), "TWT44U", t.exchange)
+	if err != nil {
 		return nil, err
 	}
-	var csvArrayContent = strings.Split(string(data), "\n")
-	if len(csvArrayContent) < 9 {
-		if err := os.Remove(utils.GetMD5FilePath(t)); err != nil {
+	var (
+		p         = newParser("TWT44U", t.client.Strict())
+		resultMap = make(map[string]BaseT44U, len(table.records))
+	)
+	t.report = p.report
+	for _, v := range table.records {
+		p.next()
+		var r BaseT44U
+		no := strings.Replace(v.get("No"), " ", "", -1)
+		r.Name = strings.Replace(v.get("Name"), " ", "", -1)
+		r.Volume = tradingVolume(p, v, "")
+		if err := p.err(); err != nil {
 			return nil, err
 		}
-		return nil, errorFileNoData
-	}
-	//從第八列開始 然後刪掉最後面的八行(注意可能會有空白的行)
-	csvArrayContent = csvArrayContent[2 : len(csvArrayContent)-9]
-
-	for i, v := range csvArrayContent {
-		csvArrayContent[i] = strings.Replace(v, "=", "", -1)
+		if p.bad {
+			p.skip()
+			continue
+		}
+		resultMap[no] = r
 	}
+	t.UnixMapT44UData[dateUnix] = resultMap
+	return resultMap, nil
+}
 
-	if csvdata, err = csv.NewReader(strings.NewReader(strings.Join(csvArrayContent, "\n"))).ReadAll(); err == nil {
-		resultMap = make(map[string]BaseT44U, len(csvdata))
-		for i, v := range csvdata {
-			if i == 0 {
-				if false == checkCsvDataFormat("T44U", v) {
-					return nil, errors.New("Wrong T44U Csv Data Format")
-				}
-				continue
-			}
-			var r BaseT44U
-			no := strings.Replace(v[1], " ", "", -1)
-
-			r.Name = strings.Replace(v[2], " ", "", -1)
-
-			r.Volume.Buy, _ = strconv.ParseInt(strings.Replace(v[3], ",", "", -1), 10, 64)
-			r.Volume.Sell, _ = strconv.ParseInt(strings.Replace(v[4], ",", "", -1), 10, 64)
-			r.Volume.Total, _ = strconv.ParseInt(strings.Replace(v[5], ",", "", -1), 10, 64)
+// Report 回傳最近一次 Get 的驗證報告
+func (t TWT38U) Report() *Report {
+	return t.report
+}
 
-			resultMap[no] = r
+// Report 回傳最近一次 Get 的驗證報告
+func (t TWT43U) Report() *Report {
+	return t.report
+}
 
-		}
-		t.UnixMapT44UData[dateUnix] = resultMap
-	}
-	return resultMap, err
+// Report 回傳最近一次 Get 的驗證報告
+func (t TWT44U) Report() *Report {
+	return t.report
 }
+
 func (t TWTXXU) URL() string {
-	return fmt.Sprintf("%s%s", utils.TWSEHOST,
+	return fmt.Sprintf("%s%s", t.client.TWSEHost(),
 		fmt.Sprintf(utils.TWTXXU, t.fund, t.Date.Year(), t.Date.Month(), t.Date.Day()))
 
 }
 
-// Get 擷取資料
+// SetFetcher 指定擷取資料的 Fetcher
+func (t *TWTXXU) SetFetcher(f Fetcher) *TWTXXU {
+	t.fetcher = f
+	return t
+}
+
+// Get 擷取資料，TWT43U 依序為自行買賣、避險與合計
 func (t TWTXXU) Get() ([][]BaseSellBuy, error) {
-	var (
-		csvdata  [][]string
-		data     []byte
-		datalist int
-		err      error
-		result   [][]BaseSellBuy
-	)
 	fmt.Println(t.URL())
-	if data, err = hCache.PostForm(t.URL(), nil); err != nil {
+	table, err := fetchTWTXXU(t.client.fetch(t.fetcher), t.URL(), t.fund, "tse")
+	if err != nil {
 		return nil, err
 	}
-	var csvArrayContent = strings.Split(string(data), "\n")
-	switch t.fund {
-	case "TWT38U":
-		if len(csvArrayContent) < 9 {
-			return nil, errorFileNoData
-		}
-		csvArrayContent = csvArrayContent[3 : len(csvArrayContent)-9]
-		datalist = 1
-	case "TWT43U":
-		if len(csvArrayContent) < 5 {
-			return nil, errorFileNoData
+	var (
+		p      = newParser(t.fund, t.client.Strict())
+		result = make([][]BaseSellBuy, len(table.records))
+	)
+	for i, r := range table.records {
+		p.next()
+		var volumes []TradingVolume
+		if t.fund == "TWT43U" {
+			prop, hedge := tradingVolume(p, r, "DProp."), tradingVolume(p, r, "DHedge.")
+			volumes = []TradingVolume{prop, hedge, prop.add(hedge)}
+		} else {
+			volumes = []TradingVolume{tradingVolume(p, r, "")}
 		}
-		csvArrayContent = csvArrayContent[3 : len(csvArrayContent)-5]
-		datalist = 3
-	case "TWT44U":
-		if len(csvArrayContent) < 9 {
-			return nil, errorFileNoData
+		if err := p.err(); err != nil {
+			return nil, err
 		}
-		csvArrayContent = csvArrayContent[2 : len(csvArrayContent)-9]
-		//fmt.Println(csvArrayContent)
-		datalist = 1
-	}
-
-	for i, v := range csvArrayContent {
-
-		csvArrayContent[i] = strings.Replace(v, "=", "", -1)
-
-		//fmt.Println(csvArrayContent[i])
-	}
-
-	if csvdata, err = csv.NewReader(strings.NewReader(strings.Join(csvArrayContent, "\n"))).ReadAll(); err == nil {
-
-		result = make([][]BaseSellBuy, len(csvdata))
-		for i, v := range csvdata {
-			var name, no string
-			result[i] = make([]BaseSellBuy, datalist)
-			switch {
-			case datalist == 1:
-				name = strings.Replace(v[2], " ", "", -1)
-				no = strings.Replace(v[1], " ", "", -1)
-			case datalist > 1:
-				name = strings.Replace(v[1], " ", "", -1)
-				no = strings.Replace(v[0], " ", "", -1)
-			}
-			result[i][0].Name = name
-			result[i][0].No = no
-			result[i][0].Buy, _ = strconv.ParseInt(
-				strings.Replace(v[3], ",", "", -1), 10, 64)
-			result[i][0].Sell, _ = strconv.ParseInt(strings.Replace(v[4], ",", "", -1), 10, 64)
-			result[i][0].Total, _ = strconv.ParseInt(strings.Replace(v[5], ",", "", -1), 10, 64)
-			if datalist > 1 {
-				result[i][1].Name = name
-				result[i][1].No = no
-				result[i][1].Buy, _ = strconv.ParseInt(strings.Replace(v[5], ",", "", -1), 10, 64)
-				result[i][1].Sell, _ = strconv.ParseInt(strings.Replace(v[6], ",", "", -1), 10, 64)
-				result[i][1].Total, _ = strconv.ParseInt(strings.Replace(v[7], ",", "", -1), 10, 64)
-				result[i][2].Name = name
-				result[i][2].No = no
-				result[i][2].Buy, _ = strconv.ParseInt(strings.Replace(v[8], ",", "", -1), 10, 64)
-				result[i][2].Sell, _ = strconv.ParseInt(strings.Replace(v[9], ",", "", -1), 10, 64)
-				result[i][2].Total, _ = strconv.ParseInt(strings.Replace(v[10], ",", "", -1), 10, 64)
-			}
+		var (
+			no   = strings.Replace(r.get("No"), " ", "", -1)
+			name = strings.Replace(r.get("Name"), " ", "", -1)
+		)
+		result[i] = make([]BaseSellBuy, len(volumes))
+		for j, v := range volumes {
+			result[i][j] = BaseSellBuy{No: no, Name: name, Buy: v.Buy, Sell: v.Sell, Total: v.Total}
 		}
 	}
-	return result, err
+	return result, nil
 }
@@ -1,6 +1,7 @@
 package twse
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -37,13 +38,122 @@ func TestT86_Get(t *testing.T) {
 
 func TestTWTXXU_Get(t *testing.T) {
 	date := time.Date(2015, 5, 26, 0, 0, 0, 0, utils.TaipeiTimeZone)
-	for _, v := range []*TWTXXU{NewTWT38U(date), NewTWT44U(date), NewTWT43U(date)} {
-		t.Log(v.URL())
-		if data, err := v.Get(); err == nil {
-			t.Log(len(data), err)
-			t.Logf("%+v\n", data[len(data)-1])
-		} else {
-			t.Error(err)
+	t38 := NewTWT38U(date)
+	t.Log(t38.URL())
+	if data, err := t38.Get(); err == nil {
+		t.Log(len(data), err)
+	} else {
+		t.Error(err)
+	}
+	t43 := NewTWT43U(date)
+	t.Log(t43.URL())
+	if data, err := t43.Get(); err == nil {
+		t.Log(len(data), err)
+	} else {
+		t.Error(err)
+	}
+	t44 := NewTWT44U(date)
+	t.Log(t44.URL())
+	if data, err := t44.Get(); err == nil {
+		t.Log(len(data), err)
+	} else {
+		t.Error(err)
+	}
+}
+
+func TestQFIISTOP20_Rank(t *testing.T) {
+	var (
+		date   = time.Date(2017, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		client = NewClient(WithFetcher(memFetcher{
+			fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.QFIISTOP20, 2017, 3, 1)): `"106年03月01日 外資及陸資持股比率前二十名彙總表"
+"排名","證券代號","證券名稱","國際證券編碼","發行股數","外資及陸資尚可投資股數","全體外資及陸資持有股數","外資及陸資尚可投資比率","全體外資及陸資持股比率","外資及陸資共用法令投資上限比率","陸資法令投資上限比率","與前日異動原因(註)","最近一次上市公司申報外資持股異動日期",
+"2","2330","台積電","TW0002330008","25,930,380,458","5,000,000,000","20,930,380,458","19.28","80.71","100.00","50.00","","1060301",
+"1","2379","瑞昱","TW0002379005","508,000,000","50,000,000","458,000,000","9.84","90.15","100.00","50.00","","1060301",
+"說明:"
+`,
+		}))
+	)
+	data, err := client.NewQFIISTOP20(date).Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 || data[0].Rank != 1 || data[0].No != "2379" || data[1].Name != "台積電" ||
+		data[1].Held != 20930380458 || data[1].Ratio != 80.71 || !data[1].Date.Equal(date) {
+		t.Errorf("Wrong data: %+v", data)
+	}
+}
+
+func bfi82uFixture(date, fii string) string {
+	return fmt.Sprintf(`"%s 三大法人買賣金額統計表"
+"單位名稱","買進金額","賣出金額","買賣差額"
+"自營商(自行買賣)","1,000","3,000","-2,000"
+"自營商(避險)","2,000","1,000","1,000"
+"投信","500","100","400"
+"外資及陸資(不含外資自營商)","%s","5,000","%s"
+"外資自營商","0","0","0"
+"合計","13,500","9,100","4,400"
+`, date, fii, fii)
+}
+
+func TestBFI82U_GetRange(t *testing.T) {
+	var (
+		url = func(day int) string {
+			return fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.BFI82U, 2017, 3, day))
 		}
+		client = NewClient(WithFetcher(dayFetcher(url, map[int]string{
+			1: bfi82uFixture("106年03月01日", "10,000"),
+			2: bfi82uFixture("106年03月02日", "6,000"),
+			3: fixtureNoData,
+		})))
+		b = client.NewBFI82U(time.Time{}, time.Time{}).SetWeek(time.Date(2017, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone))
+	)
+	if b.Begin.Day() != 27 || b.End.Day() != 5 {
+		t.Fatalf("Wrong week: %s - %s", b.Begin, b.End)
+	}
+	b.SetRange(time.Date(2017, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone), time.Date(2017, 3, 3, 0, 0, 0, 0, utils.TaipeiTimeZone))
+	data, err := b.GetRange()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 || data[0].Date.Day() != 1 || data[1].FII.Buy != 6000 || data[1].FII.Total != 6000 || len(data[0].Items) != 6 {
+		t.Fatalf("Wrong data: %+v", data)
+	}
+	if v := data[0]; v.Dealer.Buy != 3000 || v.Dealer.Total != -1000 || v.SIT.Total != 400 || v.Total.Total != 4400 {
+		t.Errorf("Wrong data: %+v", v)
+	}
+
+	if m := client.NewBFI82U(time.Time{}, time.Time{}).SetMonth(time.Date(2017, 2, 14, 0, 0, 0, 0, utils.TaipeiTimeZone)); m.Begin.Day() != 1 || m.End.Day() != 28 {
+		t.Errorf("Wrong month: %s - %s", m.Begin, m.End)
+	}
+}
+
+func TestQFIIS_transportJSON(t *testing.T) {
+	var (
+		date   = time.Date(2017, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		client = NewClient(WithTransport(TransportJSON), WithFetcher(memFetcher{
+			fmt.Sprintf("%s/fund/MI_QFIIS_sort_20?response=json&date=20170301", utils.TWSEHOST): `{
+"stat":"OK","date":"20170301","title":"106年03月01日 外資及陸資持股比率前二十名彙總表",
+"fields":["排名","證券代號","證券名稱","國際證券編碼","發行股數","外資及陸資尚可投資股數","全體外資及陸資持有股數","外資及陸資尚可投資比率","全體外資及陸資持股比率","外資及陸資共用法令投資上限比率","陸資法令投資上限比率","與前日異動原因(註)","最近一次上市公司申報外資持股異動日期"],
+"data":[["1","2379","瑞昱","TW0002379005","508,000,000","50,000,000","458,000,000","9.84","90.15","100.00","50.00","","1060301"]]}`,
+			fmt.Sprintf("%s/fund/BFI82U?response=json&dayDate=20170301&type=day", utils.TWSEHOST): `{
+"stat":"OK","date":"20170301","title":"106年03月01日 三大法人買賣金額統計表",
+"fields":["單位名稱","買進金額","賣出金額","買賣差額"],
+"data":[["自營商(自行買賣)","1,000","3,000","-2,000"],["投信","500","100","400"],["外資及陸資(不含外資自營商)","10,000","5,000","5,000"],["合計","11,500","8,100","3,400"]]}`,
+		}))
+	)
+	top, err := client.NewQFIISTOP20(date).Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(top) != 1 || top[0].No != "2379" || top[0].Ratio != 90.15 {
+		t.Errorf("Wrong data: %+v", top)
+	}
+
+	data, err := client.NewBFI82U(date, date).GetRange()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 1 || data[0].FII.Total != 5000 || data[0].Dealer.Sell != 3000 || data[0].Total.Total != 3400 {
+		t.Errorf("Wrong data: %+v", data)
 	}
 }
@@ -0,0 +1,173 @@
+package twse
+
+import (
+	"time"
+
+	"github.com/DoubleChuang/gogrs/tradingdays"
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+// Period K 線週期
+type Period struct {
+	unit periodUnit
+	days int
+}
+
+type periodUnit int
+
+const (
+	unitDays periodUnit = iota
+	unitWeek
+	unitMonth
+)
+
+// 常用 K 線週期
+var (
+	Daily   = Period{unit: unitDays, days: 1}
+	Weekly  = Period{unit: unitWeek}
+	Monthly = Period{unit: unitMonth}
+)
+
+// NDays 每 n 個開市日一根 K 線
+func NDays(n int) Period {
+	if n < 1 {
+		n = 1
+	}
+	return Period{unit: unitDays, days: n}
+}
+
+// Bars K 線序列，Date 為該根 K 線最後一個交易日
+type Bars []FmtData
+
+// Resample 將日資料依週期合併為 K 線，使用 Client 的開休市表
+func (d *Data) Resample(p Period) Bars {
+	return Resample(d.FormatData(), p, d.client.Calendar())
+}
+
+// Resample 將日資料依週期合併為 K 線
+//
+// 週 K 依開休市表以每週（週一至週日，含補班的週六）第一個開市日為起點分組，
+// 連假縮短的週與跨年的週皆以實際開市日為界，月 K 以月份分組，
+// N 日 K 則自第一筆資料起依開休市表每 n 個開市日分組，停牌的日子也會計入。
+// 開盤價取第一日、收盤價取最後一日，最高、最低取極值，
+// 成交股數、成交金額、成交筆數加總，漲跌價差為與前一根 K 線收盤價的差。
+// calendar 為 nil 時使用 tradingdays.DefaultCalendar。
+func Resample(data []FmtData, p Period, calendar *tradingdays.Calendar) Bars {
+	var (
+		result  Bars
+		lastKey = -1
+		keyOf   = periodKey(data, p, calendar)
+	)
+	for _, v := range data {
+		if v.Date.IsZero() {
+			continue
+		}
+		key := keyOf(v.Date)
+		if key != lastKey || len(result) == 0 {
+			result = append(result, v)
+			lastKey = key
+			continue
+		}
+		bar := &result[len(result)-1]
+		bar.Range += v.Price - bar.Price
+		bar.Date = v.Date
+		bar.Price = v.Price
+		if v.High > bar.High {
+			bar.High = v.High
+		}
+		if v.Low < bar.Low {
+			bar.Low = v.Low
+		}
+		bar.Volume += v.Volume
+		bar.TotalPrice += v.TotalPrice
+		bar.Totalsale += v.Totalsale
+	}
+	return result
+}
+
+// periodKey 回傳計算日期所屬 K 線的函式
+func periodKey(data []FmtData, p Period, calendar *tradingdays.Calendar) func(time.Time) int {
+	if calendar == nil {
+		calendar = tradingdays.DefaultCalendar()
+	}
+	switch p.unit {
+	case unitWeek:
+		return func(date time.Time) int {
+			return dateKey(weekStart(date, calendar))
+		}
+	case unitMonth:
+		return func(date time.Time) int {
+			return date.Year()*100 + int(date.Month())
+		}
+	}
+
+	var first time.Time
+	for _, v := range data {
+		if !v.Date.IsZero() {
+			first = v.Date
+			break
+		}
+	}
+	var (
+		day   = first
+		index int
+		n     = p.days
+	)
+	if n < 1 {
+		n = 1
+	}
+	// data 依日期排序，依序往後數開市日
+	return func(date time.Time) int {
+		for dateKey(day) < dateKey(date) {
+			day = day.AddDate(0, 0, 1)
+			if calendar.IsOpen(day.Year(), day.Month(), day.Day()) {
+				index++
+			}
+		}
+		return index / n
+	}
+}
+
+// weekStart 回傳 date 所在週（週一至週日）的第一個開市日，開休市表在 date 之前沒有開市日時回傳週一
+func weekStart(date time.Time, calendar *tradingdays.Calendar) time.Time {
+	var monday = date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+	for day := monday; dateKey(day) <= dateKey(date); day = day.AddDate(0, 0, 1) {
+		if calendar.IsOpen(day.Year(), day.Month(), day.Day()) {
+			return day
+		}
+	}
+	return monday
+}
+
+// GetPriceList 取得 收盤價 序列
+func (b Bars) GetPriceList() []float64 {
+	var result = make([]float64, len(b))
+	for i, v := range b {
+		result[i] = v.Price
+	}
+	return result
+}
+
+// GetVolumeList 取得 成交股數 序列
+func (b Bars) GetVolumeList() []uint64 {
+	var result = make([]uint64, len(b))
+	for i, v := range b {
+		result[i] = v.Volume
+	}
+	return result
+}
+
+// MA 計算 收盤價 的移動平均
+func (b Bars) MA(days int) []float64 {
+	return calMA(b.GetPriceList(), days)
+}
+
+// MAV 計算 成交股數 的移動平均
+func (b Bars) MAV(days int) []uint64 {
+	return calMAV(b.GetVolumeList(), days)
+}
+
+// MABR 計算 收盤價移動平均 的乖離
+func (b Bars) MABR(days1, days2 int) []float64 {
+	return utils.CalDiffFloat64(b.MA(days1), b.MA(days2))
+}
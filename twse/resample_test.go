@@ -0,0 +1,97 @@
+package twse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/tradingdays"
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+func sampleFmtData(days ...int) []FmtData {
+	var result = make([]FmtData, len(days))
+	for i, v := range days {
+		price := float64(10 + i)
+		result[i] = FmtData{
+			Date:       time.Date(2015, time.Month(v/100), v%100, 0, 0, 0, 0, utils.TaipeiTimeZone),
+			Volume:     1000,
+			TotalPrice: uint64(price * 1000),
+			Open:       price - 0.5,
+			High:       price + 1,
+			Low:        price - 1,
+			Price:      price,
+			Range:      1,
+			Totalsale:  10,
+		}
+	}
+	return result
+}
+
+func TestResample(t *testing.T) {
+	var (
+		calendar = tradingdays.NewCalendar()
+		data     = sampleFmtData(212, 213, 224, 225, 226, 302, 303)
+	)
+
+	weekly := Resample(data, Weekly, calendar)
+	if len(weekly) != 3 {
+		t.Fatalf("Should be 3 but %d", len(weekly))
+	}
+	if w := weekly[1]; w.Open != 11.5 || w.Price != 14 || w.High != 15 || w.Low != 11 ||
+		w.Volume != 3000 || w.TotalPrice != 39000 || w.Totalsale != 30 || w.Range != 3 ||
+		!w.Date.Equal(data[4].Date) {
+		t.Errorf("Weekly bar wrong: %+v", w)
+	}
+
+	monthly := Resample(data, Monthly, calendar)
+	if len(monthly) != 2 || monthly[0].Volume != 5000 || monthly[1].Range != 2 {
+		t.Errorf("Monthly bars wrong: %+v", monthly)
+	}
+
+	if bars := Resample(data, NDays(2), calendar); len(bars) != 4 {
+		t.Errorf("Should be 4 but %d", len(bars))
+	}
+	// 停牌的日子仍計入開市日
+	suspended := sampleFmtData(212, 213, 224, 226, 302, 303)
+	if bars := Resample(suspended, NDays(2), calendar); len(bars) != 4 || bars[2].Volume != 2000 {
+		t.Errorf("Suspended bars wrong: %+v", bars)
+	}
+
+	if daily := Resample(data, Daily, calendar); len(daily) != len(data) {
+		t.Errorf("Should be %d but %d", len(data), len(daily))
+	}
+}
+
+func TestBars_MA(t *testing.T) {
+	var bars = Resample(sampleFmtData(212, 213, 224, 225, 226, 302, 303), Weekly, tradingdays.NewCalendar())
+	if ma := bars.MA(2); len(ma) != 2 || ma[0] != 12.5 || ma[1] != 15 {
+		t.Errorf("MA wrong: %v", ma)
+	}
+	if mav := bars.MAV(3); len(mav) != 1 || mav[0] != 2333 {
+		t.Errorf("MAV wrong: %v", mav)
+	}
+}
+
+func TestResample_weekBoundary(t *testing.T) {
+	var data []FmtData
+	for _, v := range []time.Time{
+		time.Date(2014, 12, 26, 0, 0, 0, 0, utils.TaipeiTimeZone),
+		time.Date(2014, 12, 30, 0, 0, 0, 0, utils.TaipeiTimeZone),
+		time.Date(2014, 12, 31, 0, 0, 0, 0, utils.TaipeiTimeZone),
+		time.Date(2015, 1, 2, 0, 0, 0, 0, utils.TaipeiTimeZone),
+		time.Date(2015, 1, 5, 0, 0, 0, 0, utils.TaipeiTimeZone),
+	} {
+		data = append(data, FmtData{Date: v, Open: 10, High: 11, Low: 9, Price: 10, Volume: 1000})
+	}
+	weekly := Resample(data, Weekly, tradingdays.NewCalendar())
+	if len(weekly) != 3 || weekly[1].Volume != 3000 || !weekly[1].Date.Equal(data[3].Date) {
+		t.Errorf("Weekly bars wrong: %+v", weekly)
+	}
+
+	if ma := weekly.MA(5); len(ma) != 0 {
+		t.Errorf("Should be empty but %v", ma)
+	}
+	if mav := weekly.MAV(4); len(mav) != 0 {
+		t.Errorf("Should be empty but %v", mav)
+	}
+}
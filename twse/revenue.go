@@ -0,0 +1,219 @@
+package twse
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+// MonthlyRevenue 公司每月營業收入，金額單位為千元，增減為百分比
+type MonthlyRevenue struct {
+	Month              time.Time `json:"month"` // 資料年月的第一天
+	No                 string    `json:"no"`
+	Name               string    `json:"name"`
+	Industry           string    `json:"industry"`             // 產業別
+	Revenue            int64     `json:"revenue"`              // 當月營收
+	LastMonth          int64     `json:"last_month"`           // 上月營收
+	LastYear           int64     `json:"last_year"`            // 去年當月營收
+	Cumulative         int64     `json:"cumulative"`           // 當月累計營收
+	LastYearCumulative int64     `json:"last_year_cumulative"` // 去年累計營收
+	MoM                float64   `json:"mom"`                  // 與上月比較增減
+	YoY                float64   `json:"yoy"`                  // 與去年同月增減
+	CumulativeYoY      float64   `json:"cumulative_yoy"`       // 累計營收與去年同期增減
+	Note               string    `json:"note"`                 // 備註
+}
+
+// StockInfo 回傳股票代碼與名稱
+func (m MonthlyRevenue) StockInfo() StockInfo {
+	return StockInfo{No: m.No, Name: m.Name}
+}
+
+// growth 計算增減百分比，取到小數第二位；比較的基期為 0 或負數時為 0
+func growth(value, base int64) float64 {
+	if base <= 0 {
+		return 0
+	}
+	return math.Round(float64(value-base)/float64(base)*10000) / 100
+}
+
+// revenueSchema 公開資訊觀測站每月營業收入彙總表（t21sc03）的欄位
+var revenueSchema = []column{
+	col("No", "公司代號"),
+	col("Name", "公司名稱"),
+	optCol("Industry", "產業別"),
+	col("Revenue", "營業收入-當月營收", "當月營收"),
+	col("LastMonth", "營業收入-上月營收", "上月營收"),
+	col("LastYear", "營業收入-去年當月營收", "去年當月營收"),
+	col("Cumulative", "累計營業收入-當月累計營收", "當月累計營收"),
+	col("LastYearCumulative", "累計營業收入-去年累計營收", "去年累計營收"),
+	optCol("Note", "備註"),
+}
+
+// Revenue 上市/上櫃公司每月營業收入彙總表
+type Revenue struct {
+	Month    time.Time
+	FmtData  map[string]MonthlyRevenue
+	exchange string
+	report   *Report
+	fetcher  Fetcher
+	client   *Client
+}
+
+// NewRevenue 建立上市公司每月營業收入彙總表，month 為資料年月
+func NewRevenue(month time.Time) *Revenue {
+	return &Revenue{Month: month, FmtData: make(map[string]MonthlyRevenue), exchange: "tse"}
+}
+
+// NewOTCRevenue 建立上櫃公司每月營業收入彙總表，month 為資料年月
+func NewOTCRevenue(month time.Time) *Revenue {
+	return &Revenue{Month: month, FmtData: make(map[string]MonthlyRevenue), exchange: "otc"}
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (r *Revenue) SetFetcher(f Fetcher) *Revenue {
+	r.fetcher = f
+	return r
+}
+
+// URL 擷取網址
+func (r Revenue) URL() string {
+	var market = map[string]string{"tse": "sii", "otc": "otc"}[r.exchange]
+	return fmt.Sprintf("%s%s", r.client.MOPSHost(), fmt.Sprintf(utils.MOPSREVENUE, market, r.Month.Year()-1911, r.Month.Month()))
+}
+
+// Get 擷取當月全部公司的營收，依來源順序排列，欄位解析失敗時記錄在 Report()
+func (r *Revenue) Get() ([]MonthlyRevenue, error) {
+	if !utils.ExchangeMap[r.exchange] {
+		return nil, errorNotSupport
+	}
+	var s = schema{name: fmt.Sprintf("%s revenue %s", r.exchange, r.Month.Format("200601")), columns: revenueSchema}
+	data, err := r.client.fetchUTF8(r.fetcher).Get(r.URL(), false)
+	if err != nil {
+		return nil, fmt.Errorf(errorNetworkFail.Error(), err)
+	}
+	t, err := s.parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		p      = newParser(s.name, r.client.Strict())
+		month  = time.Date(r.Month.Year(), r.Month.Month(), 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		result = make([]MonthlyRevenue, 0, len(t.records))
+	)
+	r.report = p.report
+	for _, v := range t.records {
+		p.next()
+		var data MonthlyRevenue
+		data.Month = month
+		data.No = v.get("No")
+		data.Name = v.get("Name")
+		data.Industry = v.get("Industry")
+		data.Revenue = p.int("Revenue", v.get("Revenue"))
+		data.LastMonth = p.int("LastMonth", v.get("LastMonth"))
+		data.LastYear = p.int("LastYear", v.get("LastYear"))
+		data.Cumulative = p.int("Cumulative", v.get("Cumulative"))
+		data.LastYearCumulative = p.int("LastYearCumulative", v.get("LastYearCumulative"))
+		data.MoM = growth(data.Revenue, data.LastMonth)
+		data.YoY = growth(data.Revenue, data.LastYear)
+		data.CumulativeYoY = growth(data.Cumulative, data.LastYearCumulative)
+		if note := v.get("Note"); note != "-" {
+			data.Note = note
+		}
+		if err := p.err(); err != nil {
+			return nil, err
+		}
+		r.FmtData[data.No] = data
+		result = append(result, data)
+	}
+	return result, nil
+}
+
+// Report 回傳最近一次 Get 的驗證報告
+func (r Revenue) Report() *Report {
+	return r.report
+}
+
+// RevenueHistory 多個月份的營收彙總表，以股票代碼取得個股依月份排序的營收
+type RevenueHistory struct {
+	Data     map[string][]MonthlyRevenue
+	exchange string
+	months   map[int64]bool
+	report   *Report
+	fetcher  Fetcher
+	client   *Client
+}
+
+// NewRevenueHistory 建立上市公司的營收歷史資料
+func NewRevenueHistory() *RevenueHistory {
+	return &RevenueHistory{Data: make(map[string][]MonthlyRevenue), exchange: "tse", months: make(map[int64]bool)}
+}
+
+// NewOTCRevenueHistory 建立上櫃公司的營收歷史資料
+func NewOTCRevenueHistory() *RevenueHistory {
+	return &RevenueHistory{Data: make(map[string][]MonthlyRevenue), exchange: "otc", months: make(map[int64]bool)}
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (h *RevenueHistory) SetFetcher(f Fetcher) *RevenueHistory {
+	h.fetcher = f
+	return h
+}
+
+// LoadRange 擷取 from 到 to 之間每個月份的營收彙總表，已擷取的月份不會重複擷取，
+// 尚未公布的月份略過
+func (h *RevenueHistory) LoadRange(from, to time.Time) error {
+	var report = &Report{Dataset: fmt.Sprintf("%s revenue", h.exchange)}
+	err := loadEach(monthsBetween(from, to), h.months, func(month time.Time) error {
+		var r = &Revenue{Month: month, FmtData: make(map[string]MonthlyRevenue), exchange: h.exchange, fetcher: h.fetcher, client: h.client}
+		data, err := r.Get()
+		if err != nil {
+			return err
+		}
+		report.merge(r.Report())
+		for _, v := range data {
+			h.Data[v.No] = append(h.Data[v.No], v)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for no := range h.Data {
+		list := h.Data[no]
+		sort.Slice(list, func(i, j int) bool { return list[i].Month.Before(list[j].Month) })
+	}
+	h.report = report
+	return nil
+}
+
+// Stock 回傳個股依月份排序的營收
+func (h RevenueHistory) Stock(no string) []MonthlyRevenue {
+	return h.Data[no]
+}
+
+// Report 回傳最近一次 LoadRange 的驗證報告
+func (h RevenueHistory) Report() *Report {
+	return h.report
+}
+
+// IsRevenueHigh 最後一個月的營收是否為最近 months 個月的新高，data 依月份排序；
+// 資料不足 months 個月或其間缺少月份時為 false
+func IsRevenueHigh(data []MonthlyRevenue, months int) bool {
+	if months < 2 || len(data) < months {
+		return false
+	}
+	var last = data[len(data)-1]
+	if !data[len(data)-months].Month.AddDate(0, months-1, 0).Equal(last.Month) {
+		return false
+	}
+	for _, v := range data[len(data)-months : len(data)-1] {
+		if v.Revenue >= last.Revenue {
+			return false
+		}
+	}
+	return true
+}
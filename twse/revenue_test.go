@@ -0,0 +1,86 @@
+package twse
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+func TestRevenue_Get(t *testing.T) {
+	csv, err := ioutil.ReadFile("testdata/t21sc03_106_3.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		client = NewClient(WithFetcher(memFetcher{
+			fmt.Sprintf("%s%s", utils.MOPSHOST, fmt.Sprintf(utils.MOPSREVENUE, "sii", 106, 3)): string(csv),
+		}))
+		r = client.NewRevenue(time.Date(2017, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone))
+	)
+	data, err := r.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 || data[0].No != "1101" || data[0].Revenue != 9000000 || data[0].MoM != 20 || data[0].YoY != 12.5 || data[0].Note != "" {
+		t.Fatalf("Wrong data: %+v", data)
+	}
+	if v := r.FmtData["2618"]; v.YoY != -4.76 || v.CumulativeYoY != -4.67 || v.Industry != "航運業" || v.Note != "春節連假" {
+		t.Errorf("Wrong data: %+v", v)
+	}
+	if info := data[1].StockInfo(); info.No != "2618" || info.Name != "長榮航" {
+		t.Errorf("Wrong info: %+v", info)
+	}
+}
+
+func TestRevenueHistory_LoadRange(t *testing.T) {
+	csv, err := ioutil.ReadFile("testdata/t21sc03_106_3.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		url = func(month int) string {
+			return fmt.Sprintf("%s%s", utils.MOPSHOST, fmt.Sprintf(utils.MOPSREVENUE, "sii", 106, month))
+		}
+		h = NewClient(WithFetcher(dayFetcher(url, map[int]string{2: fixtureNoData, 3: string(csv)}))).NewRevenueHistory()
+	)
+	for i := 0; i < 2; i++ {
+		// 已擷取的月份不會重複加入
+		if err := h.LoadRange(time.Date(2017, 2, 1, 0, 0, 0, 0, utils.TaipeiTimeZone), time.Date(2017, 3, 31, 0, 0, 0, 0, utils.TaipeiTimeZone)); err != nil {
+			t.Fatal(err)
+		}
+		if data := h.Stock("2618"); len(h.Data) != 2 || len(data) != 1 || data[0].Month.Month() != 3 {
+			t.Fatalf("Wrong data: %+v", h.Data)
+		}
+	}
+}
+
+func TestIsRevenueHigh(t *testing.T) {
+	var (
+		month = func(m time.Month) time.Time { return time.Date(2017, m, 1, 0, 0, 0, 0, utils.TaipeiTimeZone) }
+		data  = []MonthlyRevenue{
+			{Month: month(1), Revenue: 10}, {Month: month(2), Revenue: 30}, {Month: month(3), Revenue: 20},
+			{Month: month(4), Revenue: 15}, {Month: month(5), Revenue: 25},
+		}
+	)
+	if IsRevenueHigh(data, 3) != true {
+		t.Error("Should be true")
+	}
+	if IsRevenueHigh(data, 4) != false {
+		t.Error("Should be false")
+	}
+	if IsRevenueHigh(data, 6) != false {
+		t.Error("Should be false")
+	}
+
+	// 缺少 2 月時，最後 4 筆跨越 5 個月
+	var gap = append([]MonthlyRevenue{data[0]}, data[2:]...)
+	if IsRevenueHigh(gap, 4) != false {
+		t.Error("Should be false with missing month")
+	}
+	if IsRevenueHigh(gap, 3) != true {
+		t.Error("Should be true")
+	}
+}
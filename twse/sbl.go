@@ -0,0 +1,198 @@
+package twse
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+// SBLData 個股融券與借券賣出餘額，單位為股
+type SBLData struct {
+	Date         time.Time `json:"date"`
+	No           string    `json:"no"`
+	Name         string    `json:"name"`
+	ShortPrev    int64     `json:"short_prev"`    // 融券前日餘額
+	ShortBalance int64     `json:"short_balance"` // 融券今日餘額
+	PrevBalance  int64     `json:"prev_balance"`  // 借券賣出前日餘額
+	Sell         int64     `json:"sell"`          // 借券當日賣出
+	Return       int64     `json:"return"`        // 借券當日還券
+	Adjust       int64     `json:"adjust"`        // 借券當日調整
+	Balance      int64     `json:"balance"`       // 借券賣出當日餘額
+	Limit        int64     `json:"limit"`         // 次一營業日借券賣出可限額
+}
+
+// twt93USchema 融券借券賣出餘額（TWT93U）的欄位，融券與借券賣出的前日餘額標題相同
+var twt93USchema = []column{
+	col("No", "代號", "股票代號"),
+	col("Name", "名稱", "股票名稱"),
+	nthCol("ShortPrev", 1, "前日餘額"),
+	col("ShortBalance", "今日餘額"),
+	nthCol("PrevBalance", 2, "前日餘額"),
+	col("Sell", "當日賣出"),
+	col("Return", "當日還券"),
+	col("Adjust", "當日調整"),
+	col("Balance", "當日餘額"),
+	optCol("Limit", "次一營業日可限額", "今日可借券賣出限額"),
+}
+
+// TWT93U 上市融券借券賣出餘額
+type TWT93U struct {
+	Date    time.Time
+	FmtData map[string]SBLData
+	report  *Report
+	fetcher Fetcher
+	client  *Client
+}
+
+// NewTWT93U 建立融券借券賣出餘額
+func NewTWT93U(date time.Time) *TWT93U {
+	return &TWT93U{Date: date, FmtData: make(map[string]SBLData)}
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (t *TWT93U) SetFetcher(f Fetcher) *TWT93U {
+	t.fetcher = f
+	return t
+}
+
+// URL 擷取網址
+func (t TWT93U) URL() string {
+	return t.client.twseURL(fmt.Sprintf(utils.TWT93U, t.Date.Year(), t.Date.Month(), t.Date.Day()))
+}
+
+// Get 擷取當日全部股票的融券與借券賣出餘額，欄位解析失敗時記錄在 Report()
+func (t *TWT93U) Get() (map[string]SBLData, error) {
+	var s = schema{name: "TWT93U " + t.Date.Format("20060102"), columns: twt93USchema}
+	data, err := t.client.fetchTWSE(t.fetcher).PostForm(t.URL(), nil)
+	if err != nil {
+		return nil, fmt.Errorf(errorNetworkFail.Error(), err)
+	}
+	table, err := t.client.parse(s, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var p = newParser(s.name, t.client.Strict())
+	t.report = p.report
+	for _, r := range table.records {
+		p.next()
+		var v = SBLData{
+			Date:         t.Date,
+			No:           strings.Replace(r.get("No"), " ", "", -1),
+			Name:         strings.Replace(r.get("Name"), " ", "", -1),
+			ShortPrev:    p.int("ShortPrev", r.get("ShortPrev")),
+			ShortBalance: p.int("ShortBalance", r.get("ShortBalance")),
+			PrevBalance:  p.int("PrevBalance", r.get("PrevBalance")),
+			Sell:         p.int("Sell", r.get("Sell")),
+			Return:       p.int("Return", r.get("Return")),
+			Adjust:       p.int("Adjust", r.get("Adjust")),
+			Balance:      p.int("Balance", r.get("Balance")),
+			Limit:        int64(p.optUint("Limit", r.get("Limit"))),
+		}
+		if err := p.err(); err != nil {
+			return nil, err
+		}
+		if v.No == "合計" {
+			p.skip()
+			continue
+		}
+		t.FmtData[v.No] = v
+	}
+	return t.FmtData, nil
+}
+
+// Report 回傳最近一次 Get 的驗證報告
+func (t TWT93U) Report() *Report {
+	return t.report
+}
+
+// SBLHistory 以股票代碼取得依日期排序的融券與借券賣出餘額
+type SBLHistory struct {
+	Data    map[string][]SBLData
+	dates   map[int64]bool
+	report  *Report
+	fetcher Fetcher
+	client  *Client
+}
+
+// NewSBLHistory 建立融券與借券賣出餘額的歷史資料
+func NewSBLHistory() *SBLHistory {
+	return &SBLHistory{Data: make(map[string][]SBLData), dates: make(map[int64]bool)}
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (h *SBLHistory) SetFetcher(f Fetcher) *SBLHistory {
+	h.fetcher = f
+	return h
+}
+
+// LoadRange 擷取 from 到 to 之間每個開市日的資料，已擷取的日期不會重複擷取，
+// 沒有資料的日期略過
+func (h *SBLHistory) LoadRange(from, to time.Time) error {
+	var report = &Report{Dataset: "TWT93U"}
+	err := loadEach(openDays(h.client.Calendar(), from, to), h.dates, func(day time.Time) error {
+		t := &TWT93U{Date: day, FmtData: make(map[string]SBLData), fetcher: h.fetcher, client: h.client}
+		data, err := t.Get()
+		if err != nil {
+			return err
+		}
+		report.merge(t.Report())
+		for no, v := range data {
+			h.Data[no] = append(h.Data[no], v)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for no := range h.Data {
+		list := h.Data[no]
+		sort.Slice(list, func(i, j int) bool { return list[i].Date.Before(list[j].Date) })
+	}
+	h.report = report
+	return nil
+}
+
+// Stock 回傳個股依日期排序的融券與借券賣出餘額
+func (h SBLHistory) Stock(no string) []SBLData {
+	return h.Data[no]
+}
+
+// Report 回傳最近一次 LoadRange 的驗證報告
+func (h SBLHistory) Report() *Report {
+	return h.report
+}
+
+// ShortInterest 個股的融資融券（MI_MARGN，單位為交易單位）與融券、借券賣出餘額（TWT93U，單位為股）
+type ShortInterest struct {
+	Date time.Time `json:"date"`
+	No   string    `json:"no"`
+	Name string    `json:"name"`
+	MTSS BaseMTSS  `json:"mtss"`
+	SBL  SBLData   `json:"sbl"`
+}
+
+// TotalBalance 融券與借券賣出餘額的合計（股）
+func (s ShortInterest) TotalBalance() int64 {
+	return s.SBL.ShortBalance + s.SBL.Balance
+}
+
+// MergeShortInterest 以股票代碼合併同一天的融資融券（TWMTSS.Get）與融券借券賣出餘額（TWT93U.Get）
+func MergeShortInterest(date time.Time, mtss map[string]BaseMTSS, sbl map[string]SBLData) map[string]ShortInterest {
+	var result = make(map[string]ShortInterest, len(sbl))
+	for no, v := range sbl {
+		result[no] = ShortInterest{Date: date, No: no, Name: v.Name, SBL: v}
+	}
+	for no, v := range mtss {
+		s := result[no]
+		s.Date, s.No, s.MTSS = date, no, v
+		if s.Name == "" {
+			s.Name = v.Name
+		}
+		result[no] = s
+	}
+	return result
+}
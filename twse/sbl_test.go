@@ -0,0 +1,52 @@
+package twse
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+func twt93UFixture(date, sell, balance string) string {
+	return fmt.Sprintf(`"%s 融券借券賣出餘額"
+"","","融券","","","","","","借券賣出","","","","","",""
+"代號","名稱","前日餘額","賣出","買進","現券","今日餘額","限額","前日餘額","當日賣出","當日還券","當日調整","當日餘額","次一營業日可限額","備註",
+"2618","長榮航","100,000","20,000","10,000","0","110,000","1,000,000","500,000","%s","30,000","0","%s","2,000,000","",
+"合計","","100,000","20,000","10,000","0","110,000","","500,000","%s","30,000","0","%s","","",
+"說明:"
+`, date, sell, balance, sell, balance)
+}
+
+func TestSBLHistory_LoadRange(t *testing.T) {
+	var (
+		url = func(day int) string {
+			return fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.TWT93U, 2017, 3, day))
+		}
+		client = NewClient(WithFetcher(dayFetcher(url, map[int]string{
+			1: twt93UFixture("106年03月01日", "80,000", "550,000"),
+			2: twt93UFixture("106年03月02日", "10,000", "530,000"),
+			3: fixtureNoData,
+		})))
+		h = client.NewSBLHistory()
+	)
+	if err := h.LoadRange(time.Date(2017, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone), time.Date(2017, 3, 3, 0, 0, 0, 0, utils.TaipeiTimeZone)); err != nil {
+		t.Fatal(err)
+	}
+	data := h.Stock("2618")
+	if len(data) != 2 || len(h.Data) != 1 || data[0].Sell != 80000 || data[1].Balance != 530000 || data[1].Date.Day() != 2 {
+		t.Fatalf("Wrong data: %+v", h.Data)
+	}
+	if v := data[0]; v.Name != "長榮航" || v.ShortPrev != 100000 || v.ShortBalance != 110000 || v.PrevBalance != 500000 || v.Return != 30000 || v.Limit != 2000000 {
+		t.Errorf("Wrong data: %+v", v)
+	}
+
+	var (
+		date   = data[0].Date
+		merged = MergeShortInterest(date, map[string]BaseMTSS{"2618": {Name: "長榮航", SS: TradingVolume{Buy: 10, Sell: 20, Total: 10}}},
+			map[string]SBLData{"2618": data[0]})
+	)
+	if v := merged["2618"]; v.MTSS.SS.Sell != 20 || v.SBL.Balance != 550000 || v.TotalBalance() != 660000 || !v.Date.Equal(date) {
+		t.Errorf("Wrong merge: %+v", v)
+	}
+}
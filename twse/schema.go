@@ -0,0 +1,250 @@
+package twse
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// SchemaError 找不到必要的欄位，通常是來源的格式改變
+type SchemaError struct {
+	Dataset string
+	Missing []string // 找不到的欄位標題
+	Header  []string // 最接近的表頭
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%s: schema drift: missing %s in header [%s]", e.Dataset, strings.Join(e.Missing, ", "), strings.Join(e.Header, ", "))
+}
+
+// column 欄位定義
+type column struct {
+	key      string   // 程式中使用的名稱
+	titles   []string // 可能的表頭標題（不含空白），依序比對
+	nth      int      // 同名欄位中的第幾個，由 1 開始，0 視為 1
+	optional bool     // 找不到時不視為格式改變
+}
+
+// schema 以表頭標題對應欄位的 CSV 格式
+type schema struct {
+	name    string
+	columns []column
+}
+
+// col 建立欄位定義
+func col(key string, titles ...string) column {
+	return column{key: key, titles: titles}
+}
+
+// nthCol 建立同名欄位中第 n 個的欄位定義
+func nthCol(key string, n int, titles ...string) column {
+	return column{key: key, titles: titles, nth: n}
+}
+
+// optCol 建立非必要的欄位定義
+func optCol(key string, titles ...string) column {
+	return column{key: key, titles: titles, optional: true}
+}
+
+func (c column) String() string {
+	if c.nth > 1 {
+		return fmt.Sprintf("%s#%d", c.titles[0], c.nth)
+	}
+	return c.titles[0]
+}
+
+// record 一列資料，以欄位名稱取值
+type record struct {
+	index map[string]int
+	cells []string
+}
+
+// get 取得欄位的值，去除前後空白；欄位不存在時為空字串
+func (r record) get(key string) string {
+	if i, ok := r.index[key]; ok && i < len(r.cells) {
+		return strings.TrimSpace(r.cells[i])
+	}
+	return ""
+}
+
+// has 是否有該欄位
+func (r record) has(key string) bool {
+	_, ok := r.index[key]
+	return ok
+}
+
+// table 依表頭找到的所有資料列
+type table struct {
+	title   string // 報表標題，CSV 為第一列，JSON 為 title 欄位
+	records []record
+	rows    [][]string // 原始欄位
+}
+
+// readCSV 讀取 CSV，將 ="0050" 形式的欄位轉為一般欄位，欄位數可不同，去除 UTF-8 BOM
+func readCSV(data []byte) ([][]string, error) {
+	var lines = strings.Split(strings.TrimPrefix(string(data), "\ufeff"), "\n")
+	for i, v := range lines {
+		lines[i] = strings.Replace(strings.TrimSpace(v), `="`, `"`, -1)
+	}
+	r := csv.NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	return r.ReadAll()
+}
+
+// title 表頭標題去除所有空白
+func title(cell string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, cell)
+}
+
+// parse 找出符合 schema 的表頭，收集表頭之後的資料列
+//
+// 表頭為包含所有必要欄位標題的一列，空白的表頭欄位沿用上一列的標題（兩列的表頭）。
+// 資料列到欄位數不足的一列（如「說明:」）為止，之後再遇到相同的表頭會繼續收集。
+// 只找到部分欄位時回傳 *SchemaError；完全沒有相符的欄位（如查無資料）時回傳 errorNotEnoughData。
+func (s schema) parse(data []byte) (*table, error) {
+	rows, err := readCSV(data)
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.parseRows(rows)
+	if err == nil && len(rows) > 0 && len(rows[0]) > 0 {
+		t.title = strings.TrimSpace(rows[0][0])
+	}
+	return t, err
+}
+
+// parseRows 同 parse，rows 為已讀取的所有列
+func (s schema) parseRows(rows [][]string) (*table, error) {
+	var (
+		result  = &table{}
+		index   map[string]int
+		width   int
+		matched bool
+		best    []string
+		missing []string
+	)
+	for i, row := range rows {
+		if index != nil {
+			if len(row) >= width && !isBlank(row) {
+				if _, miss, _ := s.match(rows, i); len(miss) > 0 {
+					result.records = append(result.records, record{index: index, cells: row})
+					result.rows = append(result.rows, row)
+					continue
+				}
+			}
+			index = nil
+		}
+		idx, miss, header := s.match(rows, i)
+		switch {
+		case len(miss) == 0:
+			index, width, matched = idx, 0, true
+			for _, v := range idx {
+				if v+1 > width {
+					width = v + 1
+				}
+			}
+		case len(idx) > 0 && (best == nil || len(miss) < len(missing)):
+			best, missing = header, miss
+		}
+	}
+	switch {
+	case matched:
+		return result, nil
+	case best == nil:
+		return nil, errors.WithMessage(errorNotEnoughData, s.name)
+	}
+	return nil, &SchemaError{Dataset: s.name, Missing: missing, Header: best}
+}
+
+// match 比對第 i 列是否為表頭，回傳欄位位置、找不到的必要欄位與表頭標題
+func (s schema) match(rows [][]string, i int) (map[string]int, []string, []string) {
+	var (
+		header  = make([]string, len(rows[i]))
+		index   = make(map[string]int)
+		missing []string
+	)
+	for j, cell := range rows[i] {
+		header[j] = title(cell)
+		if header[j] == "" && i > 0 && j < len(rows[i-1]) {
+			header[j] = title(rows[i-1][j])
+		}
+	}
+	for _, c := range s.columns {
+		var found = -1
+		for _, t := range c.titles {
+			var count int
+			for j, h := range header {
+				if h == t {
+					count++
+					if count == c.nth || c.nth == 0 {
+						found = j
+						break
+					}
+				}
+			}
+			if found >= 0 {
+				break
+			}
+		}
+		switch {
+		case found >= 0:
+			index[c.key] = found
+		case !c.optional:
+			missing = append(missing, c.String())
+		}
+	}
+	return index, missing, header
+}
+
+func isBlank(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Column 以表頭標題對應的欄位定義，供其他套件（如 fundamental）使用相同的表頭比對
+type Column = column
+
+// Col 建立欄位定義，titles 為可能的表頭標題（不含空白），依序比對
+func Col(key string, titles ...string) Column {
+	return col(key, titles...)
+}
+
+// OptCol 建立非必要的欄位定義
+func OptCol(key string, titles ...string) Column {
+	return optCol(key, titles...)
+}
+
+// Record 一列資料，以欄位名稱取值
+type Record = record
+
+// Get 取得欄位的值，去除前後空白；欄位不存在時為空字串
+func (r record) Get(key string) string {
+	return r.get(key)
+}
+
+// ParseRows 以 CSV 相同的規則找出符合 columns 的表頭，收集表頭之後的資料列
+//
+// 欄位數不足或空白的一列結束一個表格。完全沒有相符的表頭（如查無資料）時回傳空的結果；
+// 只找到部分欄位時回傳 *SchemaError。
+func ParseRows(name string, columns []Column, rows [][]string) ([]Record, error) {
+	t, err := schema{name: name, columns: columns}.parseRows(rows)
+	if errors.Cause(err) == errorNotEnoughData {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return t.records, nil
+}
@@ -0,0 +1,203 @@
+package twse
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+func TestSchema_parse(t *testing.T) {
+	var data = []byte(`"104年03月 8446 華研 個股日成交資訊"
+"日 期","成交仟股","成交仟元","開盤","最高","最低","收盤","漲跌","筆數"
+="104/03/02","12","1,234","100.00","101.00","99.50","100.50","+0.50","30"
+"104/03/03","15","1,512","100.50","101.50","100.00","101.00","0.50","42"
+"共2筆"
+"說明:"
+`)
+	table, err := schema{name: "otc 8446", columns: dailySchema}.parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.records) != 2 {
+		t.Fatalf("Should be 2 rows but %v", table.rows)
+	}
+	if r := table.records[0]; r.get("Date") != "104/03/02" || r.get("TotalPrice") != "1,234" || r.get("Range") != "+0.50" {
+		t.Errorf("Wrong record: %v", r.cells)
+	}
+}
+
+func TestSchema_parseDrift(t *testing.T) {
+	var data = []byte(`"104年03月 2618 長榮航 各日成交資訊"
+"日期","成交股數","成交金額","開盤價","最高價","最低價","收盤價","成交筆數",
+"104/03/02","13,384,378","305,046,992","23.00","23.05","22.50","22.90","3,793",
+`)
+	_, err := schema{name: "tse 2618", columns: dailySchema}.parse(data)
+	serr, ok := err.(*SchemaError)
+	if !ok {
+		t.Fatalf("Should be *SchemaError but %v", err)
+	}
+	if len(serr.Missing) != 1 || serr.Missing[0] != "漲跌價差" || serr.Header[0] != "日期" {
+		t.Errorf("Wrong error: %s", serr)
+	}
+
+	if _, err := (schema{name: "tse 2618", columns: dailySchema}).parse([]byte(`"很抱歉，沒有符合條件的資料!"`)); err == nil {
+		t.Error("Should be error")
+	} else if _, ok := err.(*SchemaError); ok {
+		t.Errorf("Should not be *SchemaError: %s", err)
+	}
+}
+
+func TestData_schema(t *testing.T) {
+	var d = NewTWSE("2618", time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone))
+	// 欄位順序改變、多一欄、表頭前多一行說明
+	d.SetFetcher(memFetcher{d.URL(): `"104年03月 2618 長榮航           各日成交資訊"
+"資料來源：臺灣證券交易所"
+"日期","成交筆數","成交股數","成交金額","開盤價","最高價","最低價","收盤價","漲跌價差","註記",
+"104/03/02","3,793","13,384,378","305,046,992","23.00","23.05","22.50","22.90","-0.10","",
+"104/03/03","2,893","10,061,202","229,871,452","22.90","23.05","22.75","22.90","0.00","",
+"說明:"
+`})
+	if _, err := d.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Name != "長榮航" || d.Len() != 2 {
+		t.Fatalf("Wrong data: %s %v", d.Name, d.RawData)
+	}
+	if data := d.FormatData(); data[0].Totalsale != 3793 || data[0].Volume != 13384378 || data[0].Range != -0.10 {
+		t.Errorf("Wrong data: %+v", data[0])
+	}
+}
+
+func TestLists_schema(t *testing.T) {
+	var (
+		date = time.Date(2017, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		url  = fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.TWSELISTCSV, 2017, 3, 1, "ALLBUT0999"))
+		f    = memFetcher{url: `"106年03月01日 大盤統計資訊"
+"指數","收盤指數","漲跌(+/-)","漲跌點數","漲跌百分比(%)",
+"發行量加權股價指數","9,674.78","-","75.69","-0.78",
+"成交統計","成交金額(元)","成交股數(股)","成交筆數",
+"1.一般股票","80,515,473,012","2,948,004,271","815,391",
+"106年03月01日每日收盤行情(全部(不含權證、牛熊證))"
+"證券代號","證券名稱","成交股數","成交筆數","成交金額","開盤價","最高價","最低價","收盤價","漲跌(+/-)","漲跌價差","最後揭示買價","最後揭示買量","最後揭示賣價","最後揭示賣量","本益比",
+="0050","元大台灣50","5,212,583","2,037","376,158,337","72.35","72.45","72.00","72.05","-","0.40","72.05","92","72.10","77","0.00",
+"1101","台泥","8,318,131","3,983","307,612,427","37.20","37.30","36.80","36.85","-","0.45","36.85","158","36.90","45","24.57",
+"1102","亞泥","0","0","0","--","--","--","--"," ","0.00","--","0","--","0","0.00",
+"備註:"
+"漲跌(+/-)欄位符號說明:+/-/X表示漲/跌/不比價。"
+`}
+		l = NewClient(WithFetcher(f)).NewLists(date)
+	)
+	if _, err := l.Get("ALLBUT0999"); err != nil {
+		t.Fatal(err)
+	}
+	list := l.GetCategoryList("ALLBUT0999")
+	if len(list) != 3 || list[0].No != "0050" || list[1].Name != "台泥" || list[2].No != "1102" {
+		t.Fatalf("Wrong list: %v", list)
+	}
+	if data := l.FmtData["1101"]; data.Range != -0.45 || data.PERatio != 24.57 || data.Totalsale != 3983 {
+		t.Errorf("Wrong data: %+v", data)
+	}
+	// 無成交的價格不以 0 代替
+	if data, ok := l.FmtData["1102"]; ok {
+		t.Errorf("Should skip no trade: %+v", data)
+	}
+	if r := l.Report(); r.Skipped != 1 || len(r.Errors) != 4 || r.Errors[0].Column != "Open" || r.Errors[0].Err != ErrNoValue {
+		t.Errorf("Wrong report: %s %v", r, r.Errors)
+	}
+
+	strict := NewClient(WithStrict(true), WithFetcher(f)).NewLists(date)
+	if _, err := strict.Get("ALLBUT0999"); err == nil {
+		t.Error("Should be *ParseError")
+	}
+}
+
+func TestT86_schema(t *testing.T) {
+	var (
+		date   = time.Date(2018, 1, 2, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		client = NewClient(WithFetcher(memFetcher{
+			fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.T86, 2018, 1, 2)): `"107年01月02日 三大法人買賣超日報"
+"證券代號","證券名稱","外陸資買進股數(不含外資自營商)","外陸資賣出股數(不含外資自營商)","外陸資買賣超股數(不含外資自營商)","外資自營商買進股數","外資自營商賣出股數","外資自營商買賣超股數","投信買進股數","投信賣出股數","投信買賣超股數","自營商買賣超股數","自營商買進股數(自行買賣)","自營商賣出股數(自行買賣)","自營商買賣超股數(自行買賣)","自營商買進股數(避險)","自營商賣出股數(避險)","自營商買賣超股數(避險)","三大法人買賣超股數",
+="2618","長榮航","1,000","400","600","0","0","0","50","0","50","-30","10","20","-10","0","20","-20","620",
+"說明:"
+`}))
+		t86 = client.NewT86(date)
+	)
+	data, err := t86.Get("ALL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 1 || data[0].No != "2618" || data[0].FII.Total != 600 || data[0].SIT.Buy != 50 ||
+		data[0].DProp.Total != -10 || data[0].DHedge.Sell != 20 || data[0].Diff != 620 {
+		t.Errorf("Wrong data: %+v", data)
+	}
+}
+
+func TestTWMTSS_schema(t *testing.T) {
+	var (
+		date   = time.Date(2017, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		client = NewClient(WithFetcher(memFetcher{
+			fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.TWMTSS, 2017, 3, 1, "ALL")): `"106年03月01日 信用交易統計"
+"項目","買進","賣出","現金(券)償還","前日餘額","今日餘額",
+"融資(交易單位)","151,234","140,123","2,345","5,678,901","5,687,667",
+"106年03月01日 融資融券彙總 (全部)"
+"","","融資","","","","","","融券","","","","","","",""
+"股票代號","股票名稱","買進","賣出","現金償還","前日餘額","今日餘額","限額","買進","賣出","現券償還","前日餘額","今日餘額","限額","資券互抵","註記",
+"2618","長榮航","500","300","0","10,000","10,200","100,000","20","50","0","1,000","1,030","100,000","0","",
+"說明:"
+`}))
+	)
+	data, err := client.NewTWMTSS(date, "ALL").Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := data["2618"]; len(data) != 1 || v.MT.Total != 200 || v.SS.Buy != 20 || v.SS.Total != 30 {
+		t.Errorf("Wrong data: %+v", data)
+	}
+}
+
+func TestTWMTSS_badRow(t *testing.T) {
+	var (
+		date   = time.Date(2017, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		client = NewClient(WithFetcher(memFetcher{
+			fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.TWMTSS, 2017, 3, 1, "ALL")): `"106年03月01日 融資融券彙總 (全部)"
+"股票代號","股票名稱","買進","賣出","現金償還","前日餘額","今日餘額","限額","買進","賣出","現券償還","前日餘額","今日餘額","限額","資券互抵","註記",
+"2618","長榮航","500","300","0","10,000","10,200","100,000","20","50","0","1,000","1,030","100,000","0","",
+"2303","聯電","X0.00","300","0","10,000","10,200","100,000","20","50","0","1,000","1,030","100,000","0","",
+"說明:"
+`}))
+		mtss = client.NewTWMTSS(date, "ALL")
+	)
+	data, err := mtss.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := data["2303"]; ok || len(data) != 1 {
+		t.Errorf("Should skip bad row: %+v", data)
+	}
+	if r := mtss.Report(); r.Skipped != 1 || len(r.Errors) != 1 || r.Errors[0].Column != "MT.Buy" || r.Errors[0].Raw != "X0.00" {
+		t.Errorf("Wrong report: %s %v", r, r.Errors)
+	}
+}
+
+func TestTWT43U_schema(t *testing.T) {
+	var (
+		date   = time.Date(2017, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		client = NewClient(WithFetcher(memFetcher{
+			fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.TWTXXU, "TWT43U", 2017, 3, 1)): `"106年03月01日 自營商買賣超彙總表 (股)"
+"證券代號","證券名稱","自營商(自行買賣)","","","自營商(避險)","","","自營商","",""
+"","","買進股數","賣出股數","買賣超股數","買進股數","賣出股數","買賣超股數","買進股數","賣出股數","買賣超股數"
+"2618","長榮航","1,000","200","800","0","500","-500","1,000","700","300"
+"合計","","1,000","200","800","0","500","-500","1,000","700","300"
+"說明:"
+`}))
+	)
+	data, err := client.NewTWT43U(date).Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := data["2618"]; v.Name != "長榮航" || v.Volume.Buy != 1000 || v.Volume.Sell != 700 || v.Volume.Total != 300 {
+		t.Errorf("Wrong data: %+v", data)
+	}
+}
@@ -1,15 +1,12 @@
 package twse
 
 import (
-	"encoding/csv"
 	"fmt"
 	"os"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/DoubleChuang/gogrs/tradingdays"
 	"github.com/DoubleChuang/gogrs/utils"
 	"github.com/pkg/errors"
 )
@@ -38,6 +35,17 @@ type Data struct {
 	dailyRangeList []float64
 	volumeList     []uint64
 	datelist       []time.Time
+	adjPriceList   []float64
+	adjustFactors  []float64
+	exRights       []ExRight
+	exRightsFrom   time.Time
+	exRightsTo     time.Time
+	exRightsManual bool
+	exRightsErr    error
+	adjusted       bool
+	report         *Report
+	fetcher        Fetcher
+	client         *Client
 }
 
 // NewTWSE 建立一個 TWSE 上市股票
@@ -62,16 +70,26 @@ func NewOTC(No string, Date time.Time) *Data {
 	}
 }
 
+// SetFetcher 指定擷取資料的 Fetcher
+func (d *Data) SetFetcher(f Fetcher) *Data {
+	d.fetcher = f
+	return d
+}
+
 // URL return stock csv url path.
 func (d Data) URL() string {
+	return d.urlAt(d.Date)
+}
+
+// urlAt return stock csv url path of the month of date.
+func (d Data) urlAt(date time.Time) string {
 	switch d.exchange {
 	case "tse":
-		return fmt.Sprintf("%s%s", utils.TWSEHOST,
-			fmt.Sprintf(utils.TWSECSV, d.Date.Year(), d.Date.Month(), d.Date.Day(), d.No))
+		return d.client.twseURL(fmt.Sprintf(utils.TWSECSV, date.Year(), date.Month(), date.Day(), d.No))
 	case "otc":
 		return fmt.Sprintf("%s%s",
-			utils.OTCHOST,
-			fmt.Sprintf(utils.OTCCSV, d.Date.Year()-1911, d.Date.Month(), d.No))
+			d.client.OTCHost(),
+			fmt.Sprintf(utils.OTCCSV, date.Year()-1911, date.Month(), d.No))
 	}
 
 	return ""
@@ -100,82 +118,168 @@ func (d *Data) clearCache() {
 	d.priceList = nil
 	d.volumeList = nil
 	d.datelist = nil
+	d.adjPriceList = nil
+	d.adjustFactors = nil
 }
 
 // Get return csv data in array.
 func (d *Data) Get() ([][]string, error) {
 	monthDateUnix := time.Date(d.Date.Year(), d.Date.Month(), 1, 0, 0, 0, 0, d.Date.Location()).Unix()
 	if _, exist := d.UnixMapData[monthDateUnix]; !exist ||
-		d.Date.Month() == tradingdays.FindRecentlyOpened(time.Now()).Month() {
-		var data []byte
-		var err error
-		//fmt.Println("stock url:", d.URL())
-		switch d.exchange {
-		case "tse":
-			data, err = hCache.PostForm(d.URL(), nil)
-		case "otc":
-			data, err = hCache.Get(d.URL(), true)
-		}
-		if err != nil {
-			return nil, fmt.Errorf(errorNetworkFail.Error(), err)
+		d.Date.Month() == d.client.Calendar().FindRecentlyOpened(time.Now()).Month() {
+		allData, name, report, err := d.fetchMonth(d.Date, d.Date)
+		d.report = report
+		if allData == nil && err != nil {
+			return nil, err
 		}
-		csvArrayContent := strings.Split(string(data), "\n")
-		for i := range csvArrayContent {
-			csvArrayContent[i] = strings.TrimSpace(csvArrayContent[i])
+		if d.Name == "" {
+			d.Name = name
 		}
-		var csvReader *csv.Reader
-
-		if (d.exchange == "tse" && len(csvArrayContent) > 2) || (d.exchange == "otc" && len(csvArrayContent) > 5) {
-			if d.exchange == "tse" {
-				var regdate = regexp.MustCompile(`^\"[0-9/]{7,}`)
-				if d.Name == "" {
-					groups := strings.Split(csvArrayContent[0], " ")
-					if len(groups) >= 2 {
-						d.No, d.Name = groups[1], groups[2]
-					} else {
-						return nil, errors.WithMessagef(errorNotEnoughData, "GroupFail:[%s]%s %s\n", d.No, utils.GetMD5FilePath(d), d.URL())
-					}
-				}
-				var datalist []string
-				for _, v := range csvArrayContent {
-					if regdate.MatchString(v) {
-						datalist = append(datalist, v)
-					}
-				}
-
-				csvReader = csv.NewReader(strings.NewReader(strings.Join(datalist, "\n")))
-			} else if d.exchange == "otc" {
-				if d.Name == "" {
-					d.Name = strings.Split(csvArrayContent[2], ":")[1]
-				}
-				csvReader = csv.NewReader(strings.NewReader(strings.Join(csvArrayContent[5:len(csvArrayContent)-1], "\n")))
-			}
-			allData, err := csvReader.ReadAll()
-			pickData := make([][]string, 0)
+		pickData := make([][]string, 0)
 
-			for _, v := range allData {
+		for _, v := range allData {
 
-				if d.BackupDate.After(utils.ParseDate(string(v[0]))) ||
-					d.BackupDate.Equal(utils.ParseDate(string(v[0]))) {
+			if d.BackupDate.After(utils.ParseDate(string(v[0]))) ||
+				d.BackupDate.Equal(utils.ParseDate(string(v[0]))) {
 
-					//utils.Dbgln(d.BackupDate, utils.ParseDate(string(v[0])))
-					pickData = append(pickData, v)
+				//utils.Dbgln(d.BackupDate, utils.ParseDate(string(v[0])))
+				pickData = append(pickData, v)
 
-				}
 			}
-
-			d.RawData = append(pickData, d.RawData...)
-			//d.RawData = append(allData, d.RawData...)
-			d.UnixMapData[monthDateUnix] = pickData
-			d.clearCache()
-			return pickData, err
 		}
 
-		return nil, errors.WithMessagef(errorNotEnoughData, "[%s]%s %s\n", d.No, utils.GetMD5FilePath(d), d.URL())
+		d.RawData = append(pickData, d.RawData...)
+		//d.RawData = append(allData, d.RawData...)
+		d.UnixMapData[monthDateUnix] = pickData
+		d.clearCache()
+		return pickData, err
 	}
 	return d.UnixMapData[monthDateUnix], nil
 }
 
+// urlFile 將網址轉為 utils.StockCsvFile
+type urlFile string
+
+func (u urlFile) URL() string {
+	return string(u)
+}
+
+// dailySchema 上市（STOCK_DAY）與上櫃（st43）個股日成交資訊的欄位
+var dailySchema = []column{
+	col("Date", "日期"),
+	col("Volume", "成交股數", "成交仟股"),
+	col("TotalPrice", "成交金額", "成交仟元"),
+	col("Open", "開盤價", "開盤"),
+	col("High", "最高價", "最高"),
+	col("Low", "最低價", "最低"),
+	col("Price", "收盤價", "收盤"),
+	col("Range", "漲跌價差", "漲跌"),
+	col("Totalsale", "成交筆數", "筆數"),
+}
+
+// fetchMonth 擷取 date 所在月份的所有資料、股票名稱與驗證報告，不修改 d
+//
+// Client 有指定 Store 時先讀取 Store，Store 未同步至 until 之前最後一個開市日才連線擷取並存回 Store，
+// 已同步至最後一筆擷取到的資料；已結束而沒有資料的月份同樣存回 Store，之後讀取時回傳 errorNotEnoughData 而不再連線。
+// 存回 Store 失敗時仍回傳擷取的資料與 *StoreError。
+func (d *Data) fetchMonth(date, until time.Time) ([][]string, string, *Report, error) {
+	var store = d.client.Store()
+	if store == nil {
+		return d.fetchMonthRemote(date)
+	}
+	var (
+		month  = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+		target = syncTarget(d.client.Calendar(), month, until)
+	)
+	if data, name, syncedTo, ok := store.Month(d.exchange, d.No, month); ok && dateKey(syncedTo) >= dateKey(target) {
+		if len(data) == 0 {
+			return nil, name, nil, errorNotEnoughData
+		}
+		var rows = make([][]string, len(data))
+		for i, v := range data {
+			rows[i] = formatRow(v)
+		}
+		return rows, name, &Report{Dataset: d.dataset(), Rows: len(rows)}, nil
+	}
+	rows, name, report, err := d.fetchMonthRemote(date)
+	var data []FmtData
+	switch {
+	case err == nil:
+		data = formatRows(rows)
+	case errors.Cause(err) == errorNotEnoughData:
+		// 已結束而沒有交易的月份（新上市、停牌）也視為已同步
+	default:
+		return rows, name, report, err
+	}
+	if synced := syncedTo(data, month, target); !synced.IsZero() {
+		if err := store.SaveMonth(d.exchange, d.No, name, month, data, synced); err != nil {
+			return rows, name, report, &StoreError{Month: month, Err: err}
+		}
+	}
+	return rows, name, report, err
+}
+
+// fetchMonthRemote 連線擷取 date 所在月份的所有資料與股票名稱，解析失敗的列不會回傳
+func (d *Data) fetchMonthRemote(date time.Time) ([][]string, string, *Report, error) {
+	rows, name, err := d.fetchMonthCSV(date)
+	if err != nil {
+		return rows, name, nil, err
+	}
+	rows, report, err := d.validate(rows)
+	return rows, name, report, err
+}
+
+// fetchMonthCSV 連線擷取 date 所在月份的資料與股票名稱，上市股票依 Client 的傳輸格式解析
+func (d *Data) fetchMonthCSV(date time.Time) ([][]string, string, error) {
+	var (
+		data []byte
+		err  error
+		name string
+		t    *table
+		url  = d.urlAt(date)
+		s    = schema{name: d.dataset(), columns: dailySchema}
+	)
+	//fmt.Println("stock url:", url)
+	switch d.exchange {
+	case "tse":
+		if data, err = d.client.fetchTWSE(d.fetcher).PostForm(url, nil); err != nil {
+			return nil, "", fmt.Errorf(errorNetworkFail.Error(), err)
+		}
+		if t, err = d.client.parse(s, data); err != nil {
+			return nil, "", err
+		}
+		groups := strings.Split(strings.Trim(t.title, `"`), " ")
+		if len(groups) > 2 {
+			name = groups[2]
+		} else if d.Name == "" {
+			return nil, "", errors.WithMessagef(errorNotEnoughData, "GroupFail:[%s]%s %s\n", d.No, utils.GetMD5FilePath(urlFile(url)), url)
+		}
+	case "otc":
+		if data, err = d.client.fetch(d.fetcher).Get(url, true); err != nil {
+			return nil, "", fmt.Errorf(errorNetworkFail.Error(), err)
+		}
+		if t, err = s.parse(data); err != nil {
+			return nil, "", err
+		}
+		if csvArrayContent := strings.Split(string(data), "\n"); len(csvArrayContent) > 2 {
+			if nameLine := strings.Split(strings.TrimSpace(csvArrayContent[2]), ":"); len(nameLine) > 1 {
+				name = nameLine[1]
+			}
+		}
+	default:
+		return nil, "", errorNotSupport
+	}
+
+	var rows = make([][]string, len(t.records))
+	for i, r := range t.records {
+		rows[i] = make([]string, len(dailySchema))
+		for j, c := range dailySchema {
+			rows[i][j] = r.get(c.key)
+		}
+	}
+	return rows, name, nil
+}
+
 // GetByTimeMap return a map by key of time.Time
 func (d Data) GetByTimeMap() map[time.Time]interface{} {
 	var (
@@ -295,33 +399,44 @@ func (d *Data) GetDateList() []time.Time {
 	return d.datelist
 }
 
-// MA 計算 收盤價 的移動平均
-func (d Data) MA(days int) []float64 {
-	var (
-		priceList = d.GetPriceList()
-		result    []float64
-	)
-	result = make([]float64, len(priceList)-days+1)
-	for i := range priceList[days-1:] {
-		result[i] = utils.AvgFloat64(priceList[i : i+days])
+// MA 計算 收盤價 的移動平均，SetAdjusted(true) 時使用還原權值的收盤價
+func (d *Data) MA(days int) []float64 {
+	if d.adjusted {
+		return calMA(d.GetAdjustedPriceList(), days)
+	}
+	return calMA(d.GetPriceList(), days)
+}
+
+// calMA 計算序列的移動平均，資料不足 days 筆時回傳空序列
+func calMA(list []float64, days int) []float64 {
+	if days < 1 || len(list) < days {
+		return []float64{}
+	}
+	var result = make([]float64, len(list)-days+1)
+	for i := range list[days-1:] {
+		result[i] = utils.AvgFloat64(list[i : i+days])
 	}
 	return result
 }
 
 // MABR 計算 收盤價移動平均 的乖離
-func (d Data) MABR(days1, days2 int) []float64 {
+func (d *Data) MABR(days1, days2 int) []float64 {
 	return utils.CalDiffFloat64(d.MA(days1), d.MA(days2))
 }
 
 // MAV 計算 成交股數 的移動平均
 func (d Data) MAV(days int) []uint64 {
-	var (
-		result     []uint64
-		volumeList = d.GetVolumeList()
-	)
-	result = make([]uint64, len(volumeList)-days+1)
-	for i := range volumeList[days-1:] {
-		result[i] = utils.AvgUint64(volumeList[i : i+days])
+	return calMAV(d.GetVolumeList(), days)
+}
+
+// calMAV 計算序列的移動平均（uint64），資料不足 days 筆時回傳空序列
+func calMAV(list []uint64, days int) []uint64 {
+	if days < 1 || len(list) < days {
+		return []uint64{}
+	}
+	var result = make([]uint64, len(list)-days+1)
+	for i := range list[days-1:] {
+		result[i] = utils.AvgUint64(list[i : i+days])
 	}
 	return result
 }
@@ -370,46 +485,82 @@ func (d Data) Len() int {
 
 // FmtData is struct for daily data format.
 type FmtData struct {
-	Date       time.Time
-	Volume     uint64  //成交股數
-	TotalPrice uint64  //成交金額
-	Open       float64 //開盤價
-	High       float64 //最高價
-	Low        float64 //最低價
-	Price      float64 //收盤價
-	Range      float64 //漲跌價差
-	Totalsale  uint64  //成交筆數
+	Date       time.Time `json:"date"`
+	Volume     uint64    `json:"volume"`      //成交股數
+	TotalPrice uint64    `json:"total_price"` //成交金額
+	Open       float64   `json:"open"`        //開盤價
+	High       float64   `json:"high"`        //最高價
+	Low        float64   `json:"low"`         //最低價
+	Price      float64   `json:"price"`       //收盤價
+	Range      float64   `json:"range"`       //漲跌價差
+	Totalsale  uint64    `json:"totalsale"`   //成交筆數
 }
 
 // FormatData is format daily data.
 func (d Data) FormatData() []FmtData {
 	var (
-		loopd  FmtData
-		result []FmtData
+		p      = newParser(d.dataset(), false)
+		result = make([]FmtData, len(d.RawData))
 	)
-	result = make([]FmtData, len(d.RawData))
 	for i, v := range d.RawData {
-		if len(v) >= 8 {
-			vdate := utils.ParseDate(v[0])
-			if vdate.IsZero() == false {
-				loopd.Date = vdate
-				loopd.Volume, _ = strconv.ParseUint(strings.Replace(v[1], ",", "", -1), 10, 64)
-				loopd.TotalPrice, _ = strconv.ParseUint(strings.Replace(v[2], ",", "", -1), 10, 64)
-				loopd.Open, _ = strconv.ParseFloat(v[3], 64)
-				loopd.High, _ = strconv.ParseFloat(v[4], 64)
-				loopd.Low, _ = strconv.ParseFloat(v[5], 64)
-				loopd.Price, _ = strconv.ParseFloat(v[6], 64)
-				loopd.Range, _ = strconv.ParseFloat(v[7], 64)
-				loopd.Totalsale, _ = strconv.ParseUint(strings.Replace(v[8], ",", "", -1), 10, 64)
-				result[i] = loopd
-			}
+		if len(v) > 0 && !utils.ParseDate(v[0]).IsZero() {
+			p.next()
+			result[i] = parseDailyRow(p, v)
 		}
 	}
 	return result
 }
 
-var hCache *utils.HTTPCache
+// parseDailyRow 解析一列每日資料
+func parseDailyRow(p *parser, v []string) FmtData {
+	if !p.columns(v, 9) {
+		return FmtData{}
+	}
+	return FmtData{
+		Date:       utils.ParseDate(v[0]),
+		Volume:     p.uint("Volume", v[1]),
+		TotalPrice: p.uint("TotalPrice", v[2]),
+		Open:       p.float("Open", v[3]),
+		High:       p.float("High", v[4]),
+		Low:        p.float("Low", v[5]),
+		Price:      p.float("Price", v[6]),
+		Range:      p.change("Range", v[7]),
+		Totalsale:  p.uint("Totalsale", v[8]),
+	}
+}
+
+// dataset 驗證報告中的資料集名稱
+func (d Data) dataset() string {
+	return fmt.Sprintf("%s %s", d.exchange, d.No)
+}
+
+// validate 解析每一列每日資料，寬鬆模式略過解析失敗的列，嚴格模式回傳第一個 *ParseError
+//
+// 無成交（價格為 "--"）的日子也會略過，避免以 0 計算均線。
+func (d Data) validate(rows [][]string) ([][]string, *Report, error) {
+	var (
+		p      = newParser(d.dataset(), d.client.Strict())
+		result = make([][]string, 0, len(rows))
+	)
+	for _, v := range rows {
+		if len(v) == 0 || utils.ParseDate(v[0]).IsZero() {
+			continue
+		}
+		p.next()
+		parseDailyRow(p, v)
+		if err := p.err(); err != nil {
+			return nil, p.report, err
+		}
+		if p.bad {
+			p.skip()
+			continue
+		}
+		result = append(result, v)
+	}
+	return result, p.report, nil
+}
 
-func init() {
-	hCache = utils.NewHTTPCache(utils.GetOSRamdiskPath(""), "cp950")
+// Report 回傳最近一次 Get、LoadRange 的驗證報告
+func (d Data) Report() *Report {
+	return d.report
 }
@@ -0,0 +1,203 @@
+package twse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/tradingdays"
+	"github.com/DoubleChuang/gogrs/utils"
+	"github.com/pkg/errors"
+)
+
+// Store 本地歷史資料，以上市上櫃、股票代號、月份存放每日資料
+//
+// Client 指定 Store 後，Data 會先讀取 Store，只有尚未同步的月份才會連線擷取。
+type Store interface {
+	// Month 回傳 month 所在月份的資料、股票名稱與已同步至哪一日，無資料時 ok 為 false
+	Month(exchange, no string, month time.Time) (data []FmtData, name string, syncedTo time.Time, ok bool)
+	// SaveMonth 存入 month 所在月份的資料，syncedTo 之前（含）的資料視為完整
+	SaveMonth(exchange, no, name string, month time.Time, data []FmtData, syncedTo time.Time) error
+}
+
+// StoreError 已擷取的資料存入 Store 失敗，資料仍會回傳
+type StoreError struct {
+	Month time.Time
+	Err   error
+}
+
+func (e *StoreError) Error() string {
+	return fmt.Sprintf("store %d/%02d: %s", e.Month.Year(), e.Month.Month(), e.Err)
+}
+
+// FileStore 以 JSON 檔案存放的 Store，每一檔股票每個月份一個檔案：dir/exchange/no/yyyymm.json
+type FileStore struct {
+	dir    string
+	mu     sync.Mutex
+	months map[string]*storeMonth
+}
+
+// storeMonth 單一股票一個月份的資料
+type storeMonth struct {
+	Name     string    `json:"name"`
+	SyncedTo time.Time `json:"synced_to"` // 已同步至哪一日
+	Data     []FmtData `json:"data"`      // 每日資料，沒有交易的月份為空
+}
+
+// NewFileStore 建立 FileStore，dir 不存在時會建立
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir, months: make(map[string]*storeMonth)}, nil
+}
+
+func monthKey(month time.Time) string {
+	return fmt.Sprintf("%d%02d", month.Year(), month.Month())
+}
+
+// path 月份檔案的位置
+func (s *FileStore) path(exchange, no string, month time.Time) string {
+	return filepath.Join(s.dir, exchange, no, monthKey(month)+".json")
+}
+
+// load 取得月份資料，第一次讀取時載入檔案；檔案不存在時回傳 nil，呼叫時需持有 s.mu
+func (s *FileStore) load(path string) (*storeMonth, error) {
+	if m, ok := s.months[path]; ok {
+		return m, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var m = &storeMonth{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, errors.Wrapf(err, "store: %s", path)
+	}
+	s.months[path] = m
+	return m, nil
+}
+
+// Month 回傳 month 所在月份的資料
+func (s *FileStore) Month(exchange, no string, month time.Time) ([]FmtData, string, time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.load(s.path(exchange, no, month))
+	if err != nil || m == nil {
+		return nil, "", time.Time{}, false
+	}
+	return append([]FmtData(nil), m.Data...), m.Name, m.SyncedTo, true
+}
+
+// SaveMonth 存入 month 所在月份的資料，只寫入該月份的檔案
+func (s *FileStore) SaveMonth(exchange, no, name string, month time.Time, data []FmtData, syncedTo time.Time) error {
+	var bars = make([]FmtData, 0, len(data))
+	for _, v := range data {
+		if !v.Date.IsZero() {
+			bars = append(bars, v)
+		}
+	}
+	sort.Slice(bars, func(i, j int) bool { return bars[i].Date.Before(bars[j].Date) })
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var path = s.path(exchange, no, month)
+	m, err := s.load(path)
+	if err != nil || m == nil {
+		m = &storeMonth{}
+	}
+	if name != "" {
+		m.Name = name
+	}
+	m.SyncedTo, m.Data = syncedTo, bars
+
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, body, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	s.months[path] = m
+	return nil
+}
+
+// formatRow 將每日資料轉回與 RawData 相同格式的欄位
+func formatRow(v FmtData) []string {
+	var (
+		year, month, day = v.Date.In(utils.TaipeiTimeZone).Date()
+		float            = func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	)
+	return []string{
+		fmt.Sprintf("%d/%02d/%02d", year-1911, month, day),
+		strconv.FormatUint(v.Volume, 10),
+		strconv.FormatUint(v.TotalPrice, 10),
+		float(v.Open),
+		float(v.High),
+		float(v.Low),
+		float(v.Price),
+		float(v.Range),
+		strconv.FormatUint(v.Totalsale, 10),
+	}
+}
+
+// formatRows 將 RawData 格式的資料轉為每日資料
+func formatRows(rows [][]string) []FmtData {
+	var data = make([][]string, len(rows))
+	for i, row := range rows {
+		data[i] = make([]string, len(row))
+		for j, v := range row {
+			data[i][j] = strings.Replace(v, ",", "", -1)
+		}
+	}
+	return Data{RawData: data}.FormatData()
+}
+
+// syncTarget 回傳 month 所在月份需要同步至哪一日：until 之前（含）該月最後一個開市日，
+// 尚未結束的月份不超過最近一個開市日
+func syncTarget(calendar *tradingdays.Calendar, month, until time.Time) time.Time {
+	var (
+		recent = calendar.FindRecentlyOpened(time.Now())
+		day    = time.Date(month.Year(), month.Month()+1, 0, 0, 0, 0, 0, month.Location())
+	)
+	for _, v := range []time.Time{recent, until} {
+		if dateKey(v) < dateKey(day) {
+			day = time.Date(v.Year(), v.Month(), v.Day(), 0, 0, 0, 0, month.Location())
+		}
+	}
+	for day.Month() == month.Month() && !calendar.IsOpen(day.Year(), day.Month(), day.Day()) {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
+// syncedTo 擷取的資料已同步至哪一日：最後一筆資料的日期；
+// 沒有資料時，已結束的月份為 target，尚未結束的月份為零值（不視為已同步）
+func syncedTo(data []FmtData, month, target time.Time) time.Time {
+	var last time.Time
+	for _, v := range data {
+		if v.Date.After(last) {
+			last = v.Date
+		}
+	}
+	if last.IsZero() && dateKey(time.Now().In(month.Location())) > dateKey(month.AddDate(0, 1, -1)) {
+		return target
+	}
+	return last
+}
@@ -0,0 +1,143 @@
+package twse
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+// countFetcher 計算連線擷取次數
+type countFetcher struct {
+	memFetcher
+	count int
+}
+
+func (c *countFetcher) PostForm(url string, data url.Values) ([]byte, error) {
+	c.count++
+	return c.memFetcher.PostForm(url, data)
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gogrs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		date = time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		f    = &countFetcher{memFetcher: memFetcher{}}
+	)
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewClient(WithFetcher(f), WithStore(store)).NewTWSE("2618", date)
+	f.memFetcher[d.URL()] = fixtureSTOCKDAY
+	if _, err := d.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if f.count != 1 {
+		t.Errorf("Should fetch 1 but %d", f.count)
+	}
+
+	// 重新開啟 Store，已同步的月份不再連線擷取
+	store, err = NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewClient(WithFetcher(f), WithStore(store)).NewTWSE("2618", date)
+	if err := s.LoadRange(time.Date(2015, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone), date); err != nil {
+		t.Fatal(err)
+	}
+	if f.count != 1 {
+		t.Errorf("Should fetch 1 but %d", f.count)
+	}
+	if s.Name != "長榮航" || s.Len() != 3 {
+		t.Errorf("Should be 長榮航 3 but %s %d", s.Name, s.Len())
+	}
+	raw, stored := d.FormatData(), s.FormatData()
+	for i := range raw {
+		if raw[i] != stored[i] {
+			t.Errorf("Should be %+v but %+v", raw[i], stored[i])
+		}
+	}
+	if v := s.GetVolumeList(); v[0] != 13384378 {
+		t.Errorf("Should be 13384378 but %d", v[0])
+	}
+}
+
+func TestFileStore_emptyMonth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gogrs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		from = time.Date(2015, 2, 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		to   = time.Date(2015, 2, 27, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		f    = &countFetcher{memFetcher: memFetcher{}}
+	)
+	for i := 0; i < 2; i++ {
+		// 每次重新開啟 Store，沒有資料的月份只擷取一次
+		store, err := NewFileStore(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := NewClient(WithFetcher(f), WithStore(store)).NewTWSE("2618", to)
+		f.memFetcher[d.URL()] = `"很抱歉，沒有符合條件的資料!"`
+		err = d.LoadRange(from, to)
+		if loadErr, ok := err.(*LoadError); !ok || !loadErr.NoData() {
+			t.Fatalf("Should be no data but %v", err)
+		}
+		if f.count != 1 {
+			t.Errorf("Should fetch 1 but %d", f.count)
+		}
+	}
+}
+
+func TestFileStore_partialMonth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gogrs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		from = time.Date(2015, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		to   = time.Date(2015, 3, 31, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		f    = &countFetcher{memFetcher: memFetcher{}}
+	)
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var client = NewClient(WithFetcher(f), WithStore(store))
+	// 擷取時最後一筆資料為 3/4，3/31 之前的資料尚未完整
+	f.memFetcher[client.NewTWSE("2618", to).URL()] = fixtureSTOCKDAY
+	for i := 1; i <= 2; i++ {
+		if err := client.NewTWSE("2618", to).LoadRange(from, to); err != nil {
+			t.Fatal(err)
+		}
+		if f.count != i {
+			t.Errorf("Should fetch %d but %d", i, f.count)
+		}
+	}
+	if _, _, syncedTo, _ := store.Month("tse", "2618", from); syncedTo.Day() != 4 {
+		t.Errorf("Should sync to 3/4 but %s", syncedTo)
+	}
+
+	// 只需要 3/4 之前的資料時不再連線擷取
+	d := client.NewTWSE("2618", time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone))
+	if err := d.LoadRange(from, d.Date); err != nil {
+		t.Fatal(err)
+	}
+	if f.count != 2 || d.Len() != 3 {
+		t.Errorf("Should fetch 2 but %d, len %d", f.count, d.Len())
+	}
+}
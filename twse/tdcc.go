@@ -0,0 +1,265 @@
+package twse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+// 集保戶股權分散表的持股分級
+const (
+	TierCount  = 15 // 1~15 級依持股股數分級
+	TierAdjust = 16 // 差異數調整
+	TierTotal  = 17 // 合計
+)
+
+// BigHolderLots 大股東的持股張數門檻，持股超過 400 張
+const BigHolderLots = 400
+
+// RetailLots 散戶的持股張數門檻，持股 10 張以下
+const RetailLots = 10
+
+// tierLower 各持股分級的最低持股股數，index 為分級
+var tierLower = [TierCount + 2]uint64{0, 1, 1000, 5001, 10001, 15001, 20001, 30001, 40001, 50001,
+	100001, 200001, 400001, 600001, 800001, 1000001, 0}
+
+// HolderTier 持股分級的人數、股數與占集保庫存數比例
+type HolderTier struct {
+	Level   int     `json:"level"`
+	Holders uint64  `json:"holders"` // 人數
+	Shares  uint64  `json:"shares"`  // 股數
+	Ratio   float64 `json:"ratio"`   // 占集保庫存數比例(%)
+}
+
+// Distribution 個股一週的集保戶股權分散表
+type Distribution struct {
+	Date  time.Time             `json:"date"`
+	No    string                `json:"no"`
+	Tiers [TierCount]HolderTier `json:"tiers"` // 1~15 級，Tiers[0] 為第 1 級
+	Total HolderTier            `json:"total"` // 合計
+}
+
+// RatioAbove 持股超過 lots 張的比例(%)，lots 以分級的股數區間計算
+func (d Distribution) RatioAbove(lots int) float64 {
+	var sum float64
+	for _, t := range d.Tiers {
+		if t.Level > 0 && tierLower[t.Level] > uint64(lots)*1000 {
+			sum += t.Ratio
+		}
+	}
+	return round(sum)
+}
+
+// HoldersBelow 持股 lots 張以下的人數，lots 以分級的股數區間計算
+func (d Distribution) HoldersBelow(lots int) uint64 {
+	var sum uint64
+	for _, t := range d.Tiers {
+		if t.Level > 0 && t.Level < TierCount && tierLower[t.Level+1]-1 <= uint64(lots)*1000 {
+			sum += t.Holders
+		}
+	}
+	return sum
+}
+
+// BigHolderRatio 大股東（持股超過 BigHolderLots 張）的持股比例(%)
+func (d Distribution) BigHolderRatio() float64 {
+	return d.RatioAbove(BigHolderLots)
+}
+
+// RetailHolders 散戶（持股 RetailLots 張以下）的人數
+func (d Distribution) RetailHolders() uint64 {
+	return d.HoldersBelow(RetailLots)
+}
+
+// round 取到小數第二位
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// tdccSchema 集保戶股權分散表開放資料的欄位
+var tdccSchema = []column{
+	col("Date", "資料日期"),
+	col("No", "證券代號"),
+	col("Level", "持股分級"),
+	col("Holders", "人數"),
+	col("Shares", "股數"),
+	col("Ratio", "占集保庫存數比例%", "占集保庫存數比例(%)"),
+}
+
+// TDCC 集保戶股權分散表，開放資料只提供最近一週
+type TDCC struct {
+	Date    time.Time
+	FmtData map[string]Distribution
+	report  *Report
+	fetcher Fetcher
+	client  *Client
+}
+
+// NewTDCC 建立集保戶股權分散表
+func NewTDCC() *TDCC {
+	return &TDCC{FmtData: make(map[string]Distribution)}
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (t *TDCC) SetFetcher(f Fetcher) *TDCC {
+	t.fetcher = f
+	return t
+}
+
+// URL 擷取網址
+func (t TDCC) URL() string {
+	return utils.TDCCCSV
+}
+
+// Get 擷取最近一週全部股票的股權分散表，欄位解析失敗時記錄在 Report()
+func (t *TDCC) Get() (map[string]Distribution, error) {
+	var s = schema{name: "tdcc distribution", columns: tdccSchema}
+	data, err := t.client.fetchUTF8(t.fetcher).Get(t.URL(), false)
+	if err != nil {
+		return nil, fmt.Errorf(errorNetworkFail.Error(), err)
+	}
+	table, err := s.parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var p = newParser(s.name, t.client.Strict())
+	t.report = p.report
+	for _, r := range table.records {
+		p.next()
+		date, err := time.ParseInLocation("20060102", r.get("Date"), utils.TaipeiTimeZone)
+		if err != nil {
+			p.fail("Date", r.get("Date"), err)
+		}
+		var (
+			no    = r.get("No")
+			level = int(p.uint("Level", r.get("Level")))
+			tier  = HolderTier{
+				Level:   level,
+				Holders: p.optUint("Holders", r.get("Holders")),
+				Shares:  p.optUint("Shares", r.get("Shares")),
+				Ratio:   p.optFloat("Ratio", r.get("Ratio")),
+			}
+		)
+		if err := p.err(); err != nil {
+			return nil, err
+		}
+		if p.bad {
+			continue
+		}
+
+		d := t.FmtData[no]
+		d.Date, d.No = date, no
+		switch {
+		case level >= 1 && level <= TierCount:
+			d.Tiers[level-1] = tier
+		case level == TierTotal:
+			d.Total = tier
+		case level == TierAdjust:
+		default:
+			p.skip()
+			continue
+		}
+		t.FmtData[no] = d
+		t.Date = date
+	}
+	return t.FmtData, nil
+}
+
+// Report 回傳最近一次 Get 的驗證報告
+func (t TDCC) Report() *Report {
+	return t.report
+}
+
+// DistributionChange 個股股權分散與前一週的變化
+type DistributionChange struct {
+	Date               time.Time `json:"date"`
+	No                 string    `json:"no"`
+	BigHolderRatio     float64   `json:"big_holder_ratio"`      // 大股東持股比例(%)
+	BigHolderRatioDiff float64   `json:"big_holder_ratio_diff"` // 與前一週的差
+	RetailHolders      uint64    `json:"retail_holders"`        // 散戶人數
+	RetailHoldersDiff  int64     `json:"retail_holders_diff"`   // 與前一週的差
+	Holders            uint64    `json:"holders"`               // 總人數
+	HoldersDiff        int64     `json:"holders_diff"`          // 與前一週的差
+}
+
+// DistributionHistory 以股票代碼取得依日期排序的每週股權分散表
+//
+// 開放資料只提供最近一週，歷史資料需每週 Add 後以 Save 保存，下次再以 Load 讀回。
+type DistributionHistory struct {
+	Data map[string][]Distribution
+}
+
+// NewDistributionHistory 建立股權分散表的歷史資料
+func NewDistributionHistory() *DistributionHistory {
+	return &DistributionHistory{Data: make(map[string][]Distribution)}
+}
+
+// Add 加入一週的股權分散表，同一天的資料會被取代
+func (h *DistributionHistory) Add(week map[string]Distribution) *DistributionHistory {
+	for no, d := range week {
+		var list = make([]Distribution, 0, len(h.Data[no])+1)
+		for _, v := range h.Data[no] {
+			if !v.Date.Equal(d.Date) {
+				list = append(list, v)
+			}
+		}
+		list = append(list, d)
+		sort.Slice(list, func(i, j int) bool { return list[i].Date.Before(list[j].Date) })
+		h.Data[no] = list
+	}
+	return h
+}
+
+// Stock 回傳個股依日期排序的股權分散表
+func (h DistributionHistory) Stock(no string) []Distribution {
+	return h.Data[no]
+}
+
+// Changes 回傳個股每一週與前一週的變化，第一週沒有前一週可比較時差值為 0
+func (h DistributionHistory) Changes(no string) []DistributionChange {
+	var (
+		data   = h.Data[no]
+		result = make([]DistributionChange, len(data))
+	)
+	for i, d := range data {
+		result[i] = DistributionChange{
+			Date:           d.Date,
+			No:             d.No,
+			BigHolderRatio: d.BigHolderRatio(),
+			RetailHolders:  d.RetailHolders(),
+			Holders:        d.Total.Holders,
+		}
+		if i > 0 {
+			prev := result[i-1]
+			result[i].BigHolderRatioDiff = round(result[i].BigHolderRatio - prev.BigHolderRatio)
+			result[i].RetailHoldersDiff = int64(result[i].RetailHolders) - int64(prev.RetailHolders)
+			result[i].HoldersDiff = int64(result[i].Holders) - int64(prev.Holders)
+		}
+	}
+	return result
+}
+
+// Save 將歷史資料以 JSON 寫入 w
+func (h DistributionHistory) Save(w io.Writer) error {
+	return json.NewEncoder(w).Encode(h.Data)
+}
+
+// Load 從 r 讀取 Save 保存的歷史資料並合併
+func (h *DistributionHistory) Load(r io.Reader) error {
+	var data map[string][]Distribution
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return err
+	}
+	for no, list := range data {
+		for _, d := range list {
+			h.Add(map[string]Distribution{no: d})
+		}
+	}
+	return nil
+}
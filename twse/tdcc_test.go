@@ -0,0 +1,78 @@
+package twse
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+// tdccCSV 建立一週 2618 的股權分散表，bigRatio 為 400 張以上各級的比例，retail 為 10 張以下各級的人數
+func tdccCSV(date string, bigRatio string, retail string) string {
+	var data = "\ufeff資料日期,證券代號,持股分級,人數,股數,占集保庫存數比例%\n"
+	for level := 1; level <= 17; level++ {
+		var holders, ratio = "100", "1.00"
+		switch {
+		case level <= 3:
+			holders = retail
+		case level >= 12 && level <= 15:
+			ratio = bigRatio
+		case level == 16:
+			holders, ratio = "0", "0.00"
+		case level == 17:
+			holders, ratio = "99999", "100.00"
+		}
+		data += date + ",2618," + strconv.Itoa(level) + "," + holders + ",1000," + ratio + "\n"
+	}
+	return data
+}
+
+func TestTDCC_Get(t *testing.T) {
+	var (
+		client = NewClient(WithFetcher(memFetcher{utils.TDCCCSV: tdccCSV("20170303", "15.25", "1000")}))
+		tdcc   = client.NewTDCC()
+	)
+	data, err := tdcc.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := data["2618"]
+	if !tdcc.Date.Equal(time.Date(2017, 3, 3, 0, 0, 0, 0, utils.TaipeiTimeZone)) || d.Tiers[0].Holders != 1000 || d.Total.Holders != 99999 {
+		t.Fatalf("Wrong data: %+v", d)
+	}
+	if d.BigHolderRatio() != 61 || d.RatioAbove(1000) != 15.25 || d.RetailHolders() != 3000 || d.HoldersBelow(50) != 3500 {
+		t.Errorf("Wrong ratio: %v %v %v %v", d.BigHolderRatio(), d.RatioAbove(1000), d.RetailHolders(), d.HoldersBelow(50))
+	}
+	if r := tdcc.Report(); r.Rows != 17 || !r.OK() {
+		t.Errorf("Wrong report: %s", r)
+	}
+}
+
+func TestDistributionHistory_Changes(t *testing.T) {
+	var h = NewDistributionHistory()
+	for _, v := range [][3]string{{"20170310", "15.50", "900"}, {"20170303", "15.25", "1000"}} {
+		data, err := NewClient(WithFetcher(memFetcher{utils.TDCCCSV: tdccCSV(v[0], v[1], v[2])})).NewTDCC().Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.Add(data)
+	}
+
+	var buf bytes.Buffer
+	if err := h.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var loaded = NewDistributionHistory()
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	changes := loaded.Changes("2618")
+	if len(changes) != 2 || changes[0].Date.Day() != 3 || changes[0].RetailHoldersDiff != 0 {
+		t.Fatalf("Wrong changes: %+v", changes)
+	}
+	if v := changes[1]; v.BigHolderRatio != 62 || v.BigHolderRatioDiff != 1 || v.RetailHoldersDiff != -300 || v.HoldersDiff != 0 {
+		t.Errorf("Wrong change: %+v", v)
+	}
+}
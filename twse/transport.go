@@ -0,0 +1,197 @@
+package twse
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Transport 上市資料的傳輸格式
+type Transport int
+
+// 傳輸格式
+const (
+	// TransportCSV response=csv，需將 cp950 轉為 utf8，預設值
+	TransportCSV Transport = iota
+	// TransportJSON response=json，依 fields 的欄位名稱解析，不需轉換編碼
+	//
+	// 支援 STOCK_DAY、MI_INDEX、T86、MI_MARGN 與零股行情，其他報表與上櫃資料仍使用 CSV。
+	TransportJSON
+)
+
+func (t Transport) String() string {
+	switch t {
+	case TransportJSON:
+		return "json"
+	default:
+		return "csv"
+	}
+}
+
+// jsonTable JSON 回應中的一個表格
+type jsonTable struct {
+	Title  string          `json:"title"`
+	Fields []string        `json:"fields"`
+	Data   [][]interface{} `json:"data"`
+}
+
+// jsonCell 將 JSON 欄位值轉為字串，數值保留原始格式
+func jsonCell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// readJSON 讀取 JSON 回應中的所有表格，每個表格依序為標題、fields 與 data 的列
+//
+// 支援 {"fields", "data"}、{"fields1", "data1", ...}、{"xxxFields", "xxxList"}
+// 與 {"tables": [{"title", "fields", "data"}]} 的格式。stat 不為 OK 時回傳 errorNotEnoughData。
+func readJSON(data []byte) (string, [][]string, error) {
+	var (
+		doc    map[string]json.RawMessage
+		tables []jsonTable
+		title  string
+		rows   [][]string
+	)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return "", nil, errors.Wrap(err, "decode json")
+	}
+	var stat string
+	json.Unmarshal(doc["stat"], &stat)
+	if stat != "" && stat != "OK" {
+		return "", nil, errors.WithMessage(errorNotEnoughData, stat)
+	}
+	json.Unmarshal(doc["title"], &title)
+
+	var keys = make([]string, 0, len(doc))
+	for k := range doc {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var dataKey string
+		switch {
+		case k == "tables":
+			var list []jsonTable
+			if err := unmarshalNumber(doc[k], &list); err != nil {
+				return "", nil, errors.Wrap(err, "decode json tables")
+			}
+			tables = append(tables, list...)
+			continue
+		case strings.HasPrefix(k, "fields"):
+			dataKey = "data" + strings.TrimPrefix(k, "fields")
+		case strings.HasSuffix(k, "Fields"):
+			dataKey = strings.TrimSuffix(k, "Fields") + "List"
+		default:
+			continue
+		}
+		var t jsonTable
+		if err := json.Unmarshal(doc[k], &t.Fields); err != nil {
+			return "", nil, errors.Wrapf(err, "decode json %s", k)
+		}
+		if raw, ok := doc[dataKey]; ok {
+			if err := unmarshalNumber(raw, &t.Data); err != nil {
+				return "", nil, errors.Wrapf(err, "decode json %s", dataKey)
+			}
+		}
+		tables = append(tables, t)
+	}
+
+	for _, t := range tables {
+		if title == "" {
+			title = t.Title
+		}
+		rows = append(rows, []string{t.Title}, t.Fields)
+		for _, v := range t.Data {
+			var row = make([]string, len(v))
+			for i, cell := range v {
+				row[i] = jsonCell(cell)
+			}
+			rows = append(rows, row)
+		}
+	}
+	return title, rows, nil
+}
+
+func unmarshalNumber(raw json.RawMessage, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// parseJSON 同 parse，data 為 response=json 的回應
+func (s schema) parseJSON(data []byte) (*table, error) {
+	title, rows, err := readJSON(data)
+	if err != nil {
+		return nil, errors.WithMessage(err, s.name)
+	}
+	t, err := s.parseRows(rows)
+	if err == nil {
+		t.title = title
+	}
+	return t, err
+}
+
+// twseURL 上市報表網址，TransportJSON 時改為 response=json
+func (c *Client) twseURL(path string) string {
+	var url = c.TWSEHost() + path
+	if c.Transport() == TransportJSON {
+		url = strings.Replace(url, "response=csv", "response=json", 1)
+	}
+	return url
+}
+
+// parse 依 Client 的傳輸格式解析 twseURL 擷取的資料
+func (c *Client) parse(s schema, data []byte) (*table, error) {
+	if c.Transport() == TransportJSON {
+		return s.parseJSON(data)
+	}
+	return s.parse(data)
+}
+
+// fetchTWSE 回傳擷取 twseURL 資料的 Fetcher，f 不為 nil 時優先使用；
+// TransportJSON 時預設的 HTTPCache 不轉換編碼
+func (c *Client) fetchTWSE(f Fetcher) Fetcher {
+	if f != nil {
+		return f
+	}
+	if c.Transport() == TransportJSON {
+		return c.fetchUTF8(nil)
+	}
+	return c.use().fetcher
+}
+
+// fetchTable 擷取上市（tse）或上櫃（otc）的報表並依表頭解析，
+// 上市以 POST 擷取並依 Client 的傳輸格式解析，上櫃為 CSV
+func (c *Client) fetchTable(f Fetcher, exchange, url string, s schema) (*table, error) {
+	var (
+		data []byte
+		err  error
+	)
+	switch exchange {
+	case "tse":
+		if data, err = c.fetchTWSE(f).PostForm(url, nil); err != nil {
+			return nil, fmt.Errorf(errorNetworkFail.Error(), err)
+		}
+		return c.parse(s, data)
+	case "otc":
+		if data, err = c.fetch(f).Get(url, false); err != nil {
+			return nil, fmt.Errorf(errorNetworkFail.Error(), err)
+		}
+		return s.parse(data)
+	}
+	return nil, errorNotSupport
+}
@@ -0,0 +1,80 @@
+package twse
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+func TestData_transportJSON(t *testing.T) {
+	var (
+		date   = time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		url    = fmt.Sprintf("%s/exchangeReport/STOCK_DAY?response=json&date=20150304&stockNo=2618", utils.TWSEHOST)
+		client = NewClient(WithTransport(TransportJSON), WithFetcher(memFetcher{url: `{
+"stat":"OK","date":"20150304","title":"104年03月 2618 長榮航           各日成交資訊",
+"fields":["日期","成交股數","成交金額","開盤價","最高價","最低價","收盤價","漲跌價差","成交筆數"],
+"data":[["104/03/02","13,384,378","305,046,992","23.00","23.05","22.50","22.90","-0.10","3,793"],
+["104/03/03","10,061,202","229,871,452","22.90","23.05","22.75","22.90","0.00","2,893"],
+["104/03/04","19,225,398","447,870,545","22.90","23.50","22.90","23.40","+0.50",5148]],
+"notes":["符號說明:+/-/X表示漲/跌/不比價"]}`}))
+		d = client.NewTWSE("2618", date)
+	)
+	if d.URL() != url {
+		t.Fatalf("Wrong url: %s", d.URL())
+	}
+	if _, err := d.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Name != "長榮航" || d.Len() != 3 {
+		t.Fatalf("Wrong data: %s %v", d.Name, d.RawData)
+	}
+	if data := d.FormatData(); data[2].Totalsale != 5148 || data[2].Range != 0.5 || data[0].Volume != 13384378 {
+		t.Errorf("Wrong data: %+v", data)
+	}
+
+	d = client.NewTWSE("2618", date).SetFetcher(memFetcher{url: `{"stat":"很抱歉，沒有符合條件的資料!"}`})
+	if _, err := d.Get(); err == nil {
+		t.Error("Should be error")
+	}
+}
+
+func TestLists_transportJSON(t *testing.T) {
+	var (
+		date   = time.Date(2017, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		client = NewClient(WithTransport(TransportJSON), WithFetcher(memFetcher{
+			fmt.Sprintf("%s/exchangeReport/MI_INDEX?response=json&date=20170301&type=ALLBUT0999", utils.TWSEHOST): `{
+"stat":"OK",
+"fields1":["指數","收盤指數","漲跌(+/-)","漲跌點數","漲跌百分比(%)"],
+"data1":[["發行量加權股價指數","9,674.78","<p style= color:green>-</p>","75.69","-0.78"]],
+"fields5":["證券代號","證券名稱","成交股數","成交筆數","成交金額","開盤價","最高價","最低價","收盤價","漲跌(+/-)","漲跌價差","最後揭示買價","最後揭示買量","最後揭示賣價","最後揭示賣量","本益比"],
+"data5":[["1101","台泥","8,318,131","3,983","307,612,427","37.20","37.30","36.80","36.85","<p style= color:green>-</p>","0.45","36.85","158","36.90","45","24.57"]]}`}))
+		l = client.NewLists(date)
+	)
+	if _, err := l.Get("ALLBUT0999"); err != nil {
+		t.Fatal(err)
+	}
+	if data := l.FmtData["1101"]; data.Name != "台泥" || data.Range != -0.45 || data.PERatio != 24.57 {
+		t.Errorf("Wrong data: %+v", data)
+	}
+}
+
+func TestTWMTSS_transportJSON(t *testing.T) {
+	var (
+		date   = time.Date(2017, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		client = NewClient(WithTransport(TransportJSON), WithFetcher(memFetcher{
+			fmt.Sprintf("%s/exchangeReport/MI_MARGN?response=json&date=20170301&selectType=ALL", utils.TWSEHOST): `{
+"stat":"OK","tables":[
+{"title":"信用交易統計","fields":["項目","買進","賣出","現金(券)償還","前日餘額","今日餘額"],"data":[["融資(交易單位)","151,234","140,123","2,345","5,678,901","5,687,667"]]},
+{"title":"融資融券彙總","fields":["代號","名稱","買進","賣出","現金償還","前日餘額","今日餘額","次一營業日限額","買進","賣出","現券償還","前日餘額","今日餘額","次一營業日限額","資券互抵","註記"],
+"data":[["2618","長榮航","500","300","0","10,000","10,200","100,000","20","50","0","1,000","1,030","100,000","0",""]]}]}`}))
+	)
+	data, err := client.NewTWMTSS(date, "ALL").Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := data["2618"]; len(data) != 1 || v.Name != "長榮航" || v.MT.Total != 200 || v.SS.Total != 30 {
+		t.Errorf("Wrong data: %+v", data)
+	}
+}
@@ -1,11 +1,8 @@
 package twse
 
 import (
-	"encoding/csv"
 	"errors"
 	"fmt"
-	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
@@ -181,6 +178,9 @@ type Lists struct {
 	FmtData         map[string]FmtListData
 	categoryRawData map[string][][]string
 	categoryNoList  map[string][]StockInfo
+	report          *Report
+	fetcher         Fetcher
+	client          *Client
 }
 
 var errorNotSupport = errors.New("Not support")
@@ -194,8 +194,15 @@ func NewLists(t time.Time) *Lists {
 		categoryNoList:  make(map[string][]StockInfo),
 	}
 }
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (l *Lists) SetFetcher(f Fetcher) *Lists {
+	l.fetcher = f
+	return l
+}
+
 func (l Lists) Url() string {
-	return fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.TWSELISTCSV, l.Date.Year(), l.Date.Month(), l.Date.Day(), "ALL"))
+	return l.client.twseURL(fmt.Sprintf(utils.TWSELISTCSV, l.Date.Year(), l.Date.Month(), l.Date.Day(), "ALL"))
 }
 
 // Get is to get TWSE csv data.
@@ -204,49 +211,28 @@ func (l *Lists) Get(category string) ([][]string, error) {
 		return nil, errorNotSupport
 	}
 
-	data, err := hCache.PostForm(fmt.Sprintf("%s%s", utils.TWSEHOST,
+	data, err := l.client.fetchTWSE(l.fetcher).PostForm(l.client.twseURL(
 		fmt.Sprintf(utils.TWSELISTCSV, l.Date.Year(), l.Date.Month(), l.Date.Day(), category)), nil)
 	if err != nil {
 		return nil, fmt.Errorf(errorNetworkFail.Error(), err)
 	}
 
-	csvArrayContent := strings.Split(string(data), "\n")
-
-	var csvReader *csv.Reader
-	switch category {
-	case "MS":
-		if len(csvArrayContent) > 6 {
-			csvReader = csv.NewReader(strings.NewReader(strings.Join(csvArrayContent[4:51], "\n")))
-		}
-	case "ALLBUT0999", "ALL":
-		if len(csvArrayContent) > 121 {
-			re := regexp.MustCompile("^=?[\"]{1}[0-9A-Z]{4,}")
-			var pickdata []string
-			for _, v := range csvArrayContent[121:] {
-				if re.MatchString(v) {
-					pickdata = append(pickdata, strings.Replace(v, "=", "", -1))
-				}
-			}
-			csvReader = csv.NewReader(strings.NewReader(strings.Join(pickdata, "\n")))
-		}
-	default:
-		if len(csvArrayContent) > 12 {
-			csvReader = csv.NewReader(strings.NewReader(strings.Join(csvArrayContent[4:len(csvArrayContent)-7], "\n")))
+	if category == "MS" {
+		t, err := l.client.parse(schema{name: "TWSE list MS", columns: twseIndexSchema}, data)
+		if err != nil {
+			return nil, err
 		}
+		return t.rows, nil
 	}
-	if csvReader != nil {
-		returnData, err := csvReader.ReadAll()
-		switch category {
-		default:
-			if err == nil {
-				l.categoryRawData[category] = returnData
-				l.formatData(category)
-			}
-		case "MS":
-		}
-		return returnData, err
+	t, err := l.client.parse(schema{name: "TWSE list " + category, columns: twseListSchema}, data)
+	if err != nil {
+		return nil, err
+	}
+	l.categoryRawData[category] = t.rows
+	if err := l.formatData(category, t.records); err != nil {
+		return nil, err
 	}
-	return nil, errorNotEnoughData
+	return t.rows, nil
 }
 
 // GetCategoryList 取得分類的股票代碼與名稱列表
@@ -262,50 +248,97 @@ func (l Lists) GetCategoryList(category string) []StockInfo {
 
 // FmtListData 格式化個股的資料資訊
 type FmtListData struct {
-	No             string
-	Name           string
-	Volume         uint64  //成交股數
-	TotalPrice     uint64  //成交金額
-	Open           float64 //開盤價
-	High           float64 //最高價
-	Low            float64 //最低價
-	Price          float64 //收盤價
-	Range          float64 //漲跌價差
-	Totalsale      uint64  //成交筆數
-	LastBuyPrice   float64 //最後揭示買價
-	LastBuyVolume  uint64  //最後揭示買量
-	LastSellPrice  float64 //最後揭示賣價
-	LastSellVolume uint64  //最後揭示賣量
-	PERatio        float64 //本益比
-	IssuedShares   uint64  //發行股數
+	No             string  `json:"no"`
+	Name           string  `json:"name"`
+	Volume         uint64  `json:"volume"`           //成交股數
+	TotalPrice     uint64  `json:"total_price"`      //成交金額
+	Open           float64 `json:"open"`             //開盤價
+	High           float64 `json:"high"`             //最高價
+	Low            float64 `json:"low"`              //最低價
+	Price          float64 `json:"price"`            //收盤價
+	Range          float64 `json:"range"`            //漲跌價差
+	Totalsale      uint64  `json:"totalsale"`        //成交筆數
+	LastBuyPrice   float64 `json:"last_buy_price"`   //最後揭示買價
+	LastBuyVolume  uint64  `json:"last_buy_volume"`  //最後揭示買量
+	LastSellPrice  float64 `json:"last_sell_price"`  //最後揭示賣價
+	LastSellVolume uint64  `json:"last_sell_volume"` //最後揭示賣量
+	PERatio        float64 `json:"pe_ratio"`         //本益比
+	IssuedShares   uint64  `json:"issued_shares"`    //發行股數
 }
 
-func (l *Lists) formatData(categoryNo string) {
-	if _, ok := l.categoryNoList[categoryNo]; !ok {
-		l.categoryNoList[categoryNo] = make([]StockInfo, len(l.categoryRawData[categoryNo]))
-	}
+// twseListSchema 上市每日收盤行情（MI_INDEX）的欄位
+var twseListSchema = []column{
+	col("No", "證券代號"),
+	col("Name", "證券名稱"),
+	col("Volume", "成交股數"),
+	col("Totalsale", "成交筆數"),
+	col("TotalPrice", "成交金額"),
+	col("Open", "開盤價"),
+	col("High", "最高價"),
+	col("Low", "最低價"),
+	col("Price", "收盤價"),
+	col("Sign", "漲跌(+/-)"),
+	col("Range", "漲跌價差"),
+	col("LastBuyPrice", "最後揭示買價"),
+	col("LastBuyVolume", "最後揭示買量"),
+	col("LastSellPrice", "最後揭示賣價"),
+	col("LastSellVolume", "最後揭示賣量"),
+	col("PERatio", "本益比"),
+}
 
-	for i, v := range l.categoryRawData[categoryNo] {
-		var data FmtListData
-		data.No = strings.Trim(v[0], " ")
-		data.Name = strings.Trim(v[1], " ")
-		data.Volume, _ = strconv.ParseUint(strings.Replace(v[2], ",", "", -1), 10, 64)
-		data.Totalsale, _ = strconv.ParseUint(strings.Replace(v[3], ",", "", -1), 10, 64)
-		data.TotalPrice, _ = strconv.ParseUint(strings.Replace(v[4], ",", "", -1), 10, 64)
-		data.Open, _ = strconv.ParseFloat(v[5], 64)
-		data.High, _ = strconv.ParseFloat(v[6], 64)
-		data.Low, _ = strconv.ParseFloat(v[7], 64)
-		data.Price, _ = strconv.ParseFloat(v[8], 64)
-		data.Range, _ = strconv.ParseFloat(fmt.Sprintf("%s%s", v[9], v[10]), 64)
-		data.LastBuyPrice, _ = strconv.ParseFloat(v[11], 64)
-		data.LastBuyVolume, _ = strconv.ParseUint(strings.Replace(v[12], ",", "", -1), 10, 64)
-		data.LastSellPrice, _ = strconv.ParseFloat(v[13], 64)
-		data.LastSellVolume, _ = strconv.ParseUint(v[14], 10, 64)
-		data.PERatio, _ = strconv.ParseFloat(v[15], 64)
+// twseIndexSchema 大盤統計資訊（MI_INDEX MS）的指數欄位
+var twseIndexSchema = []column{
+	col("Name", "指數"),
+	col("Price", "收盤指數"),
+}
 
+// formatData 解析類別的每一列，欄位解析失敗或無成交（價格為 "--"）時記錄在 Report()，
+// 該列不放入 FmtData，但仍列在類別的股票列表
+func (l *Lists) formatData(categoryNo string, records []record) error {
+	var (
+		p    = newParser("TWSE list "+categoryNo, l.client.Strict())
+		list = make([]StockInfo, 0, len(records))
+	)
+	l.report = p.report
+	for _, r := range records {
+		p.next()
+		var data FmtListData
+		data.No = r.get("No")
+		data.Name = r.get("Name")
+		data.Volume = p.uint("Volume", r.get("Volume"))
+		data.Totalsale = p.uint("Totalsale", r.get("Totalsale"))
+		data.TotalPrice = p.uint("TotalPrice", r.get("TotalPrice"))
+		data.Open = p.float("Open", r.get("Open"))
+		data.High = p.float("High", r.get("High"))
+		data.Low = p.float("Low", r.get("Low"))
+		data.Price = p.float("Price", r.get("Price"))
+		if v := p.change("Range", r.get("Range")); strings.Contains(r.get("Sign"), "-") {
+			data.Range = -v
+		} else {
+			data.Range = v
+		}
+		data.LastBuyPrice = p.optFloat("LastBuyPrice", r.get("LastBuyPrice"))
+		data.LastBuyVolume = p.optUint("LastBuyVolume", r.get("LastBuyVolume"))
+		data.LastSellPrice = p.optFloat("LastSellPrice", r.get("LastSellPrice"))
+		data.LastSellVolume = p.optUint("LastSellVolume", r.get("LastSellVolume"))
+		data.PERatio = p.optFloat("PERatio", r.get("PERatio"))
+		if err := p.err(); err != nil {
+			return err
+		}
+		list = append(list, StockInfo{No: data.No, Name: data.Name})
+		if p.bad {
+			p.skip()
+			continue
+		}
 		l.FmtData[data.No] = data
-		l.categoryNoList[categoryNo][i] = StockInfo{No: data.No, Name: data.Name}
 	}
+	l.categoryNoList[categoryNo] = list
+	return nil
+}
+
+// Report 回傳最近一次 Get 的驗證報告
+func (l Lists) Report() *Report {
+	return l.report
 }
 
 // OTCLists is to get OTC list.
@@ -314,6 +347,9 @@ type OTCLists struct {
 	FmtData         map[string]FmtListData
 	categoryRawData map[string][][]string
 	categoryNoList  map[string][]StockInfo
+	report          *Report
+	fetcher         Fetcher
+	client          *Client
 }
 
 // NewOTCLists new a Lists.
@@ -326,31 +362,29 @@ func NewOTCLists(date time.Time) *OTCLists {
 	}
 }
 
+// SetFetcher 指定擷取資料的 Fetcher
+func (o *OTCLists) SetFetcher(f Fetcher) *OTCLists {
+	o.fetcher = f
+	return o
+}
+
 // Get is to get OTC csv data.
 func (o *OTCLists) Get(category string) ([][]string, error) {
-	var (
-		csvArrayContent []string
-		csvReader       *csv.Reader
-		data            []byte
-		err             error
-		rawData         [][]string
-		url             string
-	)
-
-	url = fmt.Sprintf("%s%s", utils.OTCHOST, fmt.Sprintf(utils.OTCLISTCSV, fmt.Sprintf("%d/%02d/%02d", o.Date.Year()-1911, o.Date.Month(), o.Date.Day()), category))
+	var url = fmt.Sprintf("%s%s", o.client.OTCHost(), fmt.Sprintf(utils.OTCLISTCSV, fmt.Sprintf("%d/%02d/%02d", o.Date.Year()-1911, o.Date.Month(), o.Date.Day()), category))
 
-	if data, err = hCache.Get(url, false); err == nil {
-		csvArrayContent = strings.Split(string(data), "\n")
-		if len(csvArrayContent) > 5 {
-			csvReader = csv.NewReader(strings.NewReader(strings.Join(csvArrayContent[4:len(csvArrayContent)-1], "\n")))
-			if rawData, err = csvReader.ReadAll(); err == nil {
-				o.categoryRawData[category] = rawData
-				o.formatData(category)
-				return rawData, nil
-			}
-		}
+	data, err := o.client.fetch(o.fetcher).Get(url, false)
+	if err != nil {
+		return nil, err
+	}
+	t, err := schema{name: "OTC list " + category, columns: otcListSchema}.parse(data)
+	if err != nil {
+		return nil, err
 	}
-	return nil, err
+	o.categoryRawData[category] = t.rows
+	if err = o.formatData(category, t.records); err != nil {
+		return nil, err
+	}
+	return t.rows, nil
 }
 
 // GetCategoryList 取得分類的股票代碼與名稱列表
@@ -364,28 +398,62 @@ func (o OTCLists) GetCategoryList(category string) []StockInfo {
 	return o.categoryNoList[category]
 }
 
-func (o *OTCLists) formatData(categoryNo string) {
-	if _, ok := o.categoryNoList[categoryNo]; !ok {
-		o.categoryNoList[categoryNo] = make([]StockInfo, len(o.categoryRawData[categoryNo]))
-	}
+// otcListSchema 上櫃股票每日收盤行情（stk_wn1430）的欄位
+var otcListSchema = []column{
+	col("No", "代號"),
+	col("Name", "名稱"),
+	col("Price", "收盤"),
+	col("Range", "漲跌"),
+	col("Open", "開盤"),
+	col("High", "最高"),
+	col("Low", "最低"),
+	col("Volume", "成交股數"),
+	col("TotalPrice", "成交金額(元)", "成交金額"),
+	col("Totalsale", "成交筆數"),
+	col("LastBuyPrice", "最後買價"),
+	col("LastSellPrice", "最後賣價"),
+	col("IssuedShares", "發行股數"),
+}
 
-	for i, v := range o.categoryRawData[categoryNo] {
+// formatData 解析類別的每一列，欄位解析失敗或無成交（價格為 "--"）時記錄在 Report()，
+// 該列不放入 FmtData，但仍列在類別的股票列表
+func (o *OTCLists) formatData(categoryNo string, records []record) error {
+	var (
+		p    = newParser("OTC list "+categoryNo, o.client.Strict())
+		list = make([]StockInfo, 0, len(records))
+	)
+	o.report = p.report
+	for _, r := range records {
+		p.next()
 		var data FmtListData
-		data.No = strings.Trim(v[0], " ")
-		data.Name = strings.Trim(v[1], " ")
-		data.Volume, _ = strconv.ParseUint(strings.Replace(v[7], ",", "", -1), 10, 64)
-		data.Totalsale, _ = strconv.ParseUint(strings.Replace(v[9], ",", "", -1), 10, 64)
-		data.TotalPrice, _ = strconv.ParseUint(strings.Replace(v[8], ",", "", -1), 10, 64)
-		data.Open, _ = strconv.ParseFloat(v[4], 64)
-		data.High, _ = strconv.ParseFloat(v[5], 64)
-		data.Low, _ = strconv.ParseFloat(v[6], 64)
-		data.Price, _ = strconv.ParseFloat(v[2], 64)
-		data.Range, _ = strconv.ParseFloat(strings.Replace(v[3], " ", "", -1), 64)
-		data.LastBuyPrice, _ = strconv.ParseFloat(v[10], 64)
-		data.LastSellPrice, _ = strconv.ParseFloat(v[11], 64)
-		data.IssuedShares, _ = strconv.ParseUint(strings.Replace(v[12], ",", "", -1), 10, 64)
-
+		data.No = r.get("No")
+		data.Name = r.get("Name")
+		data.Volume = p.uint("Volume", r.get("Volume"))
+		data.Totalsale = p.uint("Totalsale", r.get("Totalsale"))
+		data.TotalPrice = p.uint("TotalPrice", r.get("TotalPrice"))
+		data.Open = p.float("Open", r.get("Open"))
+		data.High = p.float("High", r.get("High"))
+		data.Low = p.float("Low", r.get("Low"))
+		data.Price = p.float("Price", r.get("Price"))
+		data.Range = p.change("Range", strings.Replace(r.get("Range"), " ", "", -1))
+		data.LastBuyPrice = p.optFloat("LastBuyPrice", r.get("LastBuyPrice"))
+		data.LastSellPrice = p.optFloat("LastSellPrice", r.get("LastSellPrice"))
+		data.IssuedShares = p.uint("IssuedShares", r.get("IssuedShares"))
+		if err := p.err(); err != nil {
+			return err
+		}
+		list = append(list, StockInfo{No: data.No, Name: data.Name})
+		if p.bad {
+			p.skip()
+			continue
+		}
 		o.FmtData[data.No] = data
-		o.categoryNoList[categoryNo][i] = StockInfo{No: data.No, Name: data.Name}
 	}
+	o.categoryNoList[categoryNo] = list
+	return nil
+}
+
+// Report 回傳最近一次 Get 的驗證報告
+func (o OTCLists) Report() *Report {
+	return o.report
 }
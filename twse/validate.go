@@ -0,0 +1,197 @@
+package twse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNoValue 欄位沒有數值，如無成交時的 "--"
+var ErrNoValue = errors.New("no value")
+
+// errColumnCount 欄位數量不足
+var errColumnCount = errors.New("not enough columns")
+
+// errDate 日期無法解析
+var errDate = errors.New("invalid date")
+
+// ParseError 欄位解析失敗的資料集、列、欄位與原始值
+type ParseError struct {
+	Dataset string // 資料集，如 "tse 2618"、"T86"
+	Row     int    // 第幾列，由 0 開始
+	Column  string // 欄位名稱
+	Raw     string // 原始值
+	Err     error  // ErrNoValue 或 strconv 的錯誤
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: row %d column %s: %q: %s", e.Dataset, e.Row, e.Column, e.Raw, e.Err)
+}
+
+// Report 每次擷取的驗證報告
+//
+// WithStrict(true) 時遇到第一個錯誤即回傳 *ParseError；
+// 預設寬鬆模式下繼續解析，並將所有錯誤記錄在 Report。
+type Report struct {
+	Dataset string
+	Rows    int // 解析的列數
+	Skipped int // 因解析失敗而略過的列數
+	Errors  []*ParseError
+}
+
+// OK 沒有任何解析錯誤
+func (r *Report) OK() bool {
+	return r == nil || len(r.Errors) == 0
+}
+
+func (r *Report) String() string {
+	if r == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s: %d rows, %d skipped, %d errors", r.Dataset, r.Rows, r.Skipped, len(r.Errors))
+}
+
+// merge 合併其他報告的列數與錯誤
+func (r *Report) merge(other *Report) {
+	if other == nil {
+		return
+	}
+	r.Rows += other.Rows
+	r.Skipped += other.Skipped
+	r.Errors = append(r.Errors, other.Errors...)
+}
+
+// parser 逐列解析欄位，解析失敗的欄位值為 0 並記錄在 report
+type parser struct {
+	report *Report
+	strict bool
+	row    int
+	bad    bool // 目前這一列有欄位解析失敗
+}
+
+func newParser(dataset string, strict bool) *parser {
+	return &parser{report: &Report{Dataset: dataset}, strict: strict, row: -1}
+}
+
+// next 開始解析下一列
+func (p *parser) next() {
+	p.row++
+	p.bad = false
+	p.report.Rows++
+}
+
+// skip 略過目前這一列
+func (p *parser) skip() {
+	p.report.Skipped++
+}
+
+// err 寬鬆模式回傳 nil，嚴格模式回傳第一個錯誤
+func (p *parser) err() error {
+	if p.strict && len(p.report.Errors) > 0 {
+		return p.report.Errors[0]
+	}
+	return nil
+}
+
+func (p *parser) fail(column, raw string, err error) {
+	p.bad = true
+	if ne, ok := err.(*strconv.NumError); ok {
+		err = ne.Err
+	}
+	p.report.Errors = append(p.report.Errors, &ParseError{
+		Dataset: p.report.Dataset,
+		Row:     p.row,
+		Column:  column,
+		Raw:     raw,
+		Err:     err,
+	})
+}
+
+// cleanNumber 去除空白、千分位逗號與正號，無數值時回傳 false
+func cleanNumber(raw string) (string, bool) {
+	s := strings.Replace(strings.TrimSpace(raw), ",", "", -1)
+	s = strings.TrimPrefix(s, "+")
+	if s == "" || strings.Trim(s, "-") == "" {
+		return s, false
+	}
+	return s, true
+}
+
+func (p *parser) uint(column, raw string) uint64 {
+	s, ok := cleanNumber(raw)
+	if !ok {
+		p.fail(column, raw, ErrNoValue)
+		return 0
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		p.fail(column, raw, err)
+	}
+	return v
+}
+
+func (p *parser) int(column, raw string) int64 {
+	s, ok := cleanNumber(raw)
+	if !ok {
+		p.fail(column, raw, ErrNoValue)
+		return 0
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		p.fail(column, raw, err)
+	}
+	return v
+}
+
+func (p *parser) float(column, raw string) float64 {
+	s, ok := cleanNumber(raw)
+	if !ok {
+		p.fail(column, raw, ErrNoValue)
+		return 0
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		p.fail(column, raw, err)
+	}
+	return v
+}
+
+// optFloat 允許無數值（如無成交的價格）的欄位，無數值時為 0 且不記錄錯誤
+func (p *parser) optFloat(column, raw string) float64 {
+	if _, ok := cleanNumber(raw); !ok {
+		return 0
+	}
+	return p.float(column, raw)
+}
+
+// optUint 允許無數值的欄位，無數值時為 0 且不記錄錯誤
+func (p *parser) optUint(column, raw string) uint64 {
+	if _, ok := cleanNumber(raw); !ok {
+		return 0
+	}
+	return p.uint(column, raw)
+}
+
+// columns 檢查欄位數量
+func (p *parser) columns(row []string, n int) bool {
+	if len(row) < n {
+		p.fail("columns", strings.Join(row, ","), errColumnCount)
+		return false
+	}
+	return true
+}
+
+// change 漲跌價差，"X" 開頭為除權息或不比價，取其後的數值；無數值（如無成交）時為 0 且不記錄錯誤
+func (p *parser) change(column, raw string) float64 {
+	s, ok := cleanNumber(strings.TrimLeft(strings.TrimSpace(raw), "Xx"))
+	if !ok {
+		return 0
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		p.fail(column, raw, err)
+	}
+	return v
+}
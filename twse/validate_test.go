@@ -0,0 +1,130 @@
+package twse
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+const fixtureSTOCKDAYNoTrade = `"104年03月 2618 長榮航           各日成交資訊"
+"日期","成交股數","成交金額","開盤價","最高價","最低價","收盤價","漲跌價差","成交筆數",
+"104/03/02","13,384,378","305,046,992","23.00","23.05","22.50","22.90","-0.10","3,793",
+"104/03/03","0","0","--","--","--","--"," 0.00","0",
+"104/03/04","19,225,398","447,870,545","22.90","23.50","22.90","23.40","X0.00","5,148",
+"說明:"
+`
+
+func TestData_validate(t *testing.T) {
+	var (
+		date = time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		d    = NewTWSE("2618", date)
+	)
+	d.SetFetcher(memFetcher{d.URL(): fixtureSTOCKDAYNoTrade})
+
+	if _, err := d.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Len() != 2 {
+		t.Fatalf("Should skip no trade day but %v", d.RawData)
+	}
+	if ma := d.MA(2); ma[0] != (22.90+23.40)/2 {
+		t.Errorf("Should not use zero price: %v", ma)
+	}
+	report := d.Report()
+	if report.OK() || report.Rows != 3 || report.Skipped != 1 || len(report.Errors) != 4 {
+		t.Fatalf("Wrong report: %s %v", report, report.Errors)
+	}
+	if e := report.Errors[0]; e.Row != 1 || e.Column != "Open" || e.Raw != "--" || e.Err != ErrNoValue {
+		t.Errorf("Wrong error: %s", e)
+	}
+	if data := d.FormatData(); data[1].Range != 0 || data[1].Price != 23.40 {
+		t.Errorf("Should parse X0.00: %+v", data[1])
+	}
+}
+
+func TestData_validateStrict(t *testing.T) {
+	var (
+		date   = time.Date(2015, 3, 4, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		client = NewClient(WithStrict(true))
+		d      = client.NewTWSE("2618", date)
+	)
+	d.SetFetcher(memFetcher{d.URL(): fixtureSTOCKDAYNoTrade})
+
+	_, err := d.Get()
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("Should be *ParseError but %v", err)
+	}
+	if perr.Dataset != "tse 2618" || perr.Row != 1 || perr.Column != "Open" {
+		t.Errorf("Wrong error: %s", perr)
+	}
+	if d.Len() != 0 {
+		t.Errorf("Should not keep data: %v", d.RawData)
+	}
+
+	err = d.LoadRange(time.Date(2015, 3, 2, 0, 0, 0, 0, utils.TaipeiTimeZone), date)
+	if loadErr, ok := err.(*LoadError); !ok {
+		t.Errorf("Should be *LoadError but %v", err)
+	} else if _, ok := loadErr.Months[0].Err.(*ParseError); !ok {
+		t.Errorf("Should be *ParseError but %v", loadErr.Months[0].Err)
+	}
+}
+
+func TestParseWeightCSV(t *testing.T) {
+	var raw = []byte(`"106年03月市場成交資訊"
+"日期","成交股數","成交金額","成交筆數","發行量加權股價指數","漲跌點數",
+"106/03/01","4,352,913,007","99,915,928,382","981,872","9,674.78","-75.69",
+"106/03/02","4,226,390,493","97,384,946,604","916,584","9,647.6l","-27.17",
+"106/03/03","4,015,235,111","87,101,315,271","850,036","9,680.07","32.46",
+`)
+	result, report, err := parseWeightCSV(raw, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || result[0].Point != 9674.78 || result[1].Point != 9680.07 {
+		t.Errorf("Wrong result: %+v", result)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Column != "Point" || report.Errors[0].Raw != "9,647.6l" {
+		t.Errorf("Wrong report: %v", report.Errors)
+	}
+
+	if _, _, err := parseWeightCSV(raw, true); err == nil {
+		t.Error("Should be error")
+	}
+}
+
+func TestClient_GetWeight(t *testing.T) {
+	var (
+		date   = time.Date(2017, 3, 5, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		client = NewClient(WithStrict(true), WithFetcher(memFetcher{
+			fmt.Sprintf("%s/exchangeReport/FMTQIK?response=csv&date=20170305", utils.TWSEHOST): `"106年03月市場成交資訊"
+"日期","成交股數","成交金額","成交筆數","發行量加權股價指數","漲跌點數",
+"106/03/01","4,352,913,007","99,915,928,382","981,872","9,674.78","-75.69",
+`}))
+	)
+	result, report, err := client.GetWeight(date)
+	if err != nil || len(result) != 1 || !report.OK() {
+		t.Errorf("Wrong result: %v %s %v", result, report, err)
+	}
+}
+
+func ExampleReport() {
+	var (
+		p    = newParser("tse 2618", false)
+		rows = [][]string{
+			{"104/03/02", "13,384,378", "305,046,992", "23.00", "23.05", "22.50", "22.90", "-0.10", "3,793"},
+			{"104/03/03", "0", "0", "--", "--", "--", "--", " 0.00", "0"},
+		}
+	)
+	for _, v := range rows {
+		p.next()
+		parseDailyRow(p, v)
+	}
+	fmt.Println(p.report)
+	fmt.Println(p.report.Errors[0])
+	// output:
+	// tse 2618: 2 rows, 0 skipped, 4 errors
+	// tse 2618: row 1 column Open: "--": no value
+}
@@ -0,0 +1,262 @@
+package twse
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+	"github.com/pkg/errors"
+)
+
+// ValuationData 個股本益比、殖利率及股價淨值比
+type ValuationData struct {
+	Date          time.Time `json:"date"`
+	No            string    `json:"no"`
+	Name          string    `json:"name"`
+	PERatio       float64   `json:"pe_ratio"`       // 本益比，虧損或無資料時為 0
+	PBRatio       float64   `json:"pb_ratio"`       // 股價淨值比
+	DividendYield float64   `json:"dividend_yield"` // 殖利率(%)
+	DividendYear  int       `json:"dividend_year"`  // 股利年度（民國年），無資料時為 0
+}
+
+// valuationSchema 上市（BWIBBU_d）與上櫃（peratio_analysis）每日全部個股的欄位
+var valuationSchema = []column{
+	col("No", "證券代號", "股票代號", "代號"),
+	col("Name", "證券名稱", "名稱", "公司名稱"),
+	col("PERatio", "本益比"),
+	col("PBRatio", "股價淨值比"),
+	col("DividendYield", "殖利率(%)", "殖利率"),
+	optCol("DividendYear", "股利年度"),
+}
+
+// valuationHistorySchema 上市（BWIBBU）與上櫃（peratio_stk）個股月份資料的欄位
+var valuationHistorySchema = []column{
+	col("Date", "日期"),
+	col("PERatio", "本益比"),
+	col("PBRatio", "股價淨值比"),
+	col("DividendYield", "殖利率(%)", "殖利率"),
+	optCol("DividendYear", "股利年度"),
+}
+
+// parseValuation 解析一列的本益比、殖利率、股價淨值比與股利年度
+func parseValuation(p *parser, r record) ValuationData {
+	var data ValuationData
+	data.PERatio = p.optFloat("PERatio", r.get("PERatio"))
+	data.PBRatio = p.optFloat("PBRatio", r.get("PBRatio"))
+	data.DividendYield = p.optFloat("DividendYield", r.get("DividendYield"))
+	if v, ok := cleanNumber(r.get("DividendYear")); ok {
+		year, err := strconv.Atoi(v)
+		if err != nil {
+			p.fail("DividendYear", r.get("DividendYear"), err)
+		}
+		data.DividendYear = year
+	}
+	return data
+}
+
+// Valuation 上市/上櫃每日全部個股的本益比、殖利率及股價淨值比
+type Valuation struct {
+	Date     time.Time
+	FmtData  map[string]ValuationData
+	exchange string
+	report   *Report
+	fetcher  Fetcher
+	client   *Client
+}
+
+// NewValuation 建立上市個股每日本益比、殖利率及股價淨值比
+func NewValuation(date time.Time) *Valuation {
+	return &Valuation{Date: date, FmtData: make(map[string]ValuationData), exchange: "tse"}
+}
+
+// NewOTCValuation 建立上櫃個股每日本益比、殖利率及股價淨值比
+func NewOTCValuation(date time.Time) *Valuation {
+	return &Valuation{Date: date, FmtData: make(map[string]ValuationData), exchange: "otc"}
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (v *Valuation) SetFetcher(f Fetcher) *Valuation {
+	v.fetcher = f
+	return v
+}
+
+// URL 擷取網址
+func (v Valuation) URL() string {
+	switch v.exchange {
+	case "tse":
+		return v.client.twseURL(fmt.Sprintf(utils.TWSEBWIBBUD, v.Date.Year(), v.Date.Month(), v.Date.Day()))
+	case "otc":
+		return fmt.Sprintf("%s%s", v.client.OTCHost(), fmt.Sprintf(utils.OTCPERATIO,
+			fmt.Sprintf("%d/%02d/%02d", v.Date.Year()-1911, v.Date.Month(), v.Date.Day())))
+	}
+	return ""
+}
+
+// Get 擷取當日全部個股的資料，依來源順序排列，欄位解析失敗時記錄在 Report()
+func (v *Valuation) Get() ([]ValuationData, error) {
+	var s = schema{name: fmt.Sprintf("%s valuation %s", v.exchange, v.Date.Format("20060102")), columns: valuationSchema}
+	t, err := v.client.fetchTable(v.fetcher, v.exchange, v.URL(), s)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		p      = newParser(s.name, v.client.Strict())
+		result = make([]ValuationData, 0, len(t.records))
+	)
+	v.report = p.report
+	for _, r := range t.records {
+		p.next()
+		data := parseValuation(p, r)
+		data.Date = v.Date
+		data.No = r.get("No")
+		data.Name = r.get("Name")
+		if err := p.err(); err != nil {
+			return nil, err
+		}
+		v.FmtData[data.No] = data
+		result = append(result, data)
+	}
+	return result, nil
+}
+
+// Report 回傳最近一次 Get 的驗證報告
+func (v Valuation) Report() *Report {
+	return v.report
+}
+
+// ValuationHistory 個股每日本益比、殖利率及股價淨值比的歷史資料，以月份擷取
+type ValuationHistory struct {
+	No       string
+	Name     string
+	Data     []ValuationData // 依日期排序
+	exchange string
+	months   map[int64]bool
+	report   *Report
+	fetcher  Fetcher
+	client   *Client
+}
+
+// NewValuationHistory 建立上市個股本益比、殖利率及股價淨值比的歷史資料
+func NewValuationHistory(no string) *ValuationHistory {
+	return &ValuationHistory{No: no, exchange: "tse", months: make(map[int64]bool)}
+}
+
+// NewOTCValuationHistory 建立上櫃個股本益比、殖利率及股價淨值比的歷史資料
+func NewOTCValuationHistory(no string) *ValuationHistory {
+	return &ValuationHistory{No: no, exchange: "otc", months: make(map[int64]bool)}
+}
+
+// SetFetcher 指定擷取資料的 Fetcher
+func (h *ValuationHistory) SetFetcher(f Fetcher) *ValuationHistory {
+	h.fetcher = f
+	return h
+}
+
+// URL 擷取 month 所在月份的網址
+func (h ValuationHistory) URL(month time.Time) string {
+	switch h.exchange {
+	case "tse":
+		return h.client.twseURL(fmt.Sprintf(utils.TWSEBWIBBU, month.Year(), month.Month(), 1, h.No))
+	case "otc":
+		return fmt.Sprintf("%s%s", h.client.OTCHost(), fmt.Sprintf(utils.OTCPERASTK,
+			fmt.Sprintf("%d/%02d", month.Year()-1911, month.Month()), h.No))
+	}
+	return ""
+}
+
+// GetMonth 擷取 month 所在月份的資料並合併至 Data，欄位解析失敗時記錄在 Report()
+func (h *ValuationHistory) GetMonth(month time.Time) ([]ValuationData, error) {
+	var s = schema{name: fmt.Sprintf("%s valuation %s %s", h.exchange, h.No, month.Format("200601")), columns: valuationHistorySchema}
+	t, err := h.client.fetchTable(h.fetcher, h.exchange, h.URL(month), s)
+	if err != nil {
+		return nil, err
+	}
+	if fields := strings.Fields(t.title); h.Name == "" && len(fields) > 2 && fields[1] == h.No {
+		h.Name = fields[2]
+	}
+
+	var (
+		p      = newParser(s.name, h.client.Strict())
+		result = make([]ValuationData, 0, len(t.records))
+	)
+	h.report = p.report
+	for _, r := range t.records {
+		p.next()
+		data := parseValuation(p, r)
+		if data.Date = utils.ParseDate(r.get("Date")); data.Date.IsZero() {
+			p.fail("Date", r.get("Date"), ErrNoValue)
+		}
+		data.No = h.No
+		data.Name = h.Name
+		if err := p.err(); err != nil {
+			return nil, err
+		}
+		if !data.Date.IsZero() {
+			result = append(result, data)
+		}
+	}
+	h.merge(month, result)
+	return result, nil
+}
+
+// merge 以月份取代 Data 中的資料並依日期排序，只有已結束的月份記錄為已擷取
+func (h *ValuationHistory) merge(month time.Time, data []ValuationData) {
+	var list = make([]ValuationData, 0, len(h.Data)+len(data))
+	for _, v := range h.Data {
+		if v.Date.Year() != month.Year() || v.Date.Month() != month.Month() {
+			list = append(list, v)
+		}
+	}
+	list = append(list, data...)
+	sort.Slice(list, func(i, j int) bool { return list[i].Date.Before(list[j].Date) })
+	h.Data = list
+	month = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+	if dateKey(time.Now().In(utils.TaipeiTimeZone)) > dateKey(month.AddDate(0, 1, -1)) {
+		h.months[month.Unix()] = true
+	}
+}
+
+// LoadRange 擷取 from 到 to 之間每個月份的資料，已擷取且已結束的月份不會重複擷取，
+// 沒有資料的月份（如上市前）略過
+func (h *ValuationHistory) LoadRange(from, to time.Time) error {
+	var report = &Report{Dataset: fmt.Sprintf("%s valuation %s", h.exchange, h.No)}
+	for month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, utils.TaipeiTimeZone); !month.After(to); month = month.AddDate(0, 1, 0) {
+		if h.months[month.Unix()] {
+			continue
+		}
+		if _, err := h.GetMonth(month); errors.Cause(err) == errorNotEnoughData {
+			continue
+		} else if err != nil {
+			return err
+		}
+		report.merge(h.report)
+	}
+	h.report = report
+	return nil
+}
+
+// Since 回傳 date 之後（含）的資料
+func (h ValuationHistory) Since(date time.Time) []ValuationData {
+	var i = sort.Search(len(h.Data), func(i int) bool { return !h.Data[i].Date.Before(date) })
+	return h.Data[i:]
+}
+
+// PERatioMedian 計算 date 之後（含）本益比的中位數，不含虧損或無資料（本益比為 0）的日期
+func (h ValuationHistory) PERatioMedian(date time.Time) float64 {
+	var list []float64
+	for _, v := range h.Since(date) {
+		if v.PERatio > 0 {
+			list = append(list, v.PERatio)
+		}
+	}
+	return utils.MedianFloat64(list)
+}
+
+// Report 回傳最近一次 GetMonth 或 LoadRange 的驗證報告
+func (h ValuationHistory) Report() *Report {
+	return h.report
+}
@@ -0,0 +1,111 @@
+package twse
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DoubleChuang/gogrs/utils"
+)
+
+func TestValuation_Get(t *testing.T) {
+	var (
+		date   = time.Date(2017, 3, 1, 0, 0, 0, 0, utils.TaipeiTimeZone)
+		client = NewClient(WithFetcher(memFetcher{
+			fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.TWSEBWIBBUD, 2017, 3, 1)): `"106年03月01日 個股日本益比、殖利率及股價淨值比"
+"證券代號","證券名稱","殖利率(%)","股利年度","本益比","股價淨值比","財報年/季",
+"1101","台泥","2.77",105,"24.57","1.04","105/3",
+"2618","長榮航","0.00",105,"-","0.89","105/3",
+"說明:"
+`,
+			fmt.Sprintf("%s%s", utils.OTCHOST, fmt.Sprintf(utils.OTCPERATIO, "106/03/01")): `"個股本益比、殖利率及股價淨值比"
+"資料日期:106/03/01"
+"股票代號","名稱","本益比","每股股利","股利年度","殖利率(%)","股價淨值比"
+"8446","華研","15.93","5.00","105","3.35","4.12"
+"共1筆"
+`}))
+	)
+	data, err := client.NewValuation(date).Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 || data[0].PERatio != 24.57 || data[0].DividendYield != 2.77 || data[0].DividendYear != 105 {
+		t.Fatalf("Wrong data: %+v", data)
+	}
+	if v := data[1]; v.Name != "長榮航" || v.PERatio != 0 || v.PBRatio != 0.89 || !v.Date.Equal(date) {
+		t.Errorf("Wrong data: %+v", v)
+	}
+
+	otc := client.NewOTCValuation(date)
+	if _, err := otc.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if v := otc.FmtData["8446"]; v.PERatio != 15.93 || v.PBRatio != 4.12 || v.DividendYield != 3.35 {
+		t.Errorf("Wrong data: %+v", v)
+	}
+}
+
+func TestValuationHistory_LoadRange(t *testing.T) {
+	var (
+		url = func(year, month int) string {
+			return fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.TWSEBWIBBU, year, month, 1, "2618"))
+		}
+		client = NewClient(WithFetcher(memFetcher{
+			url(2017, 2): `"106年02月 2618 長榮航 個股日本益比、殖利率及股價淨值比(以個股月查詢)"
+"日期","殖利率(%)","股利年度","本益比","股價淨值比","財報年/季",
+"106年02月02日","0.00",105,"18.00","0.85","105/3",
+"106年02月03日","0.00",105,"20.00","0.87","105/3",
+"說明:"
+`,
+			url(2017, 3): `"106年03月 2618 長榮航 個股日本益比、殖利率及股價淨值比(以個股月查詢)"
+"日期","殖利率(%)","股利年度","本益比","股價淨值比","財報年/季",
+"106年03月01日","0.00",105,"-","0.89","105/3",
+"106年03月02日","0.00",105,"22.00","0.90","105/3",
+"說明:"
+`,
+			url(2017, 1): `"很抱歉，沒有符合條件的資料!"`,
+		}))
+		h = client.NewValuationHistory("2618")
+	)
+	if err := h.LoadRange(time.Date(2017, 1, 1, 0, 0, 0, 0, utils.TaipeiTimeZone),
+		time.Date(2017, 3, 31, 0, 0, 0, 0, utils.TaipeiTimeZone)); err != nil {
+		t.Fatal(err)
+	}
+	if h.Name != "長榮航" || len(h.Data) != 4 || h.Data[0].PERatio != 18 || h.Data[3].Date.Day() != 2 {
+		t.Fatalf("Wrong data: %s %+v", h.Name, h.Data)
+	}
+	if r := h.Report(); r.Rows != 4 || !r.OK() {
+		t.Errorf("Wrong report: %s", r)
+	}
+	if v := h.PERatioMedian(time.Date(2017, 2, 3, 0, 0, 0, 0, utils.TaipeiTimeZone)); v != 21 {
+		t.Errorf("Should be 21 but %v", v)
+	}
+}
+
+func TestValuationHistory_currentMonth(t *testing.T) {
+	var (
+		now  = time.Now().In(utils.TaipeiTimeZone)
+		last = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, utils.TaipeiTimeZone).AddDate(0, -1, 0)
+		url  = func(month time.Time) string {
+			return fmt.Sprintf("%s%s", utils.TWSEHOST, fmt.Sprintf(utils.TWSEBWIBBU, month.Year(), month.Month(), 1, "2618"))
+		}
+		fixture = func(month time.Time) string {
+			return fmt.Sprintf(`"%d年%02d月 2618 長榮航 個股日本益比、殖利率及股價淨值比(以個股月查詢)"
+"日期","殖利率(%%)","股利年度","本益比","股價淨值比","財報年/季",
+"%d年%02d月01日","0.00",105,"18.00","0.85","105/3",
+"說明:"
+`, month.Year()-1911, month.Month(), month.Year()-1911, month.Month())
+		}
+		f = &countFetcher{memFetcher: memFetcher{url(last): fixture(last), url(now): fixture(now)}}
+		h = NewClient(WithFetcher(f)).NewValuationHistory("2618")
+	)
+	for i := 1; i <= 2; i++ {
+		if err := h.LoadRange(last, now); err != nil {
+			t.Fatal(err)
+		}
+		// 已結束的月份只擷取一次，本月每次重新擷取
+		if f.count != i+1 || len(h.Data) != 2 {
+			t.Errorf("Should fetch %d but %d, data %+v", i+1, f.count, h.Data)
+		}
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
-	"strconv"
 	"strings"
 	"time"
 
@@ -13,60 +12,92 @@ import (
 
 // Weight is TWSE Weight
 func Weight(date time.Time) []*WeightData {
-	if csvData, err := getWeight(date); err == nil {
+	return DefaultClient().Weight(date)
+}
+
+// WeightFrom 透過指定的 Fetcher 擷取 Weight
+func WeightFrom(f Fetcher, date time.Time) []*WeightData {
+	if csvData, err := getWeight(f, utils.TWSEHOST, date); err == nil {
 		return solveWeightCSV(csvData)
 	}
 	return nil
 }
 
-func getWeight(date time.Time) ([]byte, error) {
-	return hCache.PostForm(fmt.Sprintf("%s%s",
-		utils.TWSEHOST, fmt.Sprintf("/exchangeReport/FMTQIK?response=csv&date=%d%02d%02d", date.Year(), date.Month(), date.Day())), nil)
+func getWeight(f Fetcher, host string, date time.Time) ([]byte, error) {
+	return f.PostForm(fmt.Sprintf("%s%s",
+		host, fmt.Sprintf("/exchangeReport/FMTQIK?response=csv&date=%d%02d%02d", date.Year(), date.Month(), date.Day())), nil)
 }
 
 // WeightData struct
 type WeightData struct {
-	Date       time.Time
-	Volume     uint64  // 成交股數
-	TotalPrice uint64  //成交金額
-	Totalsale  uint64  //成交筆數
-	Point      float64 //收盤價
-	Range      float64 //漲跌價差
+	Date       time.Time `json:"date"`
+	Volume     uint64    `json:"volume"`      // 成交股數
+	TotalPrice uint64    `json:"total_price"` //成交金額
+	Totalsale  uint64    `json:"totalsale"`   //成交筆數
+	Point      float64   `json:"point"`       //收盤價
+	Range      float64   `json:"range"`       //漲跌價差
 }
 
 func solveWeightCSV(raw []byte) []*WeightData {
-	splitData := strings.Split(string(raw), "\n")
+	result, _, _ := parseWeightCSV(raw, false)
+	return result
+}
+
+// parseWeightCSV 依日期順序解析大盤成交資訊，寬鬆模式略過解析失敗的列，嚴格模式回傳第一個 *ParseError
+func parseWeightCSV(raw []byte, strict bool) ([]*WeightData, *Report, error) {
+	var (
+		splitData = strings.Split(string(raw), "\n")
+		p         = newParser("FMTQIK", strict)
+		result    []*WeightData
+		dates     = make(map[time.Time]bool)
+	)
+	if len(splitData) < 2 {
+		return nil, p.report, nil
+	}
 	csvReader := csv.NewReader(strings.NewReader(strings.Join(splitData[2:], "\n")))
-	dataMapping := make(map[time.Time][]string)
+	csvReader.FieldsPerRecord = -1
 
 	for {
 		row, err := csvReader.Read()
-		switch err {
-		case nil:
-			if len(row) >= 5 {
-				date := utils.ParseDate(row[0])
-				if date.IsZero() == false {
-					if _, dup := dataMapping[date]; dup {
-						goto Final
-					}
-					dataMapping[date] = row
-				}
+		if err == io.EOF {
+			break
+		}
+		if err != nil || len(row) == 0 {
+			continue
+		}
+		date := utils.ParseDate(row[0])
+		if date.IsZero() {
+			continue
+		}
+		if dates[date] {
+			break
+		}
+		dates[date] = true
+
+		p.next()
+		if !p.columns(row, 6) {
+			if err := p.err(); err != nil {
+				return nil, p.report, err
 			}
-		case io.EOF:
-			goto Final
+			p.skip()
+			continue
 		}
+		data := &WeightData{
+			Date:       date,
+			Volume:     p.uint("Volume", row[1]),
+			TotalPrice: p.uint("TotalPrice", row[2]),
+			Totalsale:  p.uint("Totalsale", row[3]),
+			Point:      p.float("Point", row[4]),
+			Range:      p.float("Range", row[5]),
+		}
+		if err := p.err(); err != nil {
+			return nil, p.report, err
+		}
+		if p.bad {
+			p.skip()
+			continue
+		}
+		result = append(result, data)
 	}
-Final:
-	result := make([]*WeightData, len(dataMapping))
-	num := 0
-	for k, v := range dataMapping {
-		result[num] = &WeightData{Date: k}
-		result[num].Volume, _ = strconv.ParseUint(strings.Replace(v[1], ",", "", -1), 10, 64)
-		result[num].TotalPrice, _ = strconv.ParseUint(strings.Replace(v[2], ",", "", -1), 10, 64)
-		result[num].Totalsale, _ = strconv.ParseUint(strings.Replace(v[3], ",", "", -1), 10, 64)
-		result[num].Point, _ = strconv.ParseFloat(strings.Replace(v[4], ",", "", -1), 64)
-		result[num].Range, _ = strconv.ParseFloat(v[5], 64)
-		num++
-	}
-	return result
+	return result, p.report, nil
 }
@@ -41,7 +41,7 @@ func ExampleWeight() {
 }
 
 func Benchmark_solveWeightCSV(b *testing.B) {
-	csvData, _ := getWeight(time.Date(2017, 2, 5, 0, 0, 0, 0, utils.TaipeiTimeZone))
+	csvData, _ := getWeight(DefaultClient().Fetcher(), utils.TWSEHOST, time.Date(2017, 2, 5, 0, 0, 0, 0, utils.TaipeiTimeZone))
 	for i := 0; i <= b.N; i++ {
 		solveWeightCSV(csvData)
 	}
@@ -14,6 +14,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	iconv "github.com/djimenez/iconv-go"
@@ -28,17 +29,56 @@ const TWSEDURTION = 5
 //與OTC訪問需間隔的時間差
 const OTCDURTION = 0
 
+//與公開資訊觀測站訪問需間隔的時間差，查詢過於頻繁會被暫時封鎖
+const MOPSDURTION = 3
+
+//與集保結算所開放資料訪問需間隔的時間差
+const TDCCDURTION = 1
+
 //上一次造訪TWSE時間
 var visitTwseTime time.Time = time.Now()
 
 //上一次造訪OTC時間
 var visitOtcTime time.Time = time.Now()
 
+//上一次造訪公開資訊觀測站時間
+var visitMopsTime time.Time
+
+//上一次造訪集保結算所開放資料時間
+var visitTdccTime time.Time
+
+//保護 visitTwseTime、visitOtcTime、visitMopsTime、visitTdccTime，並讓同時的造訪依序等待
+var visitTimeMu sync.Mutex
+
 // HTTPCache net/http 快取功能
 type HTTPCache struct {
 	Dir            string
 	fullpath       string
 	iconvConverter func([]byte) []byte
+	client         *http.Client
+	rateLimits     map[string]*RateLimit
+}
+
+// RateLimit 控制造訪同一主機的最小間隔
+type RateLimit struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     time.Time
+}
+
+// NewRateLimit 建立一個間隔為 interval 的 RateLimit
+func NewRateLimit(interval time.Duration) *RateLimit {
+	return &RateLimit{interval: interval}
+}
+
+// Wait 等待至距離上一次造訪超過設定的間隔
+func (r *RateLimit) Wait() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if wait := r.interval - time.Now().Sub(r.last); wait > 0 {
+		time.Sleep(wait)
+	}
+	r.last = time.Now()
 }
 
 func Dbgln(args ...interface{}) {
@@ -70,6 +110,42 @@ func NewHTTPCache(dir string, fromEncoding string) *HTTPCache {
 		iconvConverter: renderIconvConverter(fromEncoding)}
 }
 
+// SetHTTPClient 指定連線使用的 http.Client，nil 為預設的 HTTPClient
+func (hc *HTTPCache) SetHTTPClient(client *http.Client) *HTTPCache {
+	hc.client = client
+	return hc
+}
+
+// SetRateLimit 指定造訪 host 開頭網址的最小間隔
+//
+// 未指定任何 host 時沿用 TWSEDURTION、OTCDURTION、MOPSDURTION、TDCCDURTION 的全域間隔。
+func (hc *HTTPCache) SetRateLimit(host string, interval time.Duration) *HTTPCache {
+	return hc.SetRateLimiter(host, NewRateLimit(interval))
+}
+
+// SetRateLimiter 指定造訪 host 開頭網址使用的 RateLimit，可與其他 HTTPCache 共用
+func (hc *HTTPCache) SetRateLimiter(host string, r *RateLimit) *HTTPCache {
+	if hc.rateLimits == nil {
+		hc.rateLimits = make(map[string]*RateLimit)
+	}
+	hc.rateLimits[host] = r
+	return hc
+}
+
+// waitVisit 依網址等待造訪間隔
+func (hc HTTPCache) waitVisit(url string) {
+	if hc.rateLimits == nil {
+		checkAndSyncVisitTime(whereUrl(url))
+		return
+	}
+	for host, r := range hc.rateLimits {
+		if strings.HasPrefix(url, host) {
+			r.Wait()
+			return
+		}
+	}
+}
+
 // makeCacheDir 建立快取資料夾
 func makeCacheDir(dir string) string {
 	var fullpath = filepath.Join(dir, TempFolderName)
@@ -87,6 +163,8 @@ func makeCacheDir(dir string) string {
 //次存取的時間
 
 func checkAndSyncVisitTime(urlWeb string) {
+	visitTimeMu.Lock()
+	defer visitTimeMu.Unlock()
 	rand.Seed(time.Now().UnixNano())
 	ms := rand.Intn(1000)
 	switch urlWeb {
@@ -104,6 +182,16 @@ func checkAndSyncVisitTime(urlWeb string) {
 			time.Sleep(TWSEDURTION*time.Second - t)
 		}
 		visitOtcTime = time.Now()
+	case "mops":
+		if t := time.Now().Sub(visitMopsTime); t < MOPSDURTION*time.Second {
+			time.Sleep(MOPSDURTION*time.Second - t)
+		}
+		visitMopsTime = time.Now()
+	case "tdcc":
+		if t := time.Now().Sub(visitTdccTime); t < TDCCDURTION*time.Second {
+			time.Sleep(TDCCDURTION*time.Second - t)
+		}
+		visitTdccTime = time.Now()
 	default:
 	}
 }
@@ -112,6 +200,10 @@ func whereUrl(url string) string {
 		return "twse"
 	} else if strings.Contains(url, OTCHOST) {
 		return "otc"
+	} else if strings.Contains(url, MOPSHOST) {
+		return "mops"
+	} else if strings.Contains(url, TDCCHOST) {
+		return "tdcc"
 	} else {
 		return "Unknown"
 	}
@@ -129,7 +221,7 @@ func (hc HTTPCache) Get(url string, rand bool) ([]byte, error) {
 
 	fmt.Printf("file:%s%s/%s\n", GetOSRamdiskPath(""), TempFolderName, filehash)
 	if content, err = hc.readFile(filehash); err != nil {
-		checkAndSyncVisitTime(whereUrl(url))
+		hc.waitVisit(url)
 		return hc.saveFile(url, filehash, rand, nil)
 	} else {
 		csvArrayContent := strings.Split(string(content), "\n")
@@ -160,13 +252,18 @@ func (hc HTTPCache) PostForm(url string, data url.Values) ([]byte, error) {
 
 	//Dbg("file:%s%s/%s\nurl:%s\n", GetOSRamdiskPath(""), TempFolderName, filehash, url)
 	if content, err = hc.readFile(filehash); err != nil {
-		checkAndSyncVisitTime(whereUrl(url))
+		hc.waitVisit(url)
 
 		return hc.saveFile(url, filehash, false, data)
 	}
 	return content, nil
 }
 
+// Remove 移除 url 對應的快取檔案
+func (hc HTTPCache) Remove(url string) error {
+	return os.Remove(filepath.Join(hc.fullpath, fmt.Sprintf("%x", md5.Sum([]byte(url)))))
+}
+
 // FlushAll 清除快取
 func (hc *HTTPCache) FlushAll() {
 	os.RemoveAll(hc.fullpath)
@@ -226,7 +323,11 @@ func (hc HTTPCache) saveFile(url, filehash string, rand bool, data url.Values) (
 	}
 
 	req.Header.Set("Connection", "close")
-	if resp, err = HTTPClient.Do(req); err != nil {
+	client := hc.client
+	if client == nil {
+		client = HTTPClient
+	}
+	if resp, err = client.Do(req); err != nil {
 		return out, err
 	}
 	defer resp.Body.Close()
@@ -9,6 +9,7 @@ import (
 	"io"
 	"math"
 	"regexp"
+	"sort"
 	"strconv"
 	"time"
 )
@@ -24,6 +25,7 @@ const (
 	TWSEURL     string = "http://mis.twse.com.tw"
 	TWSEHOST    string = "http://www.twse.com.tw"
 	OTCHOST     string = "http://www.tpex.org.tw"
+	MOPSHOST    string = "https://mops.twse.com.tw"
 	HOME        string = "/stock/index.jsp"
 	OTCCSV      string = "/ch/stock/aftertrading/daily_trading_info/st43_download.php?d=%d/%02d&stkno=%s&r=%%d"           // year, mon, stock, rand
 	OTCLISTCSV  string = "/web/stock/aftertrading/otc_quotes_no1430/stk_wn1430_download.php?l=zh-tw&d=%s&se=%s&s=0,asc,0" // date, cate
@@ -31,11 +33,33 @@ const (
 	TWSELISTCSV string = "/exchangeReport/MI_INDEX?response=csv&date=%d%02d%02d&type=%s" // year, mon, day, type
 	TWSEREAL    string = "/stock/api/getStockInfo.jsp?ex_ch=%s_%s.tw&json=1&delay=0&_=%d"
 	QFIISTOP20  string = "/fund/MI_QFIIS_sort_20?response=csv&date=%d%02d%02d"   // yyyymmdd
+	QFIIS       string = "/fund/MI_QFIIS?response=csv&date=%d%02d%02d&selectType=%s" // yyyymmdd, type
 	BFI82U      string = "/fund/BFI82U?response=csv&dayDate=%d%02d%02d&type=day" // yyyymmdd
 	T86         string = "/fund/T86?response=csv&date=%d%02d%02d&selectType=ALL"
 	TWTXXU      string = "/fund/%s?response=csv&date=%d%02d%02d"
 	TWMTSS      string = "/exchangeReport/MI_MARGN?response=csv&date=%d%02d%02d&selectType=%s"
+	TWT93U      string = "/exchangeReport/TWT93U?response=csv&date=%d%02d%02d"
+	TWTB4U      string = "/exchangeReport/TWTB4U?response=csv&date=%d%02d%02d&selectType=All"
+	TWT49U      string = "/exchangeReport/TWT49U?response=csv&strDate=%d%02d%02d&endDate=%d%02d%02d"                // begin, end yyyymmdd
+	OTCEXRIGHT  string = "/web/stock/exright/dailyquo/exDailyQ_result.php?l=zh-tw&o=csv&d=%d/%02d/%02d&ed=%d/%02d/%02d" // begin, end yyy/mm/dd
+	TWSEODDCSV  string = "/exchangeReport/TWT53U?response=csv&date=%d%02d%02d&selectType=%s"                            // 盤後零股 year, mon, day, type
+	TWSEINTODD  string = "/exchangeReport/TWTC7U?response=csv&date=%d%02d%02d&selectType=%s"                            // 盤中零股 year, mon, day, type
+	OTCODDCSV   string = "/web/stock/aftertrading/odd_stock/odd_download.php?l=zh-tw&d=%s&se=%s"                        // 盤後零股 date, cate
+	OTCINTODD   string = "/web/stock/aftertrading/intraday_odd/intraday_odd_download.php?l=zh-tw&d=%s&se=%s"            // 盤中零股 date, cate
+	TWSEODDREAL string = "/stock/api/getOddInfo.jsp?ex_ch=%s_%s.tw&json=1&delay=0&_=%d"
+	TWSEBWIBBUD string = "/exchangeReport/BWIBBU_d?response=csv&date=%d%02d%02d&selectType=ALL"        // yyyymmdd
+	TWSEBWIBBU  string = "/exchangeReport/BWIBBU?response=csv&date=%d%02d%02d&stockNo=%s"              // yyyymmdd, stock
+	OTCPERATIO  string = "/web/stock/aftertrading/peratio_analysis/pera_download.php?l=zh-tw&d=%s"     // yyy/mm/dd
+	OTCPERASTK  string = "/web/stock/aftertrading/peratio_stk/pera_download.php?l=zh-tw&d=%s&stkno=%s" // yyy/mm, stock
+	MOPSREVENUE string = "/nas/t21/%s/t21sc03_%d_%d.csv"                                               // market, yyy, m
+	MOPSINCOME  string = "/mops/web/ajax_t163sb04"
+	MOPSBALANCE string = "/mops/web/ajax_t163sb05"
+	OTCDAYTRADE string = "/web/stock/trading/intraday_trading/intraday_trading_list_download.php?l=zh-tw&d=%s"       // yyy/mm/dd
+	OTCINSTI    string = "/web/stock/3insti/daily_trade/3itrade_hedge_result.php?l=zh-tw&o=csv&se=EW&t=D&d=%s"       // yyy/mm/dd
+	OTCMTSS     string = "/web/stock/margin_trading/margin_balance/margin_bal_result.php?l=zh-tw&o=csv&d=%s&s=0,asc" // yyy/mm/dd
 	S3CSV       string = "https://s3-ap-northeast-1.amazonaws.com/toomore/gogrs/list.csv"
+	TDCCHOST    string = "https://opendata.tdcc.com.tw"
+	TDCCCSV     string = TDCCHOST + "/getOD.ashx?id=1-5" // 集保戶股權分散表，最近一週
 )
 
 
@@ -67,9 +91,9 @@ func RandInt() int {
 //	return r.Int63n(100000)
 //}
 
-var dateReg = regexp.MustCompile(`([\d]{2,})/([\d]{1,2})/([\d]{1,2})`)
+var dateReg = regexp.MustCompile(`([\d]{2,})[/年]([\d]{1,2})[/月]([\d]{1,2})`)
 
-// ParseDate is to parse "104/01/13" format.
+// ParseDate is to parse "104/01/13" or "104年01月13日" format.
 func ParseDate(strDate string) time.Time {
 	p := dateReg.FindStringSubmatch(strDate)
 	if len(p) == 0 {
@@ -274,3 +298,17 @@ func DeltaInt64(data []int64) []int64 {
 	}
 	return result
 }
+
+// MedianFloat64 計算中位數，不修改 data
+func MedianFloat64(data []float64) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	var sorted = make([]float64, len(data))
+	copy(sorted, data)
+	sort.Float64s(sorted)
+	if n := len(sorted); n%2 == 0 {
+		return (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	return sorted[len(sorted)/2]
+}
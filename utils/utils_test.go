@@ -108,6 +108,10 @@ func TestParseDate(t *testing.T) {
 	if ParseDate(sample3) != time.Date(2015, 5, 1, 0, 0, 0, 0, TaipeiTimeZone) {
 		t.Error("Should be 2015/5/1")
 	}
+	var sample5 = "104年07月29日"
+	if ParseDate(sample5) != time.Date(2015, 7, 29, 0, 0, 0, 0, TaipeiTimeZone) {
+		t.Error("Should be 2015/7/29")
+	}
 
 	var sample4 = " "
 	if ParseDate(sample4).IsZero() == false {
@@ -344,3 +348,16 @@ func ExampleParseDate() {
 	// 2015-09-29 00:00:00 +0800 Asia/Taipei
 	// 2015-09-02 00:00:00 +0800 Asia/Taipei
 }
+
+func TestMedianFloat64(t *testing.T) {
+	var sample = []float64{3, 1, 2}
+	if MedianFloat64(sample) != 2 || sample[0] != 3 {
+		t.Error("Should be 2")
+	}
+	if MedianFloat64([]float64{4, 1, 3, 2}) != 2.5 {
+		t.Error("Should be 2.5")
+	}
+	if MedianFloat64(nil) != 0 {
+		t.Error("Should be 0")
+	}
+}